@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/auth"
+	"github.com/alanpramil7/gplay/internal/yt/services"
+	"github.com/spf13/cobra"
+)
+
+var loginFlow string
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Log in to your YouTube account",
+	Long: `Log in to your YouTube account with OAuth so gplay can read your private
+playlists and liked videos and add tracks to your playlists.
+
+Logging in needs an OAuth client from the Google Cloud console, with the
+YouTube Data API enabled. Its type depends on the login flow:
+
+  device    (default) "TVs and Limited Input devices"; you enter a code
+            on any device, so it works over SSH
+  loopback  "Desktop app"; a browser on this machine redirects back to
+            gplay
+
+  gplay config set oauth.client_id <id>
+  gplay config set oauth.client_secret <secret>
+  gplay auth login
+
+Set oauth.flow, or pass --flow, to use loopback.
+
+While logged in, Data API calls are made as you instead of with api_key.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to YouTube",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		authenticator, err := newAuthenticator()
+		if err != nil {
+			return err
+		}
+
+		flow := cfg.OAuth.Flow
+		if cmd.Flags().Changed("flow") {
+			flow = loginFlow
+		}
+		if err := authenticator.Login(cmd.Context(), flow, os.Stderr); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Logged in")
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out, revoke the stored token and clear the response cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		authenticator, err := newAuthenticator()
+		if err != nil {
+			return err
+		}
+		if err := authenticator.Logout(cmd.Context()); err != nil {
+			return err
+		}
+		// The cache holds responses only the account could see
+		if cache, err := newCache(); err == nil {
+			if err := cache.Clear(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+		fmt.Fprintln(os.Stderr, "Logged out")
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which account is logged in",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		authenticator, err := newAuthenticator()
+		if err != nil {
+			return err
+		}
+		token, err := authenticator.Token()
+		if errors.Is(err, auth.ErrNotLoggedIn) {
+			fmt.Println("Not logged in")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		library, err := libraryService()
+		if err != nil {
+			return err
+		}
+		channel, err := library.MyChannel(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Logged in as %s (%s)\n", channel.Title, channel.ID)
+		fmt.Printf("Token: %s\n", authenticator.Store().Path())
+		if !token.Expiry.IsZero() {
+			fmt.Printf("Access token expires: %s\n", token.Expiry.Local().Format(time.RFC1123))
+		}
+		return nil
+	},
+}
+
+// libraryService returns the logged in user's library
+func libraryService() (services.LibraryService, error) {
+	backend, err := newBackend(0)
+	if err != nil {
+		return nil, err
+	}
+	if backend.Library == nil {
+		return nil, services.ErrNoLibrary
+	}
+	return backend.Library, nil
+}
+
+func init() {
+	authLoginCmd.Flags().StringVar(&loginFlow, "flow", auth.FlowDevice, "Login flow ("+strings.Join(auth.Flows, ", ")+")")
+
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authStatusCmd)
+	rootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/alanpramil7/gplay/internal/output"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/spf13/cobra"
+)
+
+var cacheColumns = []output.Column[yt.CacheStats]{
+	{Header: "CALL", Value: func(s yt.CacheStats) string { return s.Call }},
+	{Header: "ENTRIES", Value: func(s yt.CacheStats) string { return strconv.Itoa(s.Entries) }},
+	{Header: "EXPIRED", Value: func(s yt.CacheStats) string { return strconv.Itoa(s.Expired) }},
+	{Header: "BYTES", Value: func(s yt.CacheStats) string { return strconv.FormatInt(s.Bytes, 10) }},
+}
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the YouTube API response cache",
+	Long: `Manage the on-disk cache of YouTube Data API responses.
+
+Searches are cached for 15 minutes, playlist pages for 6 hours and video
+details for 7 days. Expired entries are revalidated with their ETag, which
+avoids downloading unchanged results again. Change the TTLs with
+'gplay config set cache.search_ttl 30m', bypass the cache for one command
+with --no-cache or force fresh results with --refresh.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached response",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		if err := cache.Clear(); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Cache cleared")
+		return nil
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cached responses per API call",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := outputOptions(cmd)
+		if err != nil {
+			return err
+		}
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		stats, err := cache.Stats()
+		if err != nil {
+			return err
+		}
+		if err := output.Write(os.Stdout, opts, stats, cacheColumns, cacheColumns); err != nil {
+			return fmt.Errorf("failed to write cache stats: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
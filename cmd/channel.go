@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alanpramil7/gplay/internal/output"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/spf13/cobra"
+)
+
+const defaultChannelLimit = 50
+
+var (
+	channelPlaylists bool
+	channelSearch    string
+	channelLimit     int
+)
+
+// channelCmd represents the channel command
+var channelCmd = &cobra.Command{
+	Use:   "channel <id|@handle|url>",
+	Short: "List a channel's uploads, playlists or search within it",
+	Long: `List the newest uploads of a YouTube channel, its public playlists, or
+the results of a search limited to the channel.
+
+Examples:
+  gplay channel @LofiGirl
+  gplay channel UCSJ4gkVC6NrvII8umztf0Ow --limit 10
+  gplay channel https://www.youtube.com/@LofiGirl --playlists
+  gplay channel @LofiGirl --search "study" --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChannel,
+}
+
+// runChannel executes the channel command
+func runChannel(cmd *cobra.Command, args []string) error {
+	opts, err := outputOptions(cmd)
+	if err != nil {
+		return err
+	}
+	list, err := parseListOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	backend, err := newBackend(cfg.Search.MaxResults)
+	if err != nil {
+		return fmt.Errorf("failed to create channel backend: %w", err)
+	}
+
+	ctx := cmd.Context()
+	channel, err := backend.Channel.Channel(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get channel: %w", err)
+	}
+	// The header goes to stderr so structured output stays parseable
+	fmt.Fprintf(os.Stderr, "%s  •  %d subscribers  •  %s\n", channel.Title, channel.SubscriberCount, channel.URL)
+
+	switch {
+	case channelPlaylists:
+		playlists, err := backend.Channel.Playlists(ctx, channel)
+		if err != nil {
+			return fmt.Errorf("failed to get channel playlists: %w", err)
+		}
+		if err := output.Playlists(os.Stdout, opts, playlists); err != nil {
+			return fmt.Errorf("failed to write playlists: %w", err)
+		}
+
+	case channelSearch != "":
+		config := &yt.SearchConfig{
+			MaxResults:    int64(min(channelLimit, 50)),
+			Order:         cfg.Search.Order,
+			SafeSearch:    cfg.Search.SafeSearch,
+			VideoDuration: cfg.Search.VideoDuration,
+			VideoType:     cfg.Search.VideoType,
+		}
+		results, err := backend.Channel.SearchChannel(ctx, channel, channelSearch, config)
+		if err != nil {
+			return fmt.Errorf("failed to search channel: %w", err)
+		}
+		if err := output.Videos(os.Stdout, opts, list.apply(results.Videos)); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+
+	default:
+		uploads, err := backend.Channel.Uploads(ctx, channel, channelLimit)
+		if err != nil {
+			return fmt.Errorf("failed to get channel uploads: %w", err)
+		}
+		if err := output.Videos(os.Stdout, opts, list.apply(uploads)); err != nil {
+			return fmt.Errorf("failed to write uploads: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(channelCmd)
+
+	channelCmd.Flags().BoolVar(&channelPlaylists, "playlists", false, "List the channel's playlists instead of its uploads")
+	channelCmd.Flags().StringVarP(&channelSearch, "search", "s", "", "Search within the channel")
+	channelCmd.Flags().IntVarP(&channelLimit, "limit", "l", defaultChannelLimit, "Maximum number of videos to list")
+	addListFlags(channelCmd)
+	channelCmd.MarkFlagsMutuallyExclusive("playlists", "search")
+	channelCmd.MarkFlagsMutuallyExclusive("playlists", "filter")
+	channelCmd.MarkFlagsMutuallyExclusive("playlists", "sort")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and write the gplay config file",
+	Long: `Read and write the gplay config file.
+
+Settings are resolved in the order defaults < config file < environment <
+flags. Any key can be overridden from the environment as GPLAY_<KEY> with
+dots replaced by underscores, e.g. GPLAY_SEARCH_ORDER=date. GOOGLE_API_KEY
+sets api_key.
+
+Keys:
+  ` + strings.Join(config.Keys(), "\n  ") + `
+
+Examples:
+  gplay config path
+  gplay config get search.order
+  gplay config set default_playlist PLxxxx
+  gplay config set keys.quit "[q, ctrl+c]"`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Print the effective value of a config key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := cfg.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Store a value in the config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := configPath()
+		if err != nil {
+			return err
+		}
+
+		// Only the file layer is edited so neither defaults nor environment
+		// values are written back to disk
+		return config.SetFile(path, args[0], args[1])
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config file location",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := configPath()
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configPathCmd)
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/alanpramil7/gplay/internal/output"
+	"github.com/alanpramil7/gplay/internal/player"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services"
+	"github.com/spf13/cobra"
+)
+
+// ctlCmd represents the ctl command
+var ctlCmd = &cobra.Command{
+	Use:   "ctl",
+	Short: "Control a running gplay daemon",
+	Long: `Control the player started with 'gplay daemon'. Each command prints what
+is playing afterwards, which makes them easy to bind to media keys.
+
+Examples:
+  gplay ctl play dQw4w9WgXcQ
+  gplay ctl queue add https://www.youtube.com/playlist?list=PLxxxx
+  gplay ctl toggle
+  gplay ctl next
+  gplay ctl status --format template --template '{{.State}} {{.Current.Title}}'`,
+}
+
+var ctlPlayCmd = &cobra.Command{
+	Use:   "play [url|id...]",
+	Short: "Play videos and playlists now, or resume without arguments",
+	Long: `Replace the queue with the given videos and playlists and start the
+first one. Without arguments, resume the paused track or start the queue.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return withDaemon((*player.Client).Resume)
+		}
+		videos, err := resolveVideos(cmd, args)
+		if err != nil {
+			return err
+		}
+		return withDaemon(func(c *player.Client) (player.Status, error) { return c.Play(videos, 0) })
+	},
+}
+
+var ctlPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause playback",
+	Args:  cobra.NoArgs,
+	RunE:  func(cmd *cobra.Command, args []string) error { return withDaemon((*player.Client).Pause) },
+}
+
+var ctlToggleCmd = &cobra.Command{
+	Use:   "toggle",
+	Short: "Pause or resume playback",
+	Args:  cobra.NoArgs,
+	RunE:  func(cmd *cobra.Command, args []string) error { return withDaemon((*player.Client).Toggle) },
+}
+
+var ctlStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop playback, keeping the queue",
+	Args:  cobra.NoArgs,
+	RunE:  func(cmd *cobra.Command, args []string) error { return withDaemon((*player.Client).Stop) },
+}
+
+var ctlNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Play the next track in the queue",
+	Args:  cobra.NoArgs,
+	RunE:  func(cmd *cobra.Command, args []string) error { return withDaemon((*player.Client).Next) },
+}
+
+var ctlPrevCmd = &cobra.Command{
+	Use:   "prev",
+	Short: "Play the previous track, or restart the current one",
+	Args:  cobra.NoArgs,
+	RunE:  func(cmd *cobra.Command, args []string) error { return withDaemon((*player.Client).Prev) },
+}
+
+var ctlStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show what the daemon is playing",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := outputOptions(cmd)
+		if err != nil {
+			return err
+		}
+		client, err := player.Dial(socketPath())
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		status, err := client.Status()
+		if err != nil {
+			return err
+		}
+		return output.Write(os.Stdout, opts, []player.Status{status}, statusTableColumns, statusCSVColumns)
+	},
+}
+
+var ctlQueueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "List the queued tracks",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := outputOptions(cmd)
+		if err != nil {
+			return err
+		}
+		client, err := player.Dial(socketPath())
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		videos, _, err := client.Queue()
+		if err != nil {
+			return err
+		}
+		return output.Videos(os.Stdout, opts, videos)
+	},
+}
+
+var ctlQueueAddCmd = &cobra.Command{
+	Use:   "add <url|id>...",
+	Short: "Add videos and playlists to the end of the queue",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		videos, err := resolveVideos(cmd, args)
+		if err != nil {
+			return err
+		}
+		return withDaemon(func(c *player.Client) (player.Status, error) { return c.Add(videos) })
+	},
+}
+
+// withDaemon runs one call against the daemon and prints the status it
+// leaves behind
+func withDaemon(call func(*player.Client) (player.Status, error)) error {
+	client, err := player.Dial(socketPath())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	status, err := call(client)
+	if err != nil {
+		return err
+	}
+	fmt.Println(describeStatus(status))
+	return nil
+}
+
+// resolveVideos turns video and playlist references into the videos to
+// queue. Playlists are listed through the configured backend.
+func resolveVideos(cmd *cobra.Command, args []string) ([]yt.Video, error) {
+	var (
+		videos  []yt.Video
+		backend *services.Backend
+	)
+	for _, arg := range args {
+		ref, err := yt.ParseRef(arg)
+		if err != nil {
+			return nil, err
+		}
+		switch ref.Kind {
+		case yt.RefVideo:
+			videos = append(videos, yt.Video{ID: ref.VideoID, URL: yt.VideoURL(ref.VideoID)})
+		case yt.RefPlaylist:
+			if backend == nil {
+				if backend, err = newBackend(cfg.Search.MaxResults); err != nil {
+					return nil, err
+				}
+			}
+			items, err := backend.Playlist.GetPlaylistItemsContext(cmd.Context(), ref.PlaylistID, services.MaxPlaylistPageSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get playlist details: %w", err)
+			}
+			videos = append(videos, items...)
+		default:
+			return nil, fmt.Errorf("%q is a %s; only videos and playlists can be played", arg, ref.Kind)
+		}
+	}
+	return videos, nil
+}
+
+// describeStatus is the one line summary printed after each ctl command
+func describeStatus(s player.Status) string {
+	if s.Current == nil {
+		return string(s.State)
+	}
+	line := fmt.Sprintf("%s: %s  [%d/%d]", s.State, statusTitle(s), s.Index+1, s.Queue)
+	if s.Error != "" {
+		line += "\nError: " + s.Error
+	}
+	return line
+}
+
+func statusTitle(s player.Status) string {
+	if s.Current == nil {
+		return ""
+	}
+	if s.Current.Title != "" {
+		return s.Current.Title
+	}
+	return s.Current.URL
+}
+
+// statusPosition is the position in the track, with its length if known
+func statusPosition(s player.Status) string {
+	switch {
+	case s.Current == nil:
+		return ""
+	case s.Live:
+		return "live"
+	case s.Current.Length > 0:
+		return yt.FormatDuration(s.Position) + " / " + yt.FormatDuration(s.Current.Length)
+	}
+	return yt.FormatDuration(s.Position)
+}
+
+var statusTableColumns = []output.Column[player.Status]{
+	{Header: "STATE", Value: func(s player.Status) string { return string(s.State) }},
+	{Header: "TITLE", Value: statusTitle},
+	{Header: "POSITION", Value: statusPosition},
+	{Header: "QUEUE", Value: func(s player.Status) string {
+		if s.Current == nil {
+			return strconv.Itoa(s.Queue)
+		}
+		return fmt.Sprintf("%d/%d", s.Index+1, s.Queue)
+	}},
+}
+
+var statusCSVColumns = []output.Column[player.Status]{
+	{Header: "state", Value: func(s player.Status) string { return string(s.State) }},
+	{Header: "id", Value: func(s player.Status) string {
+		if s.Current == nil {
+			return ""
+		}
+		return s.Current.ID
+	}},
+	{Header: "title", Value: statusTitle},
+	{Header: "position", Value: func(s player.Status) string { return strconv.FormatFloat(s.Position.Seconds(), 'f', 1, 64) }},
+	{Header: "index", Value: func(s player.Status) string { return strconv.Itoa(s.Index) }},
+	{Header: "queue", Value: func(s player.Status) string { return strconv.Itoa(s.Queue) }},
+	{Header: "error", Value: func(s player.Status) string { return s.Error }},
+}
+
+func init() {
+	ctlQueueCmd.AddCommand(ctlQueueAddCmd)
+	ctlCmd.AddCommand(ctlPlayCmd, ctlPauseCmd, ctlToggleCmd, ctlStopCmd, ctlNextCmd, ctlPrevCmd, ctlStatusCmd, ctlQueueCmd)
+	rootCmd.AddCommand(ctlCmd)
+}
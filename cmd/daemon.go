@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/player"
+	"github.com/spf13/cobra"
+)
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Play music in the background, controlled over a socket",
+	Long: `Run a player that owns audio playback and a queue of tracks, controlled
+with 'gplay ctl' and the TUI over a Unix socket.
+
+While the daemon runs, the TUI plays through it and hands it the rest of
+the list, so closing the TUI does not stop the music. Tracks played by
+the daemon are added to the play history.
+
+The socket is $XDG_RUNTIME_DIR/gplay/daemon.sock unless daemon.socket is
+set. It speaks JSON-RPC 1.0 with the methods Player.Play, Player.Add,
+Player.Resume, Player.Pause, Player.Toggle, Player.Next, Player.Prev,
+Player.Stop, Player.Status and Player.Queue.
+
+Examples:
+  gplay daemon &
+  gplay ctl play https://www.youtube.com/playlist?list=PLxxxx
+  gplay ctl toggle  # e.g. bound to the play/pause media key`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Service managers stop daemons with SIGTERM
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM)
+		defer stop()
+
+		backend, err := newBackend(cfg.Search.MaxResults)
+		if err != nil {
+			return err
+		}
+		audio := newAudioService(backend)
+		defer audio.Stop()
+
+		var history *library.History
+		if path, err := library.DefaultHistoryPath(); err == nil {
+			if history, err = library.LoadHistory(path); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+
+		path := socketPath()
+		listener, err := player.Listen(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", path)
+		return player.Serve(ctx, listener, player.New(audio, history))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/alanpramil7/gplay/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check dependencies and environment",
+	Long: `Check that everything gplay needs is installed and configured:
+
+  • yt-dlp and ffmpeg are on PATH and recent enough
+  • the YouTube API key is valid
+  • an audio output device can be opened
+  • the config and cache directories are writable
+
+Exits with a non-zero status when any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+// runDoctor executes the doctor command
+func runDoctor(cmd *cobra.Command, args []string) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	results := doctor.Run(doctor.Options{Config: cfg, ConfigPath: path})
+	fmt.Print(doctor.Report(results))
+
+	if doctor.Failed(results) {
+		return fmt.Errorf("some checks failed")
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
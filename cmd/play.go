@@ -1,32 +1,58 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 
-	"github.com/alanpramil7/gplay/internal/yt/services"
+	"github.com/alanpramil7/gplay/internal/player"
+	"github.com/alanpramil7/gplay/internal/yt"
 	"github.com/spf13/cobra"
 )
 
 // playCmd represents the play command
 var playCmd = &cobra.Command{
-	Use:   "play [url]",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Use:   "play [url|id]",
+	Short: "Play a video",
+	Long: `Play the audio of a video until interrupted. When 'gplay daemon' is
+running, the video is handed to it instead and the command returns right
+away.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		url := args[0]
+		id, err := yt.ParseVideoID(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		url := yt.VideoURL(id)
+
+		// A running daemon plays it in the background instead
+		if client, err := player.Dial(socketPath()); err == nil {
+			defer client.Close()
+			status, err := client.Play([]yt.Video{{ID: id, URL: url}}, 0)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(describeStatus(status))
+			return
+		}
+
 		fmt.Println("play called with url", url)
-		as := services.NewAudioService()
-		err := as.PlayStream(url)
+		// Stream resolution can work without a backend, so a backend error
+		// only means falling back to yt-dlp
+		backend, _ := newBackend(cfg.Search.MaxResults)
+		as := newAudioService(backend)
+		err = as.PlayStreamContext(cmd.Context(), url)
+		if errors.Is(err, context.Canceled) {
+			// Interrupted while the stream was being resolved
+			return
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
-		select {}
+		// Play until interrupted
+		<-cmd.Context().Done()
+		as.Stop()
 	},
 }
 
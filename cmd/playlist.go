@@ -1,53 +1,206 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"os"
+	"strings"
 
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/output"
 	"github.com/alanpramil7/gplay/internal/yt"
 	"github.com/alanpramil7/gplay/internal/yt/services"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pushTo          string
+	pushCreate      string
+	pushDescription string
+	pushPrivacy     string
+	pushDryRun      bool
+)
+
 // playlistCmd represents the playlist command
 var playlistCmd = &cobra.Command{
-	Use:   "playlist [playlistId]",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		playlistId := args[0]
-
-		client, err := yt.NewClient()
-		if err != nil {
-			log.Fatalf("Error creating YouTube client: %v", err)
+	Use:   "playlist [playlistId|url]",
+	Short: "List the videos in a YouTube playlist",
+	Long: `List the videos in a YouTube playlist using the YouTube Data API.
+The playlist can be given as an ID, a playlist URL or a watch URL with a
+list= parameter.
+
+Examples:
+  gplay playlist PLxxxx
+  gplay playlist "https://music.youtube.com/playlist?list=PLxxxx"
+  gplay playlist PLxxxx --format csv > playlist.csv
+  gplay playlist PLxxxx --template '{{.URL}}'
+  gplay playlist PLxxxx --filter 'views>1m' --sort published:desc`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlaylist,
+}
+
+// runPlaylist executes the playlist command
+func runPlaylist(cmd *cobra.Command, args []string) error {
+	playlistId, err := yt.ParsePlaylistID(args[0])
+	if err != nil {
+		return err
+	}
+
+	opts, err := outputOptions(cmd)
+	if err != nil {
+		return err
+	}
+	list, err := parseListOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	backend, err := newBackend(cfg.Search.MaxResults)
+	if err != nil {
+		return fmt.Errorf("failed to create playlist backend: %w", err)
+	}
+
+	res, err := backend.Playlist.GetPlaylistItemsContext(cmd.Context(), playlistId, services.MaxPlaylistPageSize)
+	if err != nil {
+		return fmt.Errorf("failed to get playlist details: %w", err)
+	}
+
+	if err := output.Videos(os.Stdout, opts, list.apply(res)); err != nil {
+		return fmt.Errorf("failed to write playlist: %w", err)
+	}
+
+	return nil
+}
+
+var playlistPushCmd = &cobra.Command{
+	Use:   "push <file|->",
+	Short: "Make one of your YouTube playlists match a local playlist",
+	Long: `Make one of your YouTube playlists match a local playlist, adding,
+removing and reordering its entries. Needs 'gplay auth login'.
+
+The local playlist is a file, or - for stdin, with one video URL or ID per
+line (M3U files work), or the JSON output of another gplay command.
+
+Every edit costs 50 quota units, so check large changes with --dry-run.
+
+Examples:
+  gplay playlist push mix.m3u --to PLxxxx
+  gplay playlist push mix.m3u --create "Road trip" --privacy unlisted
+  gplay search "lofi" --format json | gplay playlist push - --to PLxxxx --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlaylistPush,
+}
+
+func runPlaylistPush(cmd *cobra.Command, args []string) error {
+	if (pushTo == "") == (pushCreate == "") {
+		return errors.New("give exactly one of --to and --create")
+	}
+	privacy, err := services.ParsePrivacy(pushPrivacy)
+	if err != nil {
+		return err
+	}
+
+	var local []yt.Video
+	if args[0] == "-" {
+		local, err = library.ReadPlaylist(os.Stdin)
+	} else {
+		local, err = library.LoadPlaylist(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	var (
+		editor     services.PlaylistEditor
+		playlistID string
+		remote     []yt.PlaylistItem
+	)
+	// A dry run against a new playlist needs nothing from YouTube
+	if pushTo != "" || !pushDryRun {
+		if editor, err = playlistEditor(); err != nil {
+			return err
+		}
+	}
+	if pushTo != "" {
+		if playlistID, err = yt.ParsePlaylistID(pushTo); err != nil {
+			return err
 		}
+		if remote, err = editor.Items(ctx, playlistID); err != nil {
+			return err
+		}
+	}
 
-		playlistService := services.NewPlaylistService(client)
-		res, err := playlistService.GetPlaylistItems(playlistId, 100)
-		if err != nil {
-			log.Fatalf("Error getting playlist details: %v", err)
+	changes := library.Plan(local, remote)
+	if pushDryRun {
+		for _, change := range changes {
+			fmt.Println(change)
 		}
-		for _, r := range res {
-			fmt.Println(r.URL)
+		if pushCreate != "" {
+			fmt.Fprintf(os.Stderr, "Would create %s playlist %q\n", privacy, pushCreate)
 		}
-	},
+		fmt.Fprintf(os.Stderr, "%s, about %d quota units\n", library.Summary(changes), pushCost(changes, pushCreate != ""))
+		return nil
+	}
+
+	if pushCreate != "" {
+		playlist, err := editor.CreatePlaylist(ctx, pushCreate, pushDescription, privacy)
+		if err != nil {
+			return err
+		}
+		playlistID = playlist.ID
+		fmt.Fprintf(os.Stderr, "Created %s\n", yt.PlaylistURL(playlistID))
+	}
+
+	err = services.ApplyChanges(ctx, editor, playlistID, changes, func(change library.Change) {
+		fmt.Println(change)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push playlist: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, library.Summary(changes))
+	return nil
 }
 
-func init() {
-	rootCmd.AddCommand(playlistCmd)
+// changeCalls maps each kind of change to the API call that makes it
+var changeCalls = map[library.ChangeKind]string{
+	library.ChangeAdd:    yt.CallPlaylistItemsInsert,
+	library.ChangeRemove: yt.CallPlaylistItemsDelete,
+	library.ChangeMove:   yt.CallPlaylistItemsUpdate,
+}
+
+// pushCost estimates the quota a push spends
+func pushCost(changes []library.Change, create bool) int64 {
+	cost := yt.QuotaCosts[yt.CallPlaylistItemsList]
+	if create {
+		cost = yt.QuotaCosts[yt.CallPlaylistsInsert]
+	}
+	for _, change := range changes {
+		cost += yt.QuotaCosts[changeCalls[change.Kind]]
+	}
+	return cost
+}
 
-	// Here you will define your flags and configuration settings.
+// playlistEditor returns the editor for the logged in user's playlists
+func playlistEditor() (services.PlaylistEditor, error) {
+	backend, err := newBackend(0)
+	if err != nil {
+		return nil, err
+	}
+	if backend.Editor == nil {
+		return nil, services.ErrNoLibrary
+	}
+	return backend.Editor, nil
+}
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// playlistCmd.PersistentFlags().String("foo", "", "A help for foo")
+func init() {
+	playlistPushCmd.Flags().StringVar(&pushTo, "to", "", "Playlist ID or URL to update")
+	playlistPushCmd.Flags().StringVar(&pushCreate, "create", "", "Create a playlist with this title instead")
+	playlistPushCmd.Flags().StringVar(&pushDescription, "description", "", "Description of the created playlist")
+	playlistPushCmd.Flags().StringVar(&pushPrivacy, "privacy", services.PrivacyPrivate, "Privacy of the created playlist ("+strings.Join(services.Privacies, ", ")+")")
+	playlistPushCmd.Flags().BoolVar(&pushDryRun, "dry-run", false, "Only print the changes")
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// playlistCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	playlistCmd.AddCommand(playlistPushCmd)
+	rootCmd.AddCommand(playlistCmd)
+	addListFlags(playlistCmd)
 }
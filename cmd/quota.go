@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// quotaRow is one API call type in the quota report
+type quotaRow struct {
+	Call  string `json:"call"`
+	Calls int64  `json:"calls"`
+	Units int64  `json:"units"`
+}
+
+var quotaColumns = []output.Column[quotaRow]{
+	{Header: "CALL", Value: func(r quotaRow) string { return r.Call }},
+	{Header: "CALLS", Value: func(r quotaRow) string { return strconv.FormatInt(r.Calls, 10) }},
+	{Header: "UNITS", Value: func(r quotaRow) string { return strconv.FormatInt(r.Units, 10) }},
+}
+
+// quotaCmd represents the quota command
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Show today's YouTube Data API quota usage",
+	Long: `Show today's YouTube Data API quota usage by call type.
+
+Google resets the quota at midnight Pacific time. Searches cost 100 units,
+video and playlist lookups cost 1. Set the daily budget with
+'gplay config set quota.budget 10000'.`,
+	Args: cobra.NoArgs,
+	RunE: runQuota,
+}
+
+// runQuota executes the quota command
+func runQuota(cmd *cobra.Command, args []string) error {
+	opts, err := outputOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	tracker, err := newQuotaTracker()
+	if err != nil {
+		return err
+	}
+	usage, err := tracker.Usage()
+	if err != nil {
+		return err
+	}
+
+	rows := make([]quotaRow, 0, len(usage.Calls))
+	for _, call := range usage.CallNames() {
+		rows = append(rows, quotaRow{Call: call, Calls: usage.Calls[call], Units: usage.Units[call]})
+	}
+	if err := output.Write(os.Stdout, opts, rows, quotaColumns, quotaColumns); err != nil {
+		return fmt.Errorf("failed to write quota usage: %w", err)
+	}
+
+	// The summary goes to stderr so structured output stays parseable
+	budget := "unlimited"
+	if tracker.Budget() > 0 {
+		budget = strconv.FormatInt(tracker.Budget(), 10)
+	}
+	fmt.Fprintf(os.Stderr, "Used %d of %s units on %s, resets in %s\n",
+		usage.Total(), budget, usage.Day, time.Until(tracker.NextReset()).Round(time.Minute))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(quotaCmd)
+}
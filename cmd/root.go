@@ -1,9 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
 
+	"github.com/alanpramil7/gplay/internal/config"
+	"github.com/alanpramil7/gplay/internal/filter"
+	"github.com/alanpramil7/gplay/internal/output"
 	"github.com/alanpramil7/gplay/internal/tui"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
@@ -16,34 +27,138 @@ const (
 Features:
   • Search YouTube videos with advanced filters
   • Interactive TUI interface
-  • Multiple output formats (table, JSON)
+  • Multiple output formats (table, JSON, JSONL, CSV, Go templates)
   • Configurable search parameters
 
 Examples:
   gplay search "golang tutorial"
   gplay search "music" --max 10 --order viewCount
+  gplay search "lofi" --format json | jq '.[].url'
+  gplay playlist PLxxxx --format template --template '{{.Title}} {{.URL}}'
   gplay  # Launch interactive TUI`
 )
 
+var (
+	cfgFile        string
+	cfg            *config.Config
+	backendName    string
+	outputFormat   string
+	outputTemplate string
+	noCache        bool
+	refreshCache   bool
+	noExclude      bool
+)
+
+// errInterrupted is reported when Ctrl+C aborts a command
+var errInterrupted = errors.New("interrupted")
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   appName,
 	Short: appDescription,
 	Long:  appLongDesc,
 	RunE:  runTUI,
+
+	PersistentPreRunE: loadConfig,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
-	return rootCmd.Execute()
+	// Ctrl+C cancels the command context so in-flight requests and yt-dlp
+	// processes are aborted cleanly. Once it has fired, a second Ctrl+C
+	// kills the process as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if errors.Is(err, context.Canceled) {
+		return errInterrupted
+	}
+	if hint := errorHint(err); hint != "" {
+		return fmt.Errorf("%w\n%s", err, hint)
+	}
+	return err
+}
+
+// errorHint suggests what to do about the typed YouTube errors
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, yt.ErrKeyInvalid):
+		return "Set a valid key with 'gplay config set api_key <key>' or use --backend ytdlp"
+	case errors.Is(err, yt.ErrQuotaExceeded), errors.Is(err, yt.ErrQuotaBudgetExceeded):
+		return "The quota resets at midnight Pacific time; check usage with 'gplay quota' or use --backend ytdlp"
+	case errors.Is(err, yt.ErrPrivatePlaylist):
+		return "Private playlists cannot be read with an API key; make the playlist public or unlisted"
+	case errors.Is(err, yt.ErrNotFound):
+		return "Check that the ID or URL is correct"
+	}
+	return ""
+}
+
+// loadConfig resolves the config file path and loads the layered config
+// before any command runs
+func loadConfig(cmd *cobra.Command, args []string) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	cfg, err = config.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cmd.Flags().Changed("backend") {
+		cfg.Backend = backendName
+	}
+
+	// Arguments have been validated by now, so any later error is a runtime
+	// failure where printing usage would only hide the message
+	cmd.SilenceUsage = true
+	return nil
+}
+
+// configPath returns the --config flag value or the default location
+func configPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return config.DefaultPath()
 }
 
 // runTUI launches the interactive TUI interface
 func runTUI(cmd *cobra.Command, args []string) error {
-	app := tui.NewApp()
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	// Without a quota file the TUI only loses the usage warning
+	quota, _ := newQuotaTracker()
+	lyricsProvider, lyricsErr := newLyricsProvider()
+	app := tui.NewApp(cfg, path, tui.Deps{
+		Connect:    newBackend,
+		Quota:      quota,
+		Lyrics:     lyricsProvider,
+		LyricsErr:  lyricsErr,
+		SocketPath: socketPath(),
+	})
 	program := tea.NewProgram(app, tea.WithAltScreen())
 
+	// Services log warnings such as quota fallbacks, which printed to
+	// stderr would land on top of the alternate screen
+	flags := log.Flags()
+	log.SetOutput(tui.LogWriter(program))
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}()
+
 	if _, err := program.Run(); err != nil {
 		return fmt.Errorf("failed to run TUI application: %w", err)
 	}
@@ -51,7 +166,65 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// outputOptions resolves the global --format and --template flags. Passing
+// --template on its own implies the template format.
+func outputOptions(cmd *cobra.Command) (output.Options, error) {
+	name := outputFormat
+	if outputTemplate != "" && !cmd.Flags().Changed("format") {
+		name = string(output.FormatTemplate)
+	}
+
+	format, err := output.ParseFormat(name)
+	if err != nil {
+		return output.Options{}, err
+	}
+
+	return output.Options{Format: format, Template: outputTemplate}, nil
+}
+
+// listOptions holds the --filter and --sort flags of the commands that list
+// videos
+type listOptions struct {
+	filter *filter.Expr
+	sort   *filter.Sort
+}
+
+// addListFlags adds --filter and --sort to a command that lists videos
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().String("filter", "", `Only keep videos matching an expression, e.g. 'duration>3m and views>100k and channel~"lofi"'`)
+	cmd.Flags().String("sort", "", "Sort by "+strings.Join(filter.SortKeys, ", ")+"; append :desc to reverse, separate keys with commas")
+}
+
+// parseListOptions parses --filter and --sort before any request is made
+func parseListOptions(cmd *cobra.Command) (listOptions, error) {
+	expr, _ := cmd.Flags().GetString("filter")
+	spec, _ := cmd.Flags().GetString("sort")
+
+	var opts listOptions
+	var err error
+	if opts.filter, err = filter.Parse(expr); err != nil {
+		return opts, err
+	}
+	if opts.sort, err = filter.ParseSort(spec); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// apply filters and sorts videos
+func (o listOptions) apply(videos []yt.Video) []yt.Video {
+	videos = o.filter.Apply(videos)
+	o.sort.Apply(videos)
+	return videos
+}
+
 func init() {
 	// Add any global flags here
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gplay.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/gplay/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&backendName, "backend", services.BackendAuto, "Search and playlist backend ("+strings.Join(services.Backends, ", ")+")")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", string(output.FormatTable), "Output format ("+output.FormatNames()+")")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Bypass the API response cache")
+	rootCmd.PersistentFlags().BoolVar(&refreshCache, "refresh", false, "Ignore cached API responses and fetch fresh ones")
+	rootCmd.PersistentFlags().BoolVar(&noExclude, "no-exclude", false, "Keep Shorts, live streams and other videos the exclude rules drop")
+	rootCmd.PersistentFlags().StringVar(&outputTemplate, "template", "", "Go template applied to each item, e.g. '{{.Title}} {{.URL}}'")
 }
@@ -2,19 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/alanpramil7/gplay/internal/config"
+	"github.com/alanpramil7/gplay/internal/output"
 	"github.com/alanpramil7/gplay/internal/yt"
 	"github.com/alanpramil7/gplay/internal/yt/services"
 	"github.com/spf13/cobra"
 )
 
-const (
-	defaultMaxResults    = int64(5)
-	defaultOrder         = "relevance"
-	defaultSafeSearch    = "moderate"
-	defaultVideoDuration = "any"
-	defaultVideoType     = "any"
-)
+const defaultSearchLimit = 50
 
 var (
 	maxResults    int64
@@ -22,6 +21,20 @@ var (
 	safeSearch    string
 	videoDuration string
 	videoType     string
+	pageToken     string
+	fetchAll      bool
+	searchLimit   int
+
+	// Search filters
+	publishedAfter    string
+	publishedBefore   string
+	regionCode        string
+	relevanceLanguage string
+	videoCategory     string
+	eventType         string
+	videoCaption      string
+	videoDefinition   string
+	searchChannel     string
 )
 
 // searchCmd represents the search command
@@ -33,7 +46,16 @@ var searchCmd = &cobra.Command{
 Examples:
   gplay search "golang tutorial"
   gplay search "music" --max 10 --order viewCount
-  gplay search "cooking" --duration short --format json`,
+  gplay search "cooking" --duration short --format json
+  gplay search "lofi" --page-token CAUQAA
+  gplay search "lofi" --all --limit 120
+  gplay search "new music" --after 7d --category music --region GB
+  gplay search "keynote" --event completed --captions closedCaption
+  gplay search "study" --channel @LofiGirl
+  gplay search "lofi" --all --filter 'duration>3m and duration<8m' --sort views:desc
+
+A pasted playlist or channel URL lists that playlist or the channel's
+uploads instead of searching for it.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSearch,
 }
@@ -42,14 +64,27 @@ Examples:
 func runSearch(cmd *cobra.Command, args []string) error {
 	searchQuery := args[0]
 
-	// Create YouTube client
-	client, err := yt.NewClient()
+	opts, err := outputOptions(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to create YouTube client: %w", err)
+		return err
 	}
+	list, err := parseListOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	applySearchDefaults(cmd)
 
 	// Create search service
-	searchService := services.NewSearchService(client, maxResults)
+	backend, err := newBackend(maxResults)
+	if err != nil {
+		return fmt.Errorf("failed to create search backend: %w", err)
+	}
+
+	if ref, err := yt.ParseURL(searchQuery); err == nil {
+		return listRef(cmd, backend, ref, opts, list)
+	}
+	searchService := backend.Search
 
 	// Create search configuration
 	config := &yt.SearchConfig{
@@ -58,33 +93,145 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		SafeSearch:    safeSearch,
 		VideoDuration: videoDuration,
 		VideoType:     videoType,
+		PageToken:     pageToken,
+	}
+	if err := applySearchFilters(cmd, backend, config); err != nil {
+		return err
 	}
 
-	// Perform search
-	results, err := searchService.SearchWithConfig(searchQuery, config)
+	// Perform search, following pages when --all is set
+	var results *yt.SearchResponse
+	if fetchAll {
+		results, err = services.SearchAllContext(cmd.Context(), searchService, searchQuery, config, searchLimit)
+	} else {
+		results, err = searchService.SearchWithConfigContext(cmd.Context(), searchQuery, config)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to perform search: %w", err)
 	}
 
 	// Display results
-	for _, result := range results.Videos {
-		fmt.Printf("Title: %s\n", result.Title)
-		fmt.Printf("Channel: %s\n", result.ChannelTitle)
-		fmt.Printf("URL: %s\n", result.URL)
-		fmt.Printf("Thumbnail: %s\n", result.ThumbnailURL)
-		fmt.Println("---")
+	if err := output.Videos(os.Stdout, opts, list.apply(results.Videos)); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+
+	// Keep stdout clean for pipes
+	if results.NextPageToken != "" {
+		fmt.Fprintf(os.Stderr, "Next page token: %s\n", results.NextPageToken)
 	}
 
 	return nil
 }
 
+// applySearchFilters parses the filter flags into config, resolving a
+// channel handle or URL to its ID
+func applySearchFilters(cmd *cobra.Command, backend *services.Backend, config *yt.SearchConfig) error {
+	now := time.Now()
+	var err error
+	if config.PublishedAfter, err = yt.ParseTimeBound(publishedAfter, now); err != nil {
+		return fmt.Errorf("invalid --after: %w", err)
+	}
+	if config.PublishedBefore, err = yt.ParseTimeBound(publishedBefore, now); err != nil {
+		return fmt.Errorf("invalid --before: %w", err)
+	}
+	if config.VideoCategoryID, err = yt.ParseCategory(videoCategory); err != nil {
+		return err
+	}
+	config.RegionCode = strings.ToUpper(regionCode)
+	config.RelevanceLanguage = relevanceLanguage
+	config.EventType = eventType
+	config.VideoCaption = videoCaption
+	config.VideoDefinition = videoDefinition
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	if searchChannel != "" {
+		channel, err := backend.Channel.Channel(cmd.Context(), searchChannel)
+		if err != nil {
+			return fmt.Errorf("failed to find channel: %w", err)
+		}
+		config.ChannelID = channel.ID
+	}
+	return nil
+}
+
+// listRef writes the videos a pasted URL points at
+func listRef(cmd *cobra.Command, backend *services.Backend, ref yt.Ref, opts output.Options, list listOptions) error {
+	var videos []yt.Video
+	var err error
+	switch {
+	case ref.PlaylistID != "":
+		videos, err = backend.Playlist.GetPlaylistItemsContext(cmd.Context(), ref.PlaylistID, services.MaxPlaylistPageSize)
+	case ref.Kind == yt.RefChannel:
+		var channel *yt.Channel
+		channel, err = backend.Channel.Channel(cmd.Context(), ref.ID())
+		if err == nil {
+			videos, err = backend.Channel.Uploads(cmd.Context(), channel, searchLimit)
+		}
+	default:
+		return fmt.Errorf("%q is a single video, play it with 'gplay play'", ref.URL())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", ref.Kind, err)
+	}
+
+	if err := output.Videos(os.Stdout, opts, list.apply(videos)); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// applySearchDefaults fills every search flag the user did not pass with the
+// value from the config file or environment
+func applySearchDefaults(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	if !flags.Changed("max") {
+		maxResults = cfg.Search.MaxResults
+	}
+	if !flags.Changed("order") {
+		order = cfg.Search.Order
+	}
+	if !flags.Changed("safe") {
+		safeSearch = cfg.Search.SafeSearch
+	}
+	if !flags.Changed("duration") {
+		videoDuration = cfg.Search.VideoDuration
+	}
+	if !flags.Changed("type") {
+		videoType = cfg.Search.VideoType
+	}
+	if !flags.Changed("region") {
+		regionCode = cfg.Search.RegionCode
+	}
+	if !flags.Changed("language") {
+		relevanceLanguage = cfg.Search.RelevanceLanguage
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(searchCmd)
 
-	// Search parameters
-	searchCmd.Flags().Int64VarP(&maxResults, "max", "m", defaultMaxResults, "Maximum number of results to return (1-50)")
-	searchCmd.Flags().StringVarP(&order, "order", "o", defaultOrder, "Order of results (relevance, date, rating, viewCount, title)")
-	searchCmd.Flags().StringVarP(&safeSearch, "safe", "s", defaultSafeSearch, "Safe search level (none, moderate, strict)")
-	searchCmd.Flags().StringVarP(&videoDuration, "duration", "d", defaultVideoDuration, "Video duration (any, short, medium, long)")
-	searchCmd.Flags().StringVarP(&videoType, "type", "t", defaultVideoType, "Video type (any, episode, movie)")
+	// Search parameters, defaulting to the built-in config
+	defaults := config.Default().Search
+	searchCmd.Flags().Int64VarP(&maxResults, "max", "m", defaults.MaxResults, "Maximum number of results to return (1-50)")
+	searchCmd.Flags().StringVarP(&order, "order", "o", defaults.Order, "Order of results (relevance, date, rating, viewCount, title)")
+	searchCmd.Flags().StringVarP(&safeSearch, "safe", "s", defaults.SafeSearch, "Safe search level (none, moderate, strict)")
+	searchCmd.Flags().StringVarP(&videoDuration, "duration", "d", defaults.VideoDuration, "Video duration (any, short, medium, long)")
+	searchCmd.Flags().StringVarP(&videoType, "type", "t", defaults.VideoType, "Video type (any, episode, movie)")
+	searchCmd.Flags().StringVar(&pageToken, "page-token", "", "Page token from a previous search to continue from")
+	searchCmd.Flags().BoolVar(&fetchAll, "all", false, "Follow result pages until --limit videos are collected")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", defaultSearchLimit, "Total number of results to collect with --all")
+
+	// Filters
+	searchCmd.Flags().StringVar(&publishedAfter, "after", "", "Only videos published after a date (YYYY-MM-DD, RFC 3339 or an age like 7d)")
+	searchCmd.Flags().StringVar(&publishedBefore, "before", "", "Only videos published before a date (YYYY-MM-DD, RFC 3339 or an age like 7d)")
+	searchCmd.Flags().StringVar(&regionCode, "region", "", "Return results for a country (ISO 3166-1 alpha-2, e.g. US)")
+	searchCmd.Flags().StringVar(&relevanceLanguage, "language", "", "Prefer results in a language (ISO 639-1, e.g. en)")
+	searchCmd.Flags().StringVar(&videoCategory, "category", "", "Video category ID or name (music, gaming, news, ...)")
+	searchCmd.Flags().StringVar(&eventType, "event", "", "Broadcasts only (live, upcoming, completed)")
+	searchCmd.Flags().StringVar(&videoCaption, "captions", "", "Caption filter (any, closedCaption, none)")
+	searchCmd.Flags().StringVar(&videoDefinition, "definition", "", "Definition filter (any, high, standard)")
+	searchCmd.Flags().StringVar(&searchChannel, "channel", "", "Only search one channel (ID, @handle or URL)")
+	addListFlags(searchCmd)
 }
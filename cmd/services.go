@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/auth"
+	"github.com/alanpramil7/gplay/internal/filter"
+	"github.com/alanpramil7/gplay/internal/lyrics"
+	"github.com/alanpramil7/gplay/internal/player"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services"
+)
+
+// newBackend creates the search and playlist services selected by --backend
+// or the config
+func newBackend(maxResults int64) (*services.Backend, error) {
+	opts, err := backendOptions()
+	if err != nil {
+		return nil, err
+	}
+	return services.NewBackend(opts, maxResults)
+}
+
+// backendOptions builds the backend options from the config and flags
+func backendOptions() (services.BackendOptions, error) {
+	quota, err := newQuotaTracker()
+	if err != nil {
+		return services.BackendOptions{}, err
+	}
+	cache, err := newCache()
+	if err != nil {
+		return services.BackendOptions{}, err
+	}
+
+	authorized, loginID, err := authorizedClient()
+	if err != nil {
+		return services.BackendOptions{}, err
+	}
+
+	var exclude *filter.Rules
+	if !noExclude {
+		if exclude, err = excludeRules(); err != nil {
+			return services.BackendOptions{}, err
+		}
+	}
+
+	return services.BackendOptions{
+		Name:       cfg.Backend,
+		APIKey:     cfg.APIKey,
+		Authorized: authorized,
+		LoginID:    loginID,
+		YtDlpPath:  cfg.YtDlpPath,
+		Instances:  cfg.Instances,
+		Quota:      quota,
+		Cache:      cache,
+		Retry:      yt.RetryPolicy{MaxRetries: cfg.API.MaxRetries, BaseDelay: cfg.API.RetryDelay, MaxDelay: cfg.API.MaxRetryDelay},
+		RateLimit: yt.NewRateLimiter(map[string]float64{
+			yt.CallSearchList:        cfg.API.RateLimit.Search,
+			yt.CallVideosList:        cfg.API.RateLimit.Videos,
+			yt.CallPlaylistItemsList: cfg.API.RateLimit.PlaylistItems,
+		}),
+		Exclude:      exclude,
+		ExcludeLists: cfg.Exclude.Lists,
+		Region:       cfg.Search.RegionCode,
+	}, nil
+}
+
+// excludeRules compiles the exclude section of the config
+func excludeRules() (*filter.Rules, error) {
+	c := cfg.Exclude
+	rules, err := filter.NewRules(c.Shorts, c.Live, c.MinDuration, c.MaxDuration, c.TitlePatterns, c.Categories)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude config: %w", err)
+	}
+	rules.ShortMaxLength = c.ShortMaxLength
+	return rules, nil
+}
+
+// newCache creates the API response cache, honouring --no-cache and
+// --refresh
+func newCache() (*yt.Cache, error) {
+	dir, err := yt.DefaultCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	mode := yt.CacheNormal
+	switch {
+	case noCache || !cfg.Cache.Enabled:
+		mode = yt.CacheDisabled
+	case refreshCache:
+		mode = yt.CacheRefresh
+	}
+	return yt.NewCache(dir, mode, map[string]time.Duration{
+		yt.CallSearchList:        cfg.Cache.SearchTTL,
+		yt.CallPlaylistItemsList: cfg.Cache.PlaylistTTL,
+		yt.CallVideosList:        cfg.Cache.VideoTTL,
+	}), nil
+}
+
+// newQuotaTracker creates the shared Data API usage tracker
+func newQuotaTracker() (*yt.QuotaTracker, error) {
+	path, err := yt.DefaultQuotaPath()
+	if err != nil {
+		return nil, err
+	}
+	return yt.NewQuotaTracker(path, cfg.Quota.Budget), nil
+}
+
+// newAuthenticator creates the OAuth authenticator with the token in its
+// default location
+func newAuthenticator() (*auth.Authenticator, error) {
+	path, err := auth.DefaultTokenPath()
+	if err != nil {
+		return nil, err
+	}
+	return auth.New(auth.Options{ClientID: cfg.OAuth.ClientID, ClientSecret: cfg.OAuth.ClientSecret}, auth.NewTokenStore(path))
+}
+
+// authorizedClient returns an HTTP client acting as the logged in user and
+// the ID of that login, or nil when OAuth is not set up or nobody is logged
+// in
+func authorizedClient() (*http.Client, string, error) {
+	if cfg.OAuth.ClientID == "" {
+		return nil, "", nil
+	}
+	authenticator, err := newAuthenticator()
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := authenticator.HTTPClient(context.Background())
+	if errors.Is(err, auth.ErrNotLoggedIn) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	loginID, err := authenticator.LoginID()
+	if err != nil {
+		return nil, "", err
+	}
+	return client, loginID, nil
+}
+
+// newAudioService creates an audio service from the audio config, resolving
+// streams through the backend when it supports that
+func newAudioService(backend *services.Backend) *services.AudioService {
+	audio := services.NewAudioServiceWithOptions(services.AudioOptions{
+		SampleRate: cfg.Audio.SampleRate,
+		Channels:   cfg.Audio.Channels,
+		BufferSize: cfg.Audio.BufferSize,
+		Format:     cfg.Audio.Format,
+		YtDlpPath:  cfg.YtDlpPath,
+	})
+	if backend != nil && backend.Streams != nil {
+		audio.SetStreamResolver(backend.Streams)
+	}
+	return audio
+}
+
+// newLyricsProvider builds the chain of lyrics sources in the configured
+// order
+func newLyricsProvider() (lyrics.Provider, error) {
+	var chain lyrics.Chain
+	for _, source := range cfg.Lyrics.Sources {
+		switch strings.ToLower(strings.TrimSpace(source)) {
+		case "local":
+			dir := cfg.Lyrics.Dir
+			if dir == "" {
+				configDir, err := os.UserConfigDir()
+				if err != nil {
+					return nil, fmt.Errorf("could not determine config directory: %w", err)
+				}
+				dir = filepath.Join(configDir, appName, "lyrics")
+			}
+			chain = append(chain, lyrics.LocalProvider{Dir: dir})
+		case "subtitles":
+			chain = append(chain, lyrics.SubtitleProvider{YtDlpPath: cfg.YtDlpPath, Languages: cfg.Lyrics.Languages})
+		case "lrclib":
+			chain = append(chain, lyrics.LRCLIBProvider{BaseURL: cfg.Lyrics.LRCLIBURL})
+		default:
+			return nil, fmt.Errorf("invalid lyrics source %q (valid: local, subtitles, lrclib)", source)
+		}
+	}
+	return chain, nil
+}
+
+// socketPath returns the daemon socket from the config or the default one
+func socketPath() string {
+	if cfg.Daemon.Socket != "" {
+		return cfg.Daemon.Socket
+	}
+	return player.DefaultSocketPath()
+}
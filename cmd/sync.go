@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services"
+	"github.com/spf13/cobra"
+)
+
+// Sync directions
+const (
+	syncBoth = "both"
+	syncPush = "push"
+	syncPull = "pull"
+)
+
+var syncDirections = []string{syncBoth, syncPush, syncPull}
+
+var (
+	syncDirection string
+	syncPrefer    string
+	syncDryRun    bool
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync <file> <playlist>",
+	Short: "Sync a local playlist file with a YouTube playlist",
+	Long: `Sync a local playlist file with a YouTube playlist, comparing them by
+video ID.
+
+By default changes go both ways: videos added on either side since the
+last sync are added to the other, videos removed on either side are
+removed from the other, and the order of whichever side was reordered is
+kept. The first sync of a pair removes nothing. --direction push makes the
+playlist match the file and --direction pull makes the file match the
+playlist.
+
+The file is read like 'gplay playlist push' reads it and is written back
+as M3U, or as JSON when it ends in .json. Deleted and private videos on
+YouTube are reported with the conflicts and never copied to the file.
+Writing to YouTube needs 'gplay auth login'; every edit costs 50 quota
+units.
+
+Examples:
+  gplay sync road-trip.m3u PLxxxx --dry-run
+  gplay sync road-trip.m3u PLxxxx
+  gplay sync backup.json PLxxxx --direction pull`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSync,
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	playlistID, err := yt.ParsePlaylistID(args[1])
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(syncDirections, syncDirection) {
+		return fmt.Errorf("invalid direction %q (valid: %s)", syncDirection, strings.Join(syncDirections, ", "))
+	}
+	if syncPrefer != "local" && syncPrefer != "remote" {
+		return fmt.Errorf("invalid --prefer %q (valid: local, remote)", syncPrefer)
+	}
+
+	local, err := library.LoadPlaylist(path)
+	localExists := !errors.Is(err, os.ErrNotExist)
+	if err != nil && localExists {
+		return err
+	}
+
+	opts, err := backendOptions()
+	if err != nil {
+		return err
+	}
+	// Exclude rules would make dropped videos look removed from YouTube
+	opts.Exclude = nil
+	backend, err := services.NewBackend(opts, 0)
+	if err != nil {
+		return err
+	}
+	writesRemote := syncDirection != syncPull && !syncDryRun
+	if writesRemote && backend.Editor == nil {
+		return services.ErrNoLibrary
+	}
+	remote, err := remoteItems(cmd, backend, playlistID)
+	if err != nil {
+		return err
+	}
+
+	statePath, err := library.DefaultSyncStatePath()
+	if err != nil {
+		return err
+	}
+	states, err := library.LoadSyncStates(statePath)
+	if err != nil {
+		return err
+	}
+
+	var (
+		target    []yt.Video
+		conflicts []library.Conflict
+	)
+	switch syncDirection {
+	case syncPush:
+		target, conflicts = local, unavailableConflicts(remote)
+	case syncPull:
+		target, conflicts = availableVideos(remote, local), unavailableConflicts(remote)
+	default:
+		state, _ := states.Get(path, playlistID)
+		target, conflicts = library.Merge(state.VideoIDs, local, remote, syncPrefer == "remote")
+	}
+
+	var remoteChanges, localChanges []library.Change
+	if syncDirection != syncPull {
+		remoteChanges = library.Plan(target, remote)
+	}
+	if syncDirection != syncPush {
+		localChanges = library.Plan(target, localItems(local))
+	}
+
+	if syncDirection != syncPull {
+		printChanges("YouTube ("+playlistID+")", remoteChanges)
+	}
+	if syncDirection != syncPush {
+		printChanges("Local ("+path+")", localChanges)
+	}
+	if len(conflicts) > 0 {
+		fmt.Println("Conflicts:")
+		for _, c := range conflicts {
+			fmt.Println("  " + c.String())
+		}
+	}
+	if syncDryRun {
+		return nil
+	}
+
+	// The file is written before YouTube is touched. If the remote edits
+	// then fail part way, the file already holds the merge and the next
+	// sync redoes the missing edits, rather than YouTube running ahead of
+	// both the file and the base.
+	if len(localChanges) > 0 || (!localExists && syncDirection != syncPush) {
+		if err := library.SavePlaylist(path, target); err != nil {
+			return err
+		}
+	}
+	if len(remoteChanges) > 0 {
+		if err := services.ApplyChanges(cmd.Context(), backend.Editor, playlistID, remoteChanges, nil); err != nil {
+			return fmt.Errorf("failed to update playlist: %w", err)
+		}
+	}
+	return states.Set(path, playlistID, target)
+}
+
+// remoteItems lists the playlist with entry IDs when logged in, and
+// through the configured backend otherwise, which is enough to read it
+func remoteItems(cmd *cobra.Command, backend *services.Backend, playlistID string) ([]yt.PlaylistItem, error) {
+	if backend.Editor != nil {
+		return backend.Editor.Items(cmd.Context(), playlistID)
+	}
+
+	videos, err := backend.Playlist.GetPlaylistItemsContext(cmd.Context(), playlistID, services.MaxPlaylistPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist details: %w", err)
+	}
+	return localItems(videos), nil
+}
+
+// localItems numbers videos as the entries of a playlist
+func localItems(videos []yt.Video) []yt.PlaylistItem {
+	items := make([]yt.PlaylistItem, len(videos))
+	for i, v := range videos {
+		items[i] = yt.PlaylistItem{VideoID: v.ID, Position: i, Title: v.Title, Availability: v.Availability}
+	}
+	return items
+}
+
+// availableVideos is the playable part of a playlist, using what the
+// local file knows about each video
+func availableVideos(remote []yt.PlaylistItem, local []yt.Video) []yt.Video {
+	known := make(map[string]yt.Video, len(local))
+	for _, v := range local {
+		known[v.ID] = v
+	}
+	videos := make([]yt.Video, 0, len(remote))
+	for _, item := range remote {
+		if item.Availability != yt.Available {
+			continue
+		}
+		v, ok := known[item.VideoID]
+		if !ok {
+			v = yt.Video{ID: item.VideoID, URL: yt.VideoURL(item.VideoID), Title: item.Title}
+		}
+		videos = append(videos, v)
+	}
+	return videos
+}
+
+func unavailableConflicts(remote []yt.PlaylistItem) []library.Conflict {
+	var conflicts []library.Conflict
+	for _, item := range remote {
+		if item.Availability != yt.Available {
+			conflicts = append(conflicts, library.Conflict{VideoID: item.VideoID, Title: item.Title, Reason: fmt.Sprintf("%s on YouTube", item.Availability)})
+		}
+	}
+	return conflicts
+}
+
+func printChanges(side string, changes []library.Change) {
+	fmt.Printf("%s: %s\n", side, library.Summary(changes))
+	for _, change := range changes {
+		fmt.Println("  " + change.String())
+	}
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncDirection, "direction", syncBoth, "Which side to change ("+strings.Join(syncDirections, ", ")+")")
+	syncCmd.Flags().StringVar(&syncPrefer, "prefer", "local", "Whose order wins when both sides were reordered (local, remote)")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Only print the changes and conflicts")
+
+	rootCmd.AddCommand(syncCmd)
+}
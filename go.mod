@@ -9,7 +9,9 @@ require (
 	github.com/hajimehoshi/oto/v2 v2.4.3
 	github.com/joho/godotenv v1.5.1
 	github.com/spf13/cobra v1.10.1
+	golang.org/x/oauth2 v0.30.0
 	google.golang.org/api v0.248.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -49,7 +51,6 @@ require (
 	go.opentelemetry.io/otel/trace v1.36.0 // indirect
 	golang.org/x/crypto v0.41.0 // indirect
 	golang.org/x/net v0.43.0 // indirect
-	golang.org/x/oauth2 v0.30.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250818200422-3122310a409c // indirect
@@ -0,0 +1,293 @@
+// Package auth signs gplay in to the user's YouTube account with OAuth 2.0
+// so private playlists and likes can be read and playlists edited
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Login flows
+const (
+	// FlowDevice shows a code to enter at google.com/device, which also
+	// works over SSH. It needs a "TVs and Limited Input devices" client.
+	FlowDevice = "device"
+	// FlowLoopback opens the consent page in a browser that redirects to
+	// a local server. It needs a "Desktop app" client.
+	FlowLoopback = "loopback"
+)
+
+// Flows lists the valid login flows
+var Flows = []string{FlowDevice, FlowLoopback}
+
+// Scope grants access to the user's YouTube account; reading likes alone
+// would only need youtube.readonly but adding to playlists needs more
+const Scope = "https://www.googleapis.com/auth/youtube"
+
+// GoogleEndpoint is Google's OAuth 2.0 endpoint
+var GoogleEndpoint = oauth2.Endpoint{
+	AuthURL:       "https://accounts.google.com/o/oauth2/auth",
+	DeviceAuthURL: "https://oauth2.googleapis.com/device/code",
+	TokenURL:      "https://oauth2.googleapis.com/token",
+	AuthStyle:     oauth2.AuthStyleInParams,
+}
+
+const (
+	googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+	// loginTimeout bounds how long a login waits for the user
+	loginTimeout = 5 * time.Minute
+)
+
+// Options configures the OAuth client
+type Options struct {
+	ClientID     string
+	ClientSecret string
+	// Endpoint and RevokeURL default to Google's
+	Endpoint  oauth2.Endpoint
+	RevokeURL string
+}
+
+// Authenticator logs in and out and hands out authorized HTTP clients
+type Authenticator struct {
+	config    *oauth2.Config
+	store     *TokenStore
+	revokeURL string
+}
+
+// New creates an authenticator storing its token in store
+func New(opts Options, store *TokenStore) (*Authenticator, error) {
+	if opts.ClientID == "" {
+		return nil, fmt.Errorf("missing OAuth client ID: create an OAuth client in the Google Cloud console as described in 'gplay auth --help' and set oauth.client_id and oauth.client_secret")
+	}
+	if opts.Endpoint.TokenURL == "" {
+		opts.Endpoint = GoogleEndpoint
+	}
+	if opts.RevokeURL == "" {
+		opts.RevokeURL = googleRevokeURL
+	}
+
+	return &Authenticator{
+		config: &oauth2.Config{
+			ClientID:     opts.ClientID,
+			ClientSecret: opts.ClientSecret,
+			Endpoint:     opts.Endpoint,
+			Scopes:       []string{Scope},
+		},
+		store:     store,
+		revokeURL: opts.RevokeURL,
+	}, nil
+}
+
+// Store returns where the token is kept
+func (a *Authenticator) Store() *TokenStore {
+	return a.store
+}
+
+// Login runs the given flow, writing instructions for the user to out, and
+// stores the token it yields
+func (a *Authenticator) Login(ctx context.Context, flow string, out io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	var token *oauth2.Token
+	var err error
+	switch flow {
+	case FlowDevice, "":
+		token, err = a.loginDevice(ctx, out)
+	case FlowLoopback:
+		token, err = a.loginLoopback(ctx, out)
+	default:
+		return fmt.Errorf("unknown login flow %q (valid: %s)", flow, strings.Join(Flows, ", "))
+	}
+	if err != nil {
+		return err
+	}
+	return a.store.Save(token)
+}
+
+func (a *Authenticator) loginDevice(ctx context.Context, out io.Writer) (*oauth2.Token, error) {
+	device, err := a.config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting device login: %w", err)
+	}
+
+	fmt.Fprintf(out, "Go to %s and enter the code %s\n", device.VerificationURI, device.UserCode)
+	fmt.Fprintln(out, "Waiting for approval...")
+
+	token, err := a.config.DeviceAccessToken(ctx, device)
+	if err != nil {
+		return nil, fmt.Errorf("error completing device login: %w", err)
+	}
+	return token, nil
+}
+
+// loginLoopback serves the redirect on a random local port. PKCE keeps the
+// code useless to anything else that might see it.
+func (a *Authenticator) loginLoopback(ctx context.Context, out io.Writer) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("error starting login server: %w", err)
+	}
+	defer listener.Close()
+
+	config := *a.config
+	config.RedirectURL = "http://" + listener.Addr().String() + "/callback"
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+			query := r.URL.Query()
+			var res result
+			switch {
+			case query.Get("state") != state:
+				res.err = errors.New("login response has the wrong state")
+			case query.Get("error") != "":
+				res.err = fmt.Errorf("login refused: %s", query.Get("error"))
+			default:
+				res.code = query.Get("code")
+			}
+
+			if res.err != nil {
+				http.Error(w, res.err.Error(), http.StatusBadRequest)
+			} else {
+				fmt.Fprintln(w, "gplay is logged in. You can close this window.")
+			}
+			select {
+			case results <- res:
+			default:
+			}
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authURL := config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier))
+	fmt.Fprintf(out, "Open this URL in your browser to log in:\n\n  %s\n\n", authURL)
+	fmt.Fprintln(out, "Waiting for approval...")
+
+	var res result
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("login timed out: %w", ctx.Err())
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	token, err := config.Exchange(ctx, res.code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging login code: %w", err)
+	}
+	return token, nil
+}
+
+// Logout revokes the stored token with Google, best effort, and deletes it
+func (a *Authenticator) Logout(ctx context.Context) error {
+	token, err := a.store.Load()
+	if errors.Is(err, ErrNotLoggedIn) {
+		return nil
+	}
+	if err == nil {
+		revoke := token.RefreshToken
+		if revoke == "" {
+			revoke = token.AccessToken
+		}
+		if err := a.revoke(ctx, revoke); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	return a.store.Delete()
+}
+
+func (a *Authenticator) revoke(ctx context.Context, token string) error {
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.revokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error revoking token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error revoking token: %s", resp.Status)
+	}
+	return nil
+}
+
+// Token returns the stored token without refreshing it
+func (a *Authenticator) Token() (*oauth2.Token, error) {
+	return a.store.Load()
+}
+
+// LoginID returns an identifier of the stored login that reveals nothing
+// about the token. It stays the same across token refreshes and changes
+// with every new login.
+func (a *Authenticator) LoginID() (string, error) {
+	token, err := a.store.Load()
+	if err != nil {
+		return "", err
+	}
+	secret := token.RefreshToken
+	if secret == "" {
+		secret = token.AccessToken
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// HTTPClient returns a client that authorizes every request as the logged
+// in user, refreshing and storing the token as it expires
+func (a *Authenticator) HTTPClient(ctx context.Context) (*http.Client, error) {
+	token, err := a.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	source := &savingTokenSource{
+		source: a.config.TokenSource(ctx, token),
+		store:  a.store,
+		last:   token.AccessToken,
+	}
+	return oauth2.NewClient(ctx, oauth2.ReuseTokenSource(token, source)), nil
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating login state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,347 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// oauthServer is a fake OAuth 2.0 provider with device, code, refresh and
+// revoke endpoints
+type oauthServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	revoked []string
+}
+
+func newOAuthServer(t *testing.T) *oauthServer {
+	s := &oauthServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device/code", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"device_code":      "device-123",
+			"user_code":        "ABCD-EFGH",
+			"verification_url": "https://example.com/device",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.PostForm.Get("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			if r.PostForm.Get("device_code") != "device-123" {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, map[string]any{"access_token": "device-access", "refresh_token": "device-refresh", "token_type": "Bearer", "expires_in": 3600})
+		case "authorization_code":
+			if r.PostForm.Get("code") != "code-123" || r.PostForm.Get("code_verifier") == "" {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, map[string]any{"access_token": "code-access", "refresh_token": "code-refresh", "token_type": "Bearer", "expires_in": 3600})
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != "stored-refresh" {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, map[string]any{"access_token": "refreshed-access", "token_type": "Bearer", "expires_in": 3600})
+		default:
+			http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		}
+	})
+	mux.HandleFunc("/revoke", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		s.mu.Lock()
+		s.revoked = append(s.revoked, r.PostForm.Get("token"))
+		s.mu.Unlock()
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *oauthServer) authenticator(t *testing.T) *Authenticator {
+	t.Helper()
+	a, err := New(Options{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       s.URL + "/auth",
+			DeviceAuthURL: s.URL + "/device/code",
+			TokenURL:      s.URL + "/token",
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+		RevokeURL: s.URL + "/revoke",
+	}, NewTokenStore(filepath.Join(t.TempDir(), tokenFileName)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestNewRequiresClientID(t *testing.T) {
+	if _, err := New(Options{}, NewTokenStore("token.json")); err == nil {
+		t.Error("expected an error without a client ID")
+	}
+}
+
+func TestLoginDevice(t *testing.T) {
+	srv := newOAuthServer(t)
+	a := srv.authenticator(t)
+
+	var out strings.Builder
+	if err := a.Login(context.Background(), FlowDevice, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "https://example.com/device") || !strings.Contains(out.String(), "ABCD-EFGH") {
+		t.Errorf("instructions = %q, want the URL and code", out.String())
+	}
+
+	token, err := a.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "device-access" || token.RefreshToken != "device-refresh" {
+		t.Errorf("stored token = %+v", token)
+	}
+}
+
+func TestLoginLoopback(t *testing.T) {
+	srv := newOAuthServer(t)
+	a := srv.authenticator(t)
+
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Login(context.Background(), FlowLoopback, writer)
+		writer.Close()
+	}()
+
+	authURL := readAuthURL(t, reader)
+	go io.Copy(io.Discard, reader)
+	query := authURL.Query()
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("auth URL has no PKCE challenge: %s", authURL)
+	}
+	if query.Get("access_type") != "offline" || query.Get("scope") != Scope {
+		t.Errorf("auth URL = %s", authURL)
+	}
+
+	// The browser comes back with the code and the state it was given
+	callback := query.Get("redirect_uri") + "?" + url.Values{"code": {"code-123"}, "state": {query.Get("state")}}.Encode()
+	resp, err := http.Get(callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("callback status = %s", resp.Status)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	token, err := a.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "code-access" {
+		t.Errorf("stored token = %+v", token)
+	}
+}
+
+func TestLoginLoopbackWrongState(t *testing.T) {
+	srv := newOAuthServer(t)
+	a := srv.authenticator(t)
+
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Login(context.Background(), FlowLoopback, writer)
+		writer.Close()
+	}()
+
+	authURL := readAuthURL(t, reader)
+	go io.Copy(io.Discard, reader)
+	callback := authURL.Query().Get("redirect_uri") + "?code=code-123&state=forged"
+	resp, err := http.Get(callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("callback status = %s, want 400", resp.Status)
+	}
+
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "wrong state") {
+		t.Errorf("error = %v, want a wrong state error", err)
+	}
+	if _, err := a.Token(); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("a token was stored after a failed login: %v", err)
+	}
+}
+
+func TestLoginUnknownFlow(t *testing.T) {
+	a := newOAuthServer(t).authenticator(t)
+	if err := a.Login(context.Background(), "carrier-pigeon", io.Discard); err == nil {
+		t.Error("expected an error for an unknown flow")
+	}
+}
+
+// readAuthURL returns the consent URL Login prints
+func readAuthURL(t *testing.T, r io.Reader) *url.URL {
+	t.Helper()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "http") {
+			u, err := url.Parse(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return u
+		}
+	}
+	t.Fatal("Login printed no URL")
+	return nil
+}
+
+func TestHTTPClientRefreshesAndSavesToken(t *testing.T) {
+	srv := newOAuthServer(t)
+	a := srv.authenticator(t)
+	expired := &oauth2.Token{AccessToken: "stale-access", RefreshToken: "stored-refresh", Expiry: time.Now().Add(-time.Hour)}
+	if err := a.Store().Save(expired); err != nil {
+		t.Fatal(err)
+	}
+	loginID, err := a.LoginID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var authorization string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer api.Close()
+
+	client, err := a.HTTPClient(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if authorization != "Bearer refreshed-access" {
+		t.Errorf("Authorization = %q, want the refreshed token", authorization)
+	}
+
+	stored, err := a.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.AccessToken != "refreshed-access" || stored.RefreshToken != "stored-refresh" {
+		t.Errorf("stored token after refresh = %+v", stored)
+	}
+	if id, err := a.LoginID(); err != nil || id != loginID {
+		t.Errorf("LoginID changed with a refresh: %q, %v; was %q", id, err, loginID)
+	}
+}
+
+func TestHTTPClientNotLoggedIn(t *testing.T) {
+	a := newOAuthServer(t).authenticator(t)
+	if _, err := a.HTTPClient(context.Background()); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("error = %v, want ErrNotLoggedIn", err)
+	}
+	if _, err := a.LoginID(); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("LoginID error = %v, want ErrNotLoggedIn", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	srv := newOAuthServer(t)
+	a := srv.authenticator(t)
+	if err := a.Store().Save(&oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Logout(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.revoked) != 1 || srv.revoked[0] != "refresh" {
+		t.Errorf("revoked = %v, want the refresh token", srv.revoked)
+	}
+	if _, err := a.Token(); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("token still stored after logout: %v", err)
+	}
+
+	// Logging out twice is fine
+	if err := a.Logout(context.Background()); err != nil {
+		t.Errorf("second logout: %v", err)
+	}
+}
+
+func TestTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", tokenFileName)
+	store := NewTokenStore(path)
+
+	if _, err := store.Load(); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("Load without a token = %v, want ErrNotLoggedIn", err)
+	}
+
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := store.Save(&oauth2.Token{AccessToken: "a", RefreshToken: "r", Expiry: expiry}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("token mode = %#o, want 0600", perm)
+	}
+
+	token, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "a" || token.RefreshToken != "r" || !token.Expiry.Equal(expiry) {
+		t.Errorf("loaded token = %+v", token)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err == nil || errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("Load of a corrupt token = %v, want a parse error", err)
+	}
+
+	if err := store.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(); err != nil {
+		t.Errorf("deleting a missing token: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	appDirName    = "gplay"
+	tokenFileName = "token.json"
+)
+
+// ErrNotLoggedIn is returned when no OAuth token has been stored
+var ErrNotLoggedIn = errors.New("not logged in to YouTube")
+
+// DefaultTokenPath returns the token file location next to the config file
+func DefaultTokenPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine config directory: %w", err)
+	}
+	return filepath.Join(dir, appDirName, tokenFileName), nil
+}
+
+// TokenStore keeps the OAuth token on disk, readable by the user only
+type TokenStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewTokenStore creates a store for the token file at path
+func NewTokenStore(path string) *TokenStore {
+	return &TokenStore{path: path}
+}
+
+// Path returns the token file location
+func (s *TokenStore) Path() string {
+	return s.path
+}
+
+// Load reads the stored token, failing with ErrNotLoggedIn when there is
+// none
+func (s *TokenStore) Load() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotLoggedIn
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading token: %w", err)
+	}
+
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("error parsing token %s: %w", s.path, err)
+	}
+	return token, nil
+}
+
+// Save writes the token, creating the parent directory if needed
+func (s *TokenStore) Save(token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding token: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("error creating token directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("error writing token: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the stored token. A missing token is not an error.
+func (s *TokenStore) Delete() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing token: %w", err)
+	}
+	return nil
+}
+
+// savingTokenSource stores every refreshed token so the next run starts
+// with a valid access token
+type savingTokenSource struct {
+	mu     sync.Mutex
+	source oauth2.TokenSource
+	store  *TokenStore
+	last   string
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.AccessToken != s.last {
+		s.last = token.AccessToken
+		if err := s.store.Save(token); err != nil {
+			// The token still works for this run
+			log.Printf("Warning: %v", err)
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,541 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	appDirName     = "gplay"
+	configFileName = "config.yaml"
+
+	envAPIKey     = "GOOGLE_API_KEY"
+	envPrefix     = "GPLAY_"
+	envConfigPath = "GPLAY_CONFIG"
+)
+
+// Config holds every user configurable setting. Values are resolved in the
+// order defaults < config file < environment < command line flags; the
+// last step is handled by the commands themselves.
+type Config struct {
+	APIKey          string        `yaml:"api_key"`
+	Backend         string        `yaml:"backend"`    // auto, api, ytdlp, invidious, piped
+	YtDlpPath       string        `yaml:"ytdlp_path"` // yt-dlp binary
+	Instances       []string      `yaml:"instances"`  // Invidious/Piped base URLs
+	DefaultPlaylist string        `yaml:"default_playlist"`
+	Search          SearchConfig  `yaml:"search"`
+	Exclude         ExcludeConfig `yaml:"exclude"`
+	Quota           QuotaConfig   `yaml:"quota"`
+	Cache           CacheConfig   `yaml:"cache"`
+	API             APIConfig     `yaml:"api"`
+	OAuth           OAuthConfig   `yaml:"oauth"`
+	Audio           AudioConfig   `yaml:"audio"`
+	Radio           RadioConfig   `yaml:"radio"`
+	Lyrics          LyricsConfig  `yaml:"lyrics"`
+	Daemon          DaemonConfig  `yaml:"daemon"`
+	Theme           ThemeConfig   `yaml:"theme"`
+	Keys            KeyConfig     `yaml:"keys"`
+}
+
+// SearchConfig holds the defaults used for YouTube searches
+type SearchConfig struct {
+	MaxResults    int64  `yaml:"max_results"`
+	Order         string `yaml:"order"`
+	SafeSearch    string `yaml:"safe_search"`
+	VideoDuration string `yaml:"video_duration"`
+	VideoType     string `yaml:"video_type"`
+	// Region and language bias results; empty leaves it to YouTube
+	RegionCode        string `yaml:"region_code"`
+	RelevanceLanguage string `yaml:"relevance_language"`
+}
+
+// ExcludeConfig holds the rules that drop unwanted videos from search
+// results and radio
+type ExcludeConfig struct {
+	Shorts bool `yaml:"shorts"`
+	// ShortMaxLength also drops unmarked videos up to this long as Shorts,
+	// short songs included; 0 disables
+	ShortMaxLength time.Duration `yaml:"short_max_length"`
+	Live           bool          `yaml:"live"`         // live and upcoming streams
+	MinDuration    time.Duration `yaml:"min_duration"` // 0 disables
+	MaxDuration    time.Duration `yaml:"max_duration"` // 0 disables
+	// TitlePatterns are regular expressions matched case-insensitively
+	TitlePatterns []string `yaml:"title_patterns"`
+	// Categories keeps only videos in these categories, by name or ID
+	Categories []string `yaml:"categories"`
+	// Lists also applies the rules to playlists and channel uploads
+	Lists bool `yaml:"lists"`
+}
+
+// QuotaConfig holds the daily YouTube Data API budget
+type QuotaConfig struct {
+	Budget      int64 `yaml:"budget"`       // units per day, 0 disables the limit
+	WarnPercent int   `yaml:"warn_percent"` // warn once usage reaches this share
+}
+
+// CacheConfig controls the on-disk cache of Data API responses
+type CacheConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	SearchTTL   time.Duration `yaml:"search_ttl"`
+	PlaylistTTL time.Duration `yaml:"playlist_ttl"`
+	VideoTTL    time.Duration `yaml:"video_ttl"`
+}
+
+// APIConfig tunes how the YouTube Data API is called
+type APIConfig struct {
+	MaxRetries    int             `yaml:"max_retries"` // retries of failed calls, 0 disables
+	RetryDelay    time.Duration   `yaml:"retry_delay"` // first backoff, doubled per retry
+	MaxRetryDelay time.Duration   `yaml:"max_retry_delay"`
+	RateLimit     RateLimitConfig `yaml:"rate_limit"`
+}
+
+// RateLimitConfig holds requests per second per endpoint, 0 for no limit
+type RateLimitConfig struct {
+	Search        float64 `yaml:"search"`
+	Videos        float64 `yaml:"videos"`
+	PlaylistItems float64 `yaml:"playlist_items"`
+}
+
+// OAuthConfig holds the OAuth client used to log in to a YouTube account.
+// The device flow needs a "TVs and Limited Input devices" client from the
+// Google Cloud console, the loopback flow a "Desktop app" one.
+type OAuthConfig struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	Flow         string `yaml:"flow"` // device or loopback
+}
+
+// AudioConfig holds playback settings
+type AudioConfig struct {
+	SampleRate int    `yaml:"sample_rate"`
+	Channels   int    `yaml:"channels"`
+	BufferSize string `yaml:"buffer_size"`
+	Format     string `yaml:"format"` // yt-dlp format selector
+}
+
+// RadioConfig controls how the TUI keeps playing once a list runs out
+type RadioConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Strategy string `yaml:"strategy"` // mix or search
+	// Remaining is how few unplayed tracks trigger fetching more
+	Remaining int `yaml:"remaining"`
+	// AvoidRecent skips tracks among this many most recent plays
+	AvoidRecent int `yaml:"avoid_recent"`
+	Batch       int `yaml:"batch"` // tracks added per fetch
+}
+
+// LyricsConfig controls where the TUI lyrics pane looks for lyrics
+type LyricsConfig struct {
+	// Sources are tried in order: local, subtitles, lrclib
+	Sources []string `yaml:"sources"`
+	// Dir holds .lrc files; empty uses the lyrics directory next to the
+	// config file
+	Dir       string   `yaml:"dir"`
+	LRCLIBURL string   `yaml:"lrclib_url"`
+	Languages []string `yaml:"languages"` // caption languages, yt-dlp patterns
+}
+
+// DaemonConfig holds the settings of 'gplay daemon' and its clients
+type DaemonConfig struct {
+	// Socket is the control socket; empty uses the user's runtime
+	// directory. Its directory must be private to the user (mode 0700).
+	Socket string `yaml:"socket"`
+}
+
+// ThemeConfig holds the TUI colors as hex strings
+type ThemeConfig struct {
+	Primary   string `yaml:"primary"`
+	Secondary string `yaml:"secondary"`
+	Text      string `yaml:"text"`
+	Muted     string `yaml:"muted"`
+	Border    string `yaml:"border"`
+	Error     string `yaml:"error"`
+	Warning   string `yaml:"warning"`
+	Success   string `yaml:"success"`
+	Paused    string `yaml:"paused"`
+	Help      string `yaml:"help"`
+}
+
+// KeyConfig maps TUI actions to the keys that trigger them
+type KeyConfig struct {
+	Quit    []string `yaml:"quit"`
+	Search  []string `yaml:"search"`
+	Up      []string `yaml:"up"`
+	Down    []string `yaml:"down"`
+	Play    []string `yaml:"play"`
+	Pause   []string `yaml:"pause"`
+	Stop    []string `yaml:"stop"`
+	Doctor  []string `yaml:"doctor"`
+	APIKey  []string `yaml:"api_key"`
+	History []string `yaml:"history"`
+	Channel []string `yaml:"channel"`
+	Filters []string `yaml:"filters"`
+	Command []string `yaml:"command"`
+	Radio   []string `yaml:"radio"`
+	// The logged in user's library, see 'gplay auth'
+	MyPlaylists   []string `yaml:"my_playlists"`
+	Liked         []string `yaml:"liked"`
+	AddToPlaylist []string `yaml:"add_to_playlist"`
+	// RemoveFromPlaylist works while one of the user's playlists is open
+	RemoveFromPlaylist []string `yaml:"remove_from_playlist"`
+	// Lyrics toggles the lyrics pane; earlier and later shift its timing
+	Lyrics        []string `yaml:"lyrics"`
+	LyricsEarlier []string `yaml:"lyrics_earlier"`
+	LyricsLater   []string `yaml:"lyrics_later"`
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		Backend:   "auto",
+		YtDlpPath: "yt-dlp",
+		Search: SearchConfig{
+			MaxResults:    5,
+			Order:         "relevance",
+			SafeSearch:    "moderate",
+			VideoDuration: "any",
+			VideoType:     "any",
+		},
+		Exclude: ExcludeConfig{
+			Shorts:        true,
+			TitlePatterns: []string{`\b\d+ ?hours? (loop|version)\b`},
+		},
+		Quota: QuotaConfig{
+			Budget:      10000,
+			WarnPercent: 80,
+		},
+		Cache: CacheConfig{
+			Enabled:     true,
+			SearchTTL:   15 * time.Minute,
+			PlaylistTTL: 6 * time.Hour,
+			VideoTTL:    7 * 24 * time.Hour,
+		},
+		API: APIConfig{
+			MaxRetries:    3,
+			RetryDelay:    500 * time.Millisecond,
+			MaxRetryDelay: 10 * time.Second,
+			RateLimit: RateLimitConfig{
+				Search:        2,
+				Videos:        10,
+				PlaylistItems: 10,
+			},
+		},
+		OAuth: OAuthConfig{
+			Flow: "device",
+		},
+		Audio: AudioConfig{
+			SampleRate: 48000,
+			Channels:   2,
+			BufferSize: "64k",
+			Format:     "bestaudio[ext=m4a]/bestaudio[ext=webm]/bestaudio",
+		},
+		Lyrics: LyricsConfig{
+			Sources:   []string{"local", "subtitles", "lrclib"},
+			LRCLIBURL: "https://lrclib.net",
+			Languages: []string{"en.*"},
+		},
+		Radio: RadioConfig{
+			Strategy:    "mix",
+			Remaining:   2,
+			AvoidRecent: 50,
+			Batch:       10,
+		},
+		Theme: ThemeConfig{
+			Primary:   "#00D9FF",
+			Secondary: "#BD93F9",
+			Text:      "#F8F8F2",
+			Muted:     "#6272A4",
+			Border:    "#3C3C3C",
+			Error:     "#FF5555",
+			Warning:   "#FFB86C",
+			Success:   "#50FA7B",
+			Paused:    "#F1FA8C",
+			Help:      "#626262",
+		},
+		Keys: KeyConfig{
+			Quit:    []string{"q", "ctrl+c"},
+			Search:  []string{"/"},
+			Up:      []string{"up", "k"},
+			Down:    []string{"down", "j"},
+			Play:    []string{"enter"},
+			Pause:   []string{" "},
+			Stop:    []string{"x"},
+			Doctor:  []string{"D"},
+			APIKey:  []string{"K"},
+			History: []string{"H"},
+			Channel: []string{"c"},
+			Filters: []string{"f"},
+			Command: []string{":"},
+			Radio:   []string{"R"},
+
+			MyPlaylists:   []string{"M"},
+			Liked:         []string{"L"},
+			AddToPlaylist: []string{"a"},
+
+			RemoveFromPlaylist: []string{"X"},
+
+			Lyrics:        []string{"l"},
+			LyricsEarlier: []string{"["},
+			LyricsLater:   []string{"]"},
+		},
+	}
+}
+
+// DefaultPath returns the config file location under the XDG config
+// directory. GPLAY_CONFIG overrides it.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(envConfigPath); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine config directory: %w", err)
+	}
+	return filepath.Join(dir, appDirName, configFileName), nil
+}
+
+// Load reads the config file at path on top of the defaults and then
+// applies environment overrides. A missing file is not an error.
+func Load(path string) (*Config, error) {
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadFile reads the config file at path on top of the defaults without
+// looking at the environment
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	if err := decode(data, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SetFile stores value under a dotted key in the config file at path,
+// creating the file and its directory if needed. Only what the file already
+// holds and the edited key are written, so settings left at their defaults
+// keep following the defaults.
+func SetFile(path, key, value string) error {
+	// Check the key and value against the full config first
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set(key, value); err != nil {
+		return err
+	}
+	tree, err := cfg.tree()
+	if err != nil {
+		return err
+	}
+	parsed, _ := lookup(tree, key)
+
+	file := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	if file == nil {
+		file = map[string]any{}
+	}
+
+	parts := strings.Split(key, ".")
+	parent := file
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := parent[part].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			parent[part] = child
+		}
+		parent = child
+	}
+	parent[parts[len(parts)-1]] = parsed
+
+	if data, err = yaml.Marshal(file); err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+	// The file may hold an API key
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
+
+// Keys returns every settable key in dotted form, e.g. "search.order"
+func Keys() []string {
+	tree, _ := Default().tree()
+	var keys []string
+	collectKeys(tree, "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the value stored under a dotted key
+func (c *Config) Get(key string) (string, error) {
+	tree, err := c.tree()
+	if err != nil {
+		return "", err
+	}
+
+	node, ok := lookup(tree, key)
+	if !ok {
+		return "", fmt.Errorf("unknown config key %q", key)
+	}
+
+	switch v := node.(type) {
+	case string:
+		return v, nil
+	case map[string]any:
+		return "", fmt.Errorf("%q is a section, not a value", key)
+	default:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+}
+
+// Set stores value under a dotted key. The value is parsed as YAML so lists
+// can be given as "[q, ctrl+c]".
+func (c *Config) Set(key, value string) error {
+	tree, err := c.tree()
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(key, ".")
+	parent := tree
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := parent[part].(map[string]any)
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		parent = child
+	}
+
+	last := parts[len(parts)-1]
+	current, ok := parent[last]
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	if _, isSection := current.(map[string]any); isSection {
+		return fmt.Errorf("%q is a section, not a value", key)
+	}
+
+	var parsed any
+	if _, isString := current.(string); isString {
+		// Keep strings verbatim so values like "#FF5555" or " " survive
+		parsed = value
+	} else if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	parent[last] = parsed
+
+	data, err := yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	updated := &Config{}
+	if err := decode(data, updated); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	*c = *updated
+	return nil
+}
+
+// applyEnv overrides settings from the environment. GOOGLE_API_KEY sets the
+// API key and GPLAY_<SECTION>_<KEY> sets any other key.
+func (c *Config) applyEnv() error {
+	if apiKey := os.Getenv(envAPIKey); apiKey != "" {
+		c.APIKey = apiKey
+	}
+
+	for _, key := range Keys() {
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if value, ok := os.LookupEnv(name); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("error applying %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// tree converts the config into nested maps keyed by YAML field name
+func (c *Config) tree() (map[string]any, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	tree := map[string]any{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// lookup returns the value under a dotted key in tree
+func lookup(tree map[string]any, key string) (any, bool) {
+	var node any = tree
+	for _, part := range strings.Split(key, ".") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+func decode(data []byte, cfg *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func collectKeys(tree map[string]any, prefix string, keys *[]string) {
+	for name, value := range tree {
+		key := prefix + name
+		if child, ok := value.(map[string]any); ok {
+			collectKeys(child, key+".", keys)
+			continue
+		}
+		*keys = append(*keys, key)
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gplay", "config.yaml")
+
+	// A new file holds only the edited key
+	if err := SetFile(path, "search.order", "date"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); !equalYAML(t, got, "search:\n  order: date\n") {
+		t.Errorf("file after the first set:\n%s", got)
+	}
+
+	// Later sets keep what the file already holds
+	if err := SetFile(path, "keys.quit", "[q, ctrl+c]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFile(path, "exclude.min_duration", "1m"); err != nil {
+		t.Fatal(err)
+	}
+	want := "search:\n  order: date\nkeys:\n  quit: [q, ctrl+c]\nexclude:\n  min_duration: 1m0s\n"
+	if got := readFile(t, path); !equalYAML(t, got, want) {
+		t.Errorf("file after three sets:\n%s", got)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Search.Order != "date" || cfg.Search.MaxResults != Default().Search.MaxResults {
+		t.Errorf("loaded search config = %+v", cfg.Search)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+}
+
+func TestSetFileErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	tests := []struct{ key, value string }{
+		{"search.nope", "x"},
+		{"search", "x"},
+		{"search.max_results", "many"},
+		{"exclude.min_duration", "soon"},
+	}
+	for _, tt := range tests {
+		if err := SetFile(path, tt.key, tt.value); err == nil {
+			t.Errorf("SetFile(%q, %q) succeeded, want an error", tt.key, tt.value)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("a failed set wrote the file: %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// equalYAML reports whether two documents decode to the same tree
+func equalYAML(t *testing.T, a, b string) bool {
+	t.Helper()
+	var ta, tb any
+	if err := yaml.Unmarshal([]byte(a), &ta); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(b), &tb); err != nil {
+		t.Fatal(err)
+	}
+	outA, _ := yaml.Marshal(ta)
+	outB, _ := yaml.Marshal(tb)
+	return string(outA) == string(outB)
+}
@@ -0,0 +1,273 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/config"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services"
+)
+
+const (
+	// minYtDlpVersion is the oldest yt-dlp release known to handle current
+	// YouTube player changes. yt-dlp versions are dates, so they compare as
+	// strings.
+	minYtDlpVersion = "2023.11.16"
+
+	// minFFmpegMajor is the first FFmpeg release with the -reconnect_*
+	// options used for streaming
+	minFFmpegMajor = 4
+
+	commandTimeout = 10 * time.Second
+	apiTimeout     = 15 * time.Second
+)
+
+// Status is the outcome of a single check
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusWarn
+	StatusFail
+)
+
+// Result describes the outcome of a single check and how to fix it
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+// Options holds what the checks need from the caller
+type Options struct {
+	Config     *config.Config
+	ConfigPath string
+	// Audio is probed for a working output device. Oto only allows one
+	// audio context per process, so callers that already play audio must
+	// pass their own service.
+	Audio *services.AudioService
+}
+
+// Run executes every check in order
+func Run(opts Options) []Result {
+	return []Result{
+		checkYtDlp(opts.Config.YtDlpPath),
+		checkFFmpeg(),
+		checkAPIKey(opts.Config),
+		checkAudio(opts.Audio),
+		checkWritable("config directory", filepath.Dir(opts.ConfigPath)),
+		checkCacheDir(),
+	}
+}
+
+// Failed reports whether any result is a failure
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Report formats results as plain text, one check per line with fixes
+// indented below failures and warnings
+func Report(results []Result) string {
+	var b strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&b, "%s %-18s %s\n", r.Status.symbol(), r.Name, r.Detail)
+		if r.Status != StatusOK && r.Fix != "" {
+			fmt.Fprintf(&b, "  %-18s fix: %s\n", "", r.Fix)
+		}
+	}
+	return b.String()
+}
+
+func (s Status) symbol() string {
+	switch s {
+	case StatusOK:
+		return "✓"
+	case StatusWarn:
+		return "!"
+	default:
+		return "✗"
+	}
+}
+
+func checkYtDlp(binary string) Result {
+	result := Result{Name: "yt-dlp"}
+
+	if binary == "" {
+		binary = "yt-dlp"
+	}
+	out, err := runVersion(binary, "--version")
+	if err != nil {
+		result.Status = StatusFail
+		result.Detail = err.Error()
+		result.Fix = "install yt-dlp (https://github.com/yt-dlp/yt-dlp#installation) and make sure it is on PATH"
+		return result
+	}
+
+	version := strings.TrimSpace(out)
+	result.Detail = version
+	if version < minYtDlpVersion {
+		result.Status = StatusFail
+		result.Detail = fmt.Sprintf("%s is older than the minimum supported %s", version, minYtDlpVersion)
+		result.Fix = "run 'yt-dlp -U' or update it with your package manager"
+	}
+	return result
+}
+
+var ffmpegVersionPattern = regexp.MustCompile(`version n?(\d+)\.(\d+)`)
+
+func checkFFmpeg() Result {
+	result := Result{Name: "ffmpeg"}
+
+	out, err := runVersion("ffmpeg", "-version")
+	if err != nil {
+		result.Status = StatusFail
+		result.Detail = err.Error()
+		result.Fix = "install ffmpeg (e.g. 'apt install ffmpeg' or 'brew install ffmpeg')"
+		return result
+	}
+
+	firstLine, _, _ := strings.Cut(out, "\n")
+	match := ffmpegVersionPattern.FindStringSubmatch(firstLine)
+	if match == nil {
+		// Git builds report a commit hash instead of a release
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("could not parse version from %q", firstLine)
+		result.Fix = fmt.Sprintf("make sure ffmpeg %d.0 or newer is installed", minFFmpegMajor)
+		return result
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	result.Detail = match[1] + "." + match[2]
+	if major < minFFmpegMajor {
+		result.Status = StatusFail
+		result.Detail = fmt.Sprintf("%s is older than the minimum supported %d.0", result.Detail, minFFmpegMajor)
+		result.Fix = "upgrade ffmpeg with your package manager"
+	}
+	return result
+}
+
+func checkAPIKey(cfg *config.Config) Result {
+	result := Result{Name: "YouTube API key"}
+
+	if cfg.APIKey == "" {
+		result.Status = StatusFail
+		result.Detail = "no API key configured"
+		result.Fix = "export GOOGLE_API_KEY=... or run 'gplay config set api_key ...'"
+		if cfg.Backend != services.BackendAPI {
+			// Other backends work without a key; auto falls back to yt-dlp
+			backend := cfg.Backend
+			if backend == services.BackendAuto {
+				backend = services.BackendYtDlp
+			}
+			result.Status = StatusWarn
+			result.Detail += fmt.Sprintf(", using the %s backend", backend)
+		}
+		return result
+	}
+
+	client, err := yt.NewClientWithKey(cfg.APIKey)
+	if err != nil {
+		result.Status = StatusFail
+		result.Detail = err.Error()
+		return result
+	}
+
+	// videoCategories.list costs a single quota unit
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	_, err = client.Service().VideoCategories.List([]string{"id"}).RegionCode("US").Context(ctx).Do()
+	if err != nil {
+		result.Status = StatusFail
+		result.Detail = fmt.Sprintf("API call failed: %v", err)
+		result.Fix = "check the key in the Google Cloud console and that YouTube Data API v3 is enabled for it"
+		return result
+	}
+
+	result.Detail = "valid"
+	return result
+}
+
+func checkAudio(audio *services.AudioService) Result {
+	result := Result{Name: "audio device"}
+
+	if audio == nil {
+		audio = services.NewAudioService()
+	}
+	if err := audio.ProbeDevice(); err != nil {
+		result.Status = StatusFail
+		result.Detail = err.Error()
+		result.Fix = "check that an ALSA/PulseAudio/PipeWire output device is available to this user"
+		return result
+	}
+
+	result.Detail = "ok"
+	return result
+}
+
+func checkCacheDir() Result {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return Result{
+			Name:   "cache directory",
+			Status: StatusFail,
+			Detail: err.Error(),
+			Fix:    "set XDG_CACHE_HOME or HOME",
+		}
+	}
+	return checkWritable("cache directory", filepath.Join(dir, "gplay"))
+}
+
+// checkWritable creates dir if needed and writes a scratch file into it
+func checkWritable(name, dir string) Result {
+	result := Result{Name: name, Detail: dir}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		result.Status = StatusFail
+		result.Detail = err.Error()
+		result.Fix = fmt.Sprintf("make sure %s can be created by this user", dir)
+		return result
+	}
+
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		result.Status = StatusFail
+		result.Detail = err.Error()
+		result.Fix = fmt.Sprintf("fix the permissions of %s", dir)
+		return result
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	return result
+}
+
+// runVersion runs a version command and returns its combined output
+func runVersion(name string, args ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%s not found on PATH", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("running %s %s failed: %v", name, strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
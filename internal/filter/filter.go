@@ -0,0 +1,391 @@
+// Package filter implements the expression language used to narrow down
+// and order lists of videos on the client, e.g.
+//
+//	duration>3m and duration<8m and views>100k and channel~"lofi"
+package filter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// Fields lists the names an expression can compare
+var Fields = []string{"title", "channel", "description", "id", "duration", "views", "likes", "published"}
+
+// Expr is a parsed filter expression
+type Expr struct {
+	source string
+	root   node
+}
+
+type node interface {
+	match(v yt.Video) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ inner node }
+
+func (n andNode) match(v yt.Video) bool { return n.left.match(v) && n.right.match(v) }
+func (n orNode) match(v yt.Video) bool  { return n.left.match(v) || n.right.match(v) }
+func (n notNode) match(v yt.Video) bool { return !n.inner.match(v) }
+
+// textCmp compares a string field; = and != ignore case, ~ and !~ test
+// for a substring
+type textCmp struct {
+	field func(yt.Video) string
+	op    string
+	value string
+}
+
+func (c textCmp) match(v yt.Video) bool {
+	field := strings.ToLower(c.field(v))
+	switch c.op {
+	case "=":
+		return field == c.value
+	case "!=":
+		return field != c.value
+	case "~":
+		return strings.Contains(field, c.value)
+	case "!~":
+		return !strings.Contains(field, c.value)
+	}
+	return false
+}
+
+// numberCmp compares a numeric field: counts, nanoseconds or unix seconds
+type numberCmp struct {
+	field func(yt.Video) int64
+	op    string
+	value int64
+}
+
+func (c numberCmp) match(v yt.Video) bool {
+	field := c.field(v)
+	switch c.op {
+	case "=":
+		return field == c.value
+	case "!=":
+		return field != c.value
+	case "<":
+		return field < c.value
+	case "<=":
+		return field <= c.value
+	case ">":
+		return field > c.value
+	case ">=":
+		return field >= c.value
+	}
+	return false
+}
+
+// Parse compiles an expression. An empty expression matches everything.
+func Parse(expr string) (*Expr, error) {
+	p := &parser{src: expr}
+	p.skipSpace()
+	if p.done() {
+		return &Expr{source: expr}, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.done() {
+		return nil, p.errorf("expected and/or, got %q", p.rest())
+	}
+	return &Expr{source: expr, root: root}, nil
+}
+
+// String returns the expression as written
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Match reports whether v satisfies the expression
+func (e *Expr) Match(v yt.Video) bool {
+	return e.root == nil || e.root.match(v)
+}
+
+// Apply returns the videos matching the expression, in their original
+// order
+func (e *Expr) Apply(videos []yt.Video) []yt.Video {
+	if e.root == nil {
+		return videos
+	}
+	matched := make([]yt.Video, 0, len(videos))
+	for _, v := range videos {
+		if e.root.match(v) {
+			matched = append(matched, v)
+		}
+	}
+	return matched
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) done() bool   { return p.pos >= len(p.src) }
+func (p *parser) rest() string { return p.src[p.pos:] }
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("filter: %s at position %d", fmt.Sprintf(format, args...), p.pos+1)
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// keyword consumes one of words when it comes next as a whole word or
+// symbol
+func (p *parser) keyword(words ...string) bool {
+	p.skipSpace()
+	for _, w := range words {
+		if !strings.HasPrefix(strings.ToLower(p.rest()), w) {
+			continue
+		}
+		end := p.pos + len(w)
+		// Words must not run into the next identifier, symbols may
+		if unicode.IsLetter(rune(w[0])) && end < len(p.src) && isIdentChar(p.src[end]) {
+			continue
+		}
+		p.pos = end
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or", "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and", "&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.keyword("not", "!") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.keyword("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword(")") {
+			return nil, p.errorf("missing )")
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	p.skipSpace()
+	start := p.pos
+	for !p.done() && isIdentChar(p.src[p.pos]) {
+		p.pos++
+	}
+	field := strings.ToLower(p.src[start:p.pos])
+	if field == "" {
+		if p.done() {
+			return nil, p.errorf("expected a comparison")
+		}
+		return nil, p.errorf("expected a field name, got %q", p.rest())
+	}
+
+	p.skipSpace()
+	op := ""
+	for _, candidate := range []string{"!=", "!~", "<=", ">=", "=", "~", "<", ">"} {
+		if strings.HasPrefix(p.rest(), candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, p.errorf("expected an operator after %q", field)
+	}
+	p.pos += len(op)
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return p.compile(field, op, value)
+}
+
+// parseValue reads a quoted string or a bare word ending at whitespace or
+// an operator character, so "duration>3m&&views>1k" splits as expected.
+// Values containing those characters must be quoted.
+func (p *parser) parseValue() (string, error) {
+	p.skipSpace()
+	if p.done() {
+		return "", p.errorf("expected a value")
+	}
+	if p.src[p.pos] == '"' || p.src[p.pos] == '\'' {
+		quote := p.src[p.pos]
+		end := strings.IndexByte(p.src[p.pos+1:], quote)
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		value := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value, nil
+	}
+	start := p.pos
+	for !p.done() && !unicode.IsSpace(rune(p.src[p.pos])) && !strings.ContainsRune(operatorChars, rune(p.src[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a value")
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *parser) compile(field, op, value string) (node, error) {
+	isText := op == "~" || op == "!~"
+
+	switch field {
+	case "title", "channel", "description", "id":
+		if op != "=" && op != "!=" && !isText {
+			return nil, p.errorf("%s only supports =, !=, ~ and !~", field)
+		}
+		return textCmp{field: textFields[field], op: op, value: strings.ToLower(value)}, nil
+	}
+
+	if isText {
+		return nil, p.errorf("%s does not support %s", field, op)
+	}
+
+	switch field {
+	case "duration":
+		d, err := parseDuration(value)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		return numberCmp{field: func(v yt.Video) int64 { return int64(v.Length) }, op: op, value: int64(d)}, nil
+	case "views", "likes":
+		n, err := parseCount(value)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		return numberCmp{field: countFields[field], op: op, value: n}, nil
+	case "published":
+		t, err := yt.ParseTimeBound(value, time.Now())
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		return numberCmp{field: func(v yt.Video) int64 { return v.PublishedAt.Unix() }, op: op, value: t.Unix()}, nil
+	}
+	return nil, p.errorf("unknown field %q (valid: %s)", field, strings.Join(Fields, ", "))
+}
+
+var textFields = map[string]func(yt.Video) string{
+	"title":       func(v yt.Video) string { return v.Title },
+	"channel":     func(v yt.Video) string { return v.ChannelTitle },
+	"description": func(v yt.Video) string { return v.Description },
+	"id":          func(v yt.Video) string { return v.ID },
+}
+
+var countFields = map[string]func(yt.Video) int64{
+	"views": func(v yt.Video) int64 { return int64(v.ViewCount) },
+	"likes": func(v yt.Video) int64 { return int64(v.LikeCount) },
+}
+
+// parseDuration accepts Go durations such as "3m" or "1h30m", clock
+// times such as "4:13" and plain seconds
+func parseDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+
+	var total time.Duration
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid duration %q (use 3m, 1h30m or 4:13)", s)
+	}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q (use 3m, 1h30m or 4:13)", s)
+		}
+		total = total*60 + time.Duration(n)*time.Second
+	}
+	return total, nil
+}
+
+// parseCount accepts whole numbers with an optional k, m or b suffix
+func parseCount(s string) (int64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("invalid count %q (use 1500, 100k or 2.5m)", s)
+	}
+	multiplier := 1.0
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "k":
+		multiplier = 1e3
+	case "m":
+		multiplier = 1e6
+	case "b":
+		multiplier = 1e9
+	}
+	number := s
+	if multiplier > 1 {
+		number = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid count %q (use 1500, 100k or 2.5m)", s)
+	}
+	return int64(n * multiplier), nil
+}
+
+// operatorChars end a bare value
+const operatorChars = "()&|!=~<>"
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,348 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// Two fixed videos every expression is matched against
+var (
+	classic = yt.Video{
+		ID:           "dQw4w9WgXcQ",
+		Title:        "Never Gonna Give You Up",
+		ChannelTitle: "Rick Astley",
+		Description:  "The official video",
+		PublishedAt:  time.Date(2009, 10, 25, 0, 0, 0, 0, time.UTC),
+		ViewCount:    1_500_000_000,
+		LikeCount:    2_000_000,
+		Length:       3*time.Minute + 33*time.Second,
+	}
+	lofi = yt.Video{
+		ID:           "jfKfPfyJRdk",
+		Title:        "Lofi Hip Hop Radio",
+		ChannelTitle: "Lofi Girl",
+		Description:  "Beats to relax and study to",
+		PublishedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ViewCount:    1500,
+		LikeCount:    999,
+		Length:       4*time.Minute + 13*time.Second,
+	}
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expr          string
+		classic, lofi bool
+	}{
+		{expr: "", classic: true, lofi: true},
+
+		// Text fields: = and != ignore case, ~ and !~ find substrings
+		{expr: `title="never gonna give you up"`, classic: true, lofi: false},
+		{expr: `title='Lofi Hip Hop Radio'`, classic: false, lofi: true},
+		{expr: `title!="Lofi Hip Hop Radio"`, classic: true, lofi: false},
+		{expr: "title~gonna", classic: true, lofi: false},
+		{expr: "title!~gonna", classic: false, lofi: true},
+		{expr: `channel="rick astley"`, classic: true, lofi: false},
+		{expr: `channel!="rick astley"`, classic: false, lofi: true},
+		{expr: "channel~LOFI", classic: false, lofi: true},
+		{expr: "channel!~lofi", classic: true, lofi: false},
+		{expr: "description=beats", classic: false, lofi: false},
+		{expr: `description="the official video"`, classic: true, lofi: false},
+		{expr: "description!=beats", classic: true, lofi: true},
+		{expr: "description~study", classic: false, lofi: true},
+		{expr: "description!~study", classic: true, lofi: false},
+		{expr: "id=dQw4w9WgXcQ", classic: true, lofi: false},
+		{expr: "id!=dQw4w9WgXcQ", classic: false, lofi: true},
+		{expr: "id~PfyJ", classic: false, lofi: true},
+		{expr: "id!~PfyJ", classic: true, lofi: false},
+
+		// Durations as Go durations, clock times and seconds
+		{expr: "duration=4:13", classic: false, lofi: true},
+		{expr: "duration!=4:13", classic: true, lofi: false},
+		{expr: "duration<4m", classic: true, lofi: false},
+		{expr: "duration<=213", classic: true, lofi: false},
+		{expr: "duration>3m", classic: true, lofi: true},
+		{expr: "duration>=4m13s", classic: false, lofi: true},
+		{expr: "duration<0:04:00", classic: true, lofi: false},
+
+		// Counts with k, m and b suffixes
+		{expr: "views=1.5k", classic: false, lofi: true},
+		{expr: "views!=1500", classic: true, lofi: false},
+		{expr: "views<2m", classic: false, lofi: true},
+		{expr: "views<=1500", classic: false, lofi: true},
+		{expr: "views>1b", classic: true, lofi: false},
+		{expr: "views>=1.5B", classic: true, lofi: false},
+		{expr: "likes=2m", classic: true, lofi: false},
+		{expr: "likes!=2M", classic: false, lofi: true},
+		{expr: "likes<1k", classic: false, lofi: true},
+		{expr: "likes<=999", classic: false, lofi: true},
+		{expr: "likes>1k", classic: true, lofi: false},
+		{expr: "likes>=2000000", classic: true, lofi: false},
+
+		// Published dates; plain dates are local midnight, so exact
+		// comparisons use RFC 3339
+		{expr: "published<2010-01-01", classic: true, lofi: false},
+		{expr: "published>=2024-01-01T00:00:00Z", classic: false, lofi: true},
+		{expr: "published=2024-01-01T00:00:00Z", classic: false, lofi: true},
+		{expr: "published!=2024-01-01T00:00:00Z", classic: true, lofi: false},
+		{expr: "published>2020-06-01 and published<=2024-01-01T00:00:00Z", classic: false, lofi: true},
+
+		// m is minutes for durations and millions for counts
+		{expr: "duration>2m and views>2m", classic: true, lofi: false},
+		{expr: "duration<5m and likes<2m", classic: false, lofi: true},
+
+		// and binds tighter than or, not tighter than and
+		{expr: "title~lofi or title~never and views<1k", classic: false, lofi: true},
+		{expr: "(title~lofi or title~never) and views<1k", classic: false, lofi: false},
+		{expr: "title~never and views>1k or channel~lofi", classic: true, lofi: true},
+		{expr: "not title~lofi and views>1k", classic: true, lofi: false},
+		{expr: "not (title~lofi and views>1k)", classic: true, lofi: false},
+		{expr: "not not title~lofi", classic: false, lofi: true},
+		{expr: "!title~lofi", classic: true, lofi: false},
+		{expr: "NOT title~lofi OR likes<1k", classic: true, lofi: true},
+		{expr: "(((views>1k)))", classic: true, lofi: true},
+
+		// Symbols need no surrounding space
+		{expr: "duration>3m&&views>1k", classic: true, lofi: true},
+		{expr: "duration>4m&&views>1k", classic: false, lofi: true},
+		{expr: "title~lofi||title~never", classic: true, lofi: true},
+		{expr: "!(views>1k)", classic: false, lofi: false},
+		{expr: "(views>1m)or(likes<1k)", classic: true, lofi: true},
+		{expr: `channel~"lofi"&&duration<5m`, classic: false, lofi: true},
+		{expr: "  views  >  1k  ", classic: true, lofi: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := expr.Match(classic); got != tt.classic {
+				t.Errorf("Match(classic) = %v, want %v", got, tt.classic)
+			}
+			if got := expr.Match(lofi); got != tt.lofi {
+				t.Errorf("Match(lofi) = %v, want %v", got, tt.lofi)
+			}
+			if expr.String() != tt.expr {
+				t.Errorf("String() = %q, want %q", expr.String(), tt.expr)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "title=never gonna give you up", want: `filter: expected and/or, got "gonna give you up" at position 13`},
+		{expr: "duration>3m)", want: `filter: expected and/or, got ")" at position 12`},
+		{expr: "duration>3m views>1k", want: `filter: expected and/or, got "views>1k" at position 13`},
+		{expr: "duration>3m>4m", want: `filter: expected and/or, got ">4m" at position 12`},
+		{expr: "title~lofi~x", want: `filter: expected and/or, got "~x" at position 11`},
+		{expr: "(duration>3m", want: "filter: missing ) at position 13"},
+		{expr: "((views>1k)", want: "filter: missing ) at position 12"},
+		{expr: "(views>1k or", want: "filter: expected a comparison at position 13"},
+		{expr: "not", want: "filter: expected a comparison at position 4"},
+		{expr: ">3m", want: `filter: expected a field name, got ">3m" at position 1`},
+		{expr: "duration 3m", want: `filter: expected an operator after "duration" at position 10`},
+		{expr: "duration>", want: "filter: expected a value at position 10"},
+		{expr: "duration>&&views>1k", want: "filter: expected a value at position 10"},
+		{expr: `title="lofi`, want: "filter: unterminated string at position 7"},
+		{expr: "title>lofi", want: "filter: title only supports =, !=, ~ and !~ at position 11"},
+		{expr: "views~1k", want: "filter: views does not support ~ at position 9"},
+		{expr: "size>1", want: "filter: unknown field \"size\" (valid: title, channel, description, id, duration, views, likes, published) at position 7"},
+		{expr: "views>1x", want: `filter: invalid count "1x" (use 1500, 100k or 2.5m) at position 9`},
+		{expr: `views>""`, want: `filter: invalid count "" (use 1500, 100k or 2.5m) at position 9`},
+		{expr: "views>NaN", want: `filter: invalid count "NaN" (use 1500, 100k or 2.5m) at position 10`},
+		{expr: "duration>soon", want: `filter: invalid duration "soon" (use 3m, 1h30m or 4:13) at position 14`},
+		{expr: "published>later", want: `filter: invalid date "later" (use YYYY-MM-DD, RFC 3339 or an age like 7d) at position 16`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %v, want an error", tt.expr, expr)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Parse(%q) error = %q, want %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "0", want: 0},
+		{in: "1500", want: 1500},
+		{in: "1.5k", want: 1500},
+		{in: "100K", want: 100_000},
+		{in: "2m", want: 2_000_000},
+		{in: "2.5M", want: 2_500_000},
+		{in: "1b", want: 1_000_000_000},
+		{in: "", wantErr: true},
+		{in: "k", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "1x", wantErr: true},
+		{in: "Inf", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseCount(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseCount(%q) = %d, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCount(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseCount(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "3m", want: 3 * time.Minute},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "90s", want: 90 * time.Second},
+		{in: "90", want: 90 * time.Second},
+		{in: "4:13", want: 4*time.Minute + 13*time.Second},
+		{in: "1:02:03", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{in: "0:00", want: 0},
+		{in: "", wantErr: true},
+		{in: "3 minutes", wantErr: true},
+		{in: "4:", wantErr: true},
+		{in: "4:x", wantErr: true},
+		{in: "4:-1", wantErr: true},
+		{in: "1:2:3:4", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDuration(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseDuration(%q) = %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDuration(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	videos := []yt.Video{lofi, classic}
+
+	all, err := Parse("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := all.Apply(videos); !slices.EqualFunc(got, videos, sameID) {
+		t.Errorf("empty expression kept %v, want every video", ids(got))
+	}
+
+	expr, err := Parse("views>1k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(expr.Apply(videos)); !slices.Equal(got, []string{lofi.ID, classic.ID}) {
+		t.Errorf("Apply kept %v, want both in their original order", got)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr string
+	}{
+		{spec: "", want: ""},
+		{spec: "views", want: "views"},
+		{spec: "views:asc", want: "views"},
+		{spec: "views:desc,title", want: "views:desc,title"},
+		{spec: "-likes", want: "likes:desc"},
+		{spec: " Duration , -Published ", want: "duration,published:desc"},
+		{spec: "title,,", want: "title"},
+		{spec: "views:up", wantErr: `invalid sort direction "up" (use asc or desc)`},
+		{spec: "size", wantErr: `unknown sort key "size" (valid: duration, views, likes, published, title)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSort(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ParseSort(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSort(%q): %v", tt.spec, err)
+			}
+			if s.String() != tt.want {
+				t.Errorf("ParseSort(%q) = %q, want %q", tt.spec, s, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortApply(t *testing.T) {
+	a := yt.Video{ID: "a", Title: "b side", ViewCount: 10, Length: time.Minute}
+	b := yt.Video{ID: "b", Title: "A side", ViewCount: 20, Length: time.Minute}
+	c := yt.Video{ID: "c", Title: "c side", ViewCount: 10, Length: 2 * time.Minute}
+
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{spec: "", want: []string{"a", "b", "c"}},
+		{spec: "title", want: []string{"b", "a", "c"}},
+		{spec: "views:desc", want: []string{"b", "a", "c"}},
+		{spec: "views", want: []string{"a", "c", "b"}},
+		{spec: "-duration,views:desc", want: []string{"c", "b", "a"}},
+		{spec: "duration", want: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSort(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			videos := []yt.Video{a, b, c}
+			s.Apply(videos)
+			if got := ids(videos); !slices.Equal(got, tt.want) {
+				t.Errorf("sorted by %q = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func sameID(a, b yt.Video) bool { return a.ID == b.ID }
+
+func ids(videos []yt.Video) []string {
+	out := make([]string, len(videos))
+	for i, v := range videos {
+		out[i] = v.ID
+	}
+	return out
+}
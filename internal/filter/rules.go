@@ -0,0 +1,112 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// Rules drop videos that are rarely wanted in a music player: Shorts, live
+// streams, hour long loops and anything outside the wanted categories
+type Rules struct {
+	Shorts bool
+	// ShortMaxLength, when set, also takes unmarked videos this long or
+	// shorter for Shorts. Off by default as it catches short songs too.
+	ShortMaxLength time.Duration
+	Live           bool
+	MinDuration    time.Duration
+	MaxDuration    time.Duration
+	// TitlePatterns are matched against titles, case-insensitively
+	TitlePatterns []*regexp.Regexp
+	// Categories are the category IDs to keep. Videos whose category is
+	// unknown are kept.
+	Categories []string
+}
+
+// NewRules compiles exclusion rules. Categories may be given by ID or name.
+func NewRules(shorts, live bool, minDuration, maxDuration time.Duration, titlePatterns, categories []string) (*Rules, error) {
+	r := &Rules{Shorts: shorts, Live: live, MinDuration: minDuration, MaxDuration: maxDuration}
+	for _, pattern := range titlePatterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid title pattern %q: %w", pattern, err)
+		}
+		r.TitlePatterns = append(r.TitlePatterns, re)
+	}
+	for _, category := range categories {
+		id, err := yt.ParseCategory(category)
+		if err != nil {
+			return nil, err
+		}
+		r.Categories = append(r.Categories, id)
+	}
+	return r, nil
+}
+
+// Excludes reports whether v breaks a rule and, if so, which one
+func (r *Rules) Excludes(v yt.Video) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	if r.Shorts && (IsShort(v) || r.ShortMaxLength > 0 && v.Length > 0 && v.Length <= r.ShortMaxLength) {
+		return "short", true
+	}
+	if r.Live && v.IsLive() {
+		return v.LiveBroadcast, true
+	}
+	// Live streams report no length, so only judge videos that have one
+	if v.Length > 0 {
+		if r.MinDuration > 0 && v.Length < r.MinDuration {
+			return "too short", true
+		}
+		if r.MaxDuration > 0 && v.Length > r.MaxDuration {
+			return "too long", true
+		}
+	}
+	for _, re := range r.TitlePatterns {
+		if re.MatchString(v.Title) {
+			return "title matches " + re.String()[len("(?i)"):], true
+		}
+	}
+	if len(r.Categories) > 0 && v.CategoryID != "" && !contains(r.Categories, v.CategoryID) {
+		return "category " + v.CategoryID, true
+	}
+	return "", false
+}
+
+// Apply returns the videos no rule excludes, in their original order
+func (r *Rules) Apply(videos []yt.Video) []yt.Video {
+	if r == nil {
+		return videos
+	}
+	kept := make([]yt.Video, 0, len(videos))
+	for _, v := range videos {
+		if _, excluded := r.Excludes(v); !excluded {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// IsShort reports whether something marks v as a YouTube Short: the
+// backend, a /shorts/ URL or a #shorts tag. Length alone is no sign, as
+// plenty of songs, intros and interludes last under a minute.
+func IsShort(v yt.Video) bool {
+	if v.Short || strings.Contains(v.URL, "/shorts/") {
+		return true
+	}
+	return strings.Contains(strings.ToLower(v.Title), "#short") ||
+		strings.Contains(strings.ToLower(v.Description), "#short")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
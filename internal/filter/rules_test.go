@@ -0,0 +1,46 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+func TestExcludesShorts(t *testing.T) {
+	song := yt.Video{Title: "Interlude", URL: yt.VideoURL("aaaaaaaaaaa"), Length: 45 * time.Second}
+	tests := []struct {
+		name           string
+		video          yt.Video
+		shortMaxLength time.Duration
+		want           bool
+	}{
+		{name: "short song", video: song},
+		{name: "marked by the backend", video: yt.Video{Short: true, Length: 3 * time.Minute}, want: true},
+		{name: "shorts URL", video: yt.Video{URL: "https://www.youtube.com/shorts/aaaaaaaaaaa"}, want: true},
+		{name: "tag in the title", video: yt.Video{Title: "Dance #Shorts"}, want: true},
+		{name: "tag in the description", video: yt.Video{Description: "#short #music"}, want: true},
+		{name: "short song with a length threshold", video: song, shortMaxLength: time.Minute, want: true},
+		{name: "longer than the threshold", video: song, shortMaxLength: 30 * time.Second},
+		{name: "unknown length with a threshold", video: yt.Video{Title: "Live"}, shortMaxLength: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewRules(true, false, 0, 0, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rules.ShortMaxLength = tt.shortMaxLength
+			reason, got := rules.Excludes(tt.video)
+			if got != tt.want {
+				t.Errorf("Excludes = %q, %v; want %v", reason, got, tt.want)
+			}
+
+			// Without the Shorts rule nothing is dropped
+			rules.Shorts = false
+			if reason, got := rules.Excludes(tt.video); got {
+				t.Errorf("Excludes with Shorts off = %q", reason)
+			}
+		})
+	}
+}
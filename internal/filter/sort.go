@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// SortKeys lists the fields a list can be sorted by
+var SortKeys = []string{"duration", "views", "likes", "published", "title"}
+
+var sortCompare = map[string]func(a, b yt.Video) int{
+	"duration":  func(a, b yt.Video) int { return cmp.Compare(a.Length, b.Length) },
+	"views":     func(a, b yt.Video) int { return cmp.Compare(a.ViewCount, b.ViewCount) },
+	"likes":     func(a, b yt.Video) int { return cmp.Compare(a.LikeCount, b.LikeCount) },
+	"published": func(a, b yt.Video) int { return a.PublishedAt.Compare(b.PublishedAt) },
+	"title":     func(a, b yt.Video) int { return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)) },
+}
+
+type sortKey struct {
+	name    string
+	compare func(a, b yt.Video) int
+	desc    bool
+}
+
+// Sort orders videos by one or more keys
+type Sort struct {
+	keys []sortKey
+}
+
+// ParseSort parses comma separated sort keys such as "views:desc,title".
+// A key is ascending unless suffixed with :desc or prefixed with -.
+func ParseSort(spec string) (*Sort, error) {
+	s := &Sort{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		key := sortKey{}
+		if strings.HasPrefix(part, "-") {
+			key.desc = true
+			part = part[1:]
+		}
+		if name, direction, ok := strings.Cut(part, ":"); ok {
+			switch direction {
+			case "asc":
+			case "desc":
+				key.desc = true
+			default:
+				return nil, fmt.Errorf("invalid sort direction %q (use asc or desc)", direction)
+			}
+			part = name
+		}
+
+		compare, ok := sortCompare[part]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort key %q (valid: %s)", part, strings.Join(SortKeys, ", "))
+		}
+		key.name, key.compare = part, compare
+		s.keys = append(s.keys, key)
+	}
+	return s, nil
+}
+
+// String describes the sort, e.g. "views:desc,title"
+func (s *Sort) String() string {
+	parts := make([]string, len(s.keys))
+	for i, key := range s.keys {
+		parts[i] = key.name
+		if key.desc {
+			parts[i] += ":desc"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Apply sorts videos in place, keeping the original order of ties
+func (s *Sort) Apply(videos []yt.Video) {
+	if len(s.keys) == 0 {
+		return
+	}
+	slices.SortStableFunc(videos, func(a, b yt.Video) int {
+		for _, key := range s.keys {
+			c := key.compare(a, b)
+			if key.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
@@ -0,0 +1,114 @@
+package library
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// ChangeKind is the kind of edit a Change makes to a remote playlist
+type ChangeKind string
+
+const (
+	ChangeAdd    ChangeKind = "add"
+	ChangeRemove ChangeKind = "remove"
+	ChangeMove   ChangeKind = "move"
+)
+
+// Change is one edit to a remote playlist. Changes are meant to be made in
+// the order Plan returns them, as positions account for the edits before.
+type Change struct {
+	Kind    ChangeKind
+	VideoID string
+	Title   string
+	// Item is the remote entry removed or moved
+	Item yt.PlaylistItem
+	// Position is where the video is added or moved to
+	Position int
+}
+
+func (c Change) String() string {
+	title := c.Title
+	if title == "" {
+		title = c.VideoID
+	}
+	switch c.Kind {
+	case ChangeAdd:
+		return fmt.Sprintf("+ %s (at %d)", title, c.Position+1)
+	case ChangeRemove:
+		return fmt.Sprintf("- %s", title)
+	case ChangeMove:
+		return fmt.Sprintf("~ %s (%d → %d)", title, c.Item.Position+1, c.Position+1)
+	}
+	return string(c.Kind) + " " + title
+}
+
+// Plan lists the edits that turn the remote playlist into the local one:
+// entries missing locally are removed, then every position is filled in
+// order by moving the matching remote entry there or adding the video.
+// Duplicates are matched by count.
+func Plan(local []yt.Video, remote []yt.PlaylistItem) []Change {
+	var changes []Change
+
+	wanted := make(map[string]int, len(local))
+	for _, v := range local {
+		wanted[v.ID]++
+	}
+	current := make([]yt.PlaylistItem, 0, len(remote))
+	for _, item := range remote {
+		if wanted[item.VideoID] > 0 {
+			wanted[item.VideoID]--
+			current = append(current, item)
+			continue
+		}
+		changes = append(changes, Change{Kind: ChangeRemove, VideoID: item.VideoID, Title: item.Title, Item: item})
+	}
+
+	for i, v := range local {
+		if i < len(current) && current[i].VideoID == v.ID {
+			continue
+		}
+
+		from := -1
+		for j := i + 1; j < len(current); j++ {
+			if current[j].VideoID == v.ID {
+				from = j
+				break
+			}
+		}
+		if from < 0 {
+			changes = append(changes, Change{Kind: ChangeAdd, VideoID: v.ID, Title: v.Title, Position: i})
+			current = insertItem(current, i, yt.PlaylistItem{VideoID: v.ID, Title: v.Title})
+			continue
+		}
+
+		item := current[from]
+		changes = append(changes, Change{Kind: ChangeMove, VideoID: item.VideoID, Title: item.Title, Item: item, Position: i})
+		current = insertItem(append(current[:from], current[from+1:]...), i, item)
+	}
+	return changes
+}
+
+func insertItem(items []yt.PlaylistItem, i int, item yt.PlaylistItem) []yt.PlaylistItem {
+	items = append(items, yt.PlaylistItem{})
+	copy(items[i+1:], items[i:])
+	items[i] = item
+	return items
+}
+
+// Summary counts changes by kind, e.g. "2 added, 1 removed, 0 moved"
+func Summary(changes []Change) string {
+	if len(changes) == 0 {
+		return "Already up to date"
+	}
+	counts := map[ChangeKind]int{}
+	for _, change := range changes {
+		counts[change.Kind]++
+	}
+	return strings.Join([]string{
+		fmt.Sprintf("%d added", counts[ChangeAdd]),
+		fmt.Sprintf("%d removed", counts[ChangeRemove]),
+		fmt.Sprintf("%d moved", counts[ChangeMove]),
+	}, ", ")
+}
@@ -0,0 +1,111 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const (
+	appDirName      = "gplay"
+	historyFileName = "history.json"
+
+	// maxHistoryEntries bounds the history file
+	maxHistoryEntries = 500
+)
+
+// HistoryEntry is a single played track
+type HistoryEntry struct {
+	Video    yt.Video  `json:"video"`
+	PlayedAt time.Time `json:"played_at"`
+}
+
+// History keeps recently played tracks on disk so they can be replayed
+// without the YouTube API
+type History struct {
+	mu      sync.Mutex
+	path    string
+	entries []HistoryEntry
+}
+
+// DefaultHistoryPath returns the history file location in the user cache
+// directory
+func DefaultHistoryPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, appDirName, historyFileName), nil
+}
+
+// LoadHistory reads the history file at path. A missing file yields an
+// empty history.
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("error reading history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return h, fmt.Errorf("error parsing history %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Add records a play, moving an already known video to the front, and
+// saves the history
+func (h *History) Add(video yt.Video) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := make([]HistoryEntry, 0, len(h.entries)+1)
+	entries = append(entries, HistoryEntry{Video: video, PlayedAt: time.Now()})
+	for _, e := range h.entries {
+		if e.Video.ID != video.ID {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) > maxHistoryEntries {
+		entries = entries[:maxHistoryEntries]
+	}
+	h.entries = entries
+
+	return h.save()
+}
+
+// Videos returns the played videos, most recent first
+func (h *History) Videos() []yt.Video {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	videos := make([]yt.Video, len(h.entries))
+	for i, e := range h.entries {
+		videos[i] = e.Video
+	}
+	return videos
+}
+
+func (h *History) save() error {
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+	if err := os.WriteFile(h.path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// extinf starts the M3U line that carries the title of the next entry
+const extinf = "#EXTINF:"
+
+// LoadPlaylist reads a local playlist file, see ReadPlaylist
+func LoadPlaylist(path string) ([]yt.Video, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening playlist: %w", err)
+	}
+	defer f.Close()
+
+	videos, err := ReadPlaylist(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading playlist %s: %w", path, err)
+	}
+	return videos, nil
+}
+
+// SavePlaylist writes a local playlist: JSON when path ends in .json,
+// otherwise an M3U file whose entries are watch URLs
+func SavePlaylist(path string, videos []yt.Video) error {
+	var b bytes.Buffer
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := json.MarshalIndent(videos, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding playlist: %w", err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	} else {
+		b.WriteString("#EXTM3U\n")
+		for _, v := range videos {
+			// M3U uses -1 for an unknown length
+			seconds := -1
+			if v.Length > 0 {
+				seconds = int(v.Length.Seconds())
+			}
+			fmt.Fprintf(&b, "%s%d,%s\n%s\n", extinf, seconds, v.Title, yt.VideoURL(v.ID))
+		}
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("error creating playlist directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing playlist: %w", err)
+	}
+	return nil
+}
+
+// ReadPlaylist reads a local playlist: the JSON or JSON lines output of
+// gplay, or text with one video URL or ID per line. In text, lines
+// starting with # are comments, except M3U #EXTINF lines which name the
+// entry below them.
+func ReadPlaylist(r io.Reader) ([]yt.Video, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return readJSONPlaylist(trimmed)
+	}
+	return readTextPlaylist(data)
+}
+
+func readJSONPlaylist(data []byte) ([]yt.Video, error) {
+	var videos []yt.Video
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &videos); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for dec.More() {
+			var v yt.Video
+			if err := dec.Decode(&v); err != nil {
+				return nil, err
+			}
+			videos = append(videos, v)
+		}
+	}
+
+	for i, v := range videos {
+		if v.ID == "" {
+			id, err := yt.ParseVideoID(v.URL)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			videos[i].ID = id
+		}
+	}
+	return videos, nil
+}
+
+func readTextPlaylist(data []byte) ([]yt.Video, error) {
+	var videos []yt.Video
+	title := ""
+	var length time.Duration
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, extinf); ok {
+			// #EXTINF:<seconds>,<title>
+			seconds, name, _ := strings.Cut(rest, ",")
+			title = name
+			if n, err := strconv.Atoi(strings.TrimSpace(seconds)); err == nil && n > 0 {
+				length = time.Duration(n) * time.Second
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, err := yt.ParseVideoID(strings.Fields(line)[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		videos = append(videos, yt.Video{ID: id, URL: yt.VideoURL(id), Title: strings.TrimSpace(title), Length: length})
+		title, length = "", 0
+	}
+	return videos, scanner.Err()
+}
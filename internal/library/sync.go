@@ -0,0 +1,249 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const syncFileName = "sync.json"
+
+// Conflict is something a merge could not settle on its own, or settled
+// in a way worth reporting
+type Conflict struct {
+	VideoID string
+	Title   string
+	Reason  string
+}
+
+func (c Conflict) String() string {
+	if c.VideoID == "" {
+		return c.Reason
+	}
+	title := c.Title
+	if title == "" {
+		title = c.VideoID
+	}
+	return fmt.Sprintf("%s: %s", title, c.Reason)
+}
+
+// Merge combines a local and a remote playlist that were equal to base
+// when last synced. Videos added on either side are kept, videos removed
+// on either side are dropped. The order of the side that was reordered
+// wins; when both were, or there is no base yet, local order wins unless
+// preferRemote is set. Playlists are compared by video ID, so a repeated
+// video is kept once.
+func Merge(base []string, local []yt.Video, remote []yt.PlaylistItem, preferRemote bool) ([]yt.Video, []Conflict) {
+	var conflicts []Conflict
+
+	inBase := make(map[string]bool, len(base))
+	for _, id := range base {
+		inBase[id] = true
+	}
+	videos := make(map[string]yt.Video)
+	var localIDs, remoteIDs []string
+	for _, v := range local {
+		if _, seen := videos[v.ID]; !seen {
+			videos[v.ID] = v
+			localIDs = append(localIDs, v.ID)
+		}
+	}
+	inLocal := toSet(localIDs)
+	remoteItems := make(map[string]yt.PlaylistItem)
+	for _, item := range remote {
+		if _, seen := remoteItems[item.VideoID]; seen {
+			continue
+		}
+		remoteItems[item.VideoID] = item
+		remoteIDs = append(remoteIDs, item.VideoID)
+		if _, known := videos[item.VideoID]; !known {
+			videos[item.VideoID] = yt.Video{ID: item.VideoID, URL: yt.VideoURL(item.VideoID), Title: item.Title}
+		}
+	}
+	inRemote := toSet(remoteIDs)
+
+	kept := make(map[string]bool, len(videos))
+	for _, id := range slices.Concat(localIDs, remoteIDs) {
+		if _, done := kept[id]; done {
+			continue
+		}
+		item, onRemote := remoteItems[id]
+		switch {
+		case inLocal[id] && inRemote[id]:
+			kept[id] = true
+			if item.Availability != yt.Available {
+				conflicts = append(conflicts, Conflict{id, videos[id].Title, fmt.Sprintf("%s on YouTube", item.Availability)})
+			}
+		case inLocal[id]:
+			// Added locally, or removed on YouTube since the last sync
+			kept[id] = !inBase[id]
+		case onRemote && !inBase[id]:
+			kept[id] = item.Availability == yt.Available
+			if !kept[id] {
+				conflicts = append(conflicts, Conflict{id, item.Title, fmt.Sprintf("added on YouTube but %s, not copied", item.Availability)})
+			}
+		default:
+			kept[id] = false
+		}
+	}
+	notKept := func(id string) bool { return !kept[id] }
+	localKept := slices.DeleteFunc(slices.Clone(localIDs), notKept)
+	remoteKept := slices.DeleteFunc(slices.Clone(remoteIDs), notKept)
+
+	primary, secondary := localKept, remoteKept
+	if len(base) > 0 {
+		// Compare the order of the videos all three lists share
+		shared := func(ids []string) []string {
+			return slices.DeleteFunc(slices.Clone(ids), func(id string) bool {
+				return !inBase[id] || !inLocal[id] || !inRemote[id]
+			})
+		}
+		baseOrder, localOrder, remoteOrder := shared(base), shared(localIDs), shared(remoteIDs)
+		localMoved := !slices.Equal(localOrder, baseOrder)
+		remoteMoved := !slices.Equal(remoteOrder, baseOrder)
+
+		switch {
+		case remoteMoved && !localMoved:
+			preferRemote = true
+		case localMoved && !remoteMoved:
+			preferRemote = false
+		case localMoved && remoteMoved && !slices.Equal(localOrder, remoteOrder):
+			side := "local"
+			if preferRemote {
+				side = "YouTube"
+			}
+			conflicts = append(conflicts, Conflict{Reason: "both sides were reordered, keeping the " + side + " order"})
+		}
+	}
+	if preferRemote {
+		primary, secondary = remoteKept, localKept
+	}
+
+	merged := mergeOrder(primary, secondary)
+	result := make([]yt.Video, len(merged))
+	for i, id := range merged {
+		result[i] = videos[id]
+	}
+	return result, conflicts
+}
+
+// mergeOrder adds the IDs of secondary missing from primary, each after
+// the closest one before it in secondary that primary has
+func mergeOrder(primary, secondary []string) []string {
+	merged := slices.Clone(primary)
+	present := toSet(merged)
+	for i, id := range secondary {
+		if present[id] {
+			continue
+		}
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if k := slices.Index(merged, secondary[j]); k >= 0 {
+				at = k + 1
+				break
+			}
+		}
+		merged = slices.Insert(merged, at, id)
+		present[id] = true
+	}
+	return merged
+}
+
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// SyncState is what a local and a remote playlist held after their last
+// sync, the base Merge needs
+type SyncState struct {
+	VideoIDs []string  `json:"video_ids"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// SyncStates keeps the SyncState of every synced pair of playlists on disk
+type SyncStates struct {
+	mu     sync.Mutex
+	path   string
+	states map[string]SyncState
+}
+
+// DefaultSyncStatePath returns the sync state file location next to the
+// config file. It is not kept with the cache since without it the next sync
+// brings back every video deleted on one side since the last one.
+func DefaultSyncStatePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine config directory: %w", err)
+	}
+	return filepath.Join(dir, appDirName, syncFileName), nil
+}
+
+// LoadSyncStates reads the sync state file at path. A missing file yields
+// no states.
+func LoadSyncStates(path string) (*SyncStates, error) {
+	s := &SyncStates{path: path, states: map[string]SyncState{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("error reading sync state: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.states); err != nil {
+		return s, fmt.Errorf("error parsing sync state %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the state of the last sync between a local file and a
+// playlist
+func (s *SyncStates) Get(localPath, playlistID string) (SyncState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[syncKey(localPath, playlistID)]
+	return state, ok
+}
+
+// Set records a sync between a local file and a playlist and saves the
+// states
+func (s *SyncStates) Set(localPath, playlistID string, videos []yt.Video) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, len(videos))
+	for i, v := range videos {
+		ids[i] = v.ID
+	}
+	s.states[syncKey(localPath, playlistID)] = SyncState{VideoIDs: ids, SyncedAt: time.Now()}
+
+	data, err := json.MarshalIndent(s.states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding sync state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("error creating sync state directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing sync state: %w", err)
+	}
+	return nil
+}
+
+func syncKey(localPath, playlistID string) string {
+	if abs, err := filepath.Abs(localPath); err == nil {
+		localPath = abs
+	}
+	return localPath + "|" + playlistID
+}
@@ -0,0 +1,285 @@
+package library
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string
+		local, remote string
+		preferRemote  bool
+		want          string
+		conflicts     []string
+	}{
+		{name: "first sync keeps both sides", local: "a b", remote: "b c", want: "a b c"},
+		{name: "first sync puts remote only videos first", local: "a b", remote: "c d", want: "c d a b"},
+		{name: "first sync prefers remote order", local: "b a", remote: "a b c", preferRemote: true, want: "a b c"},
+		{name: "first sync with an empty side", local: "", remote: "a b", want: "a b"},
+		{name: "unchanged", base: "a b c", local: "a b c", remote: "a b c", want: "a b c"},
+
+		{name: "added locally", base: "a b c", local: "a b x c", remote: "a b c", want: "a b x c"},
+		{name: "added on YouTube", base: "a b c", local: "a b c", remote: "a y b c", want: "a y b c"},
+		{name: "added on both sides", base: "a b", local: "x a b", remote: "a b y", want: "x a b y"},
+		{name: "removed locally", base: "a b c", local: "a c", remote: "a b c", want: "a c"},
+		{name: "removed on YouTube", base: "a b c", local: "a b c", remote: "a c", want: "a c"},
+		{name: "removed on both sides", base: "a b c", local: "b c", remote: "a b", want: "b"},
+		{name: "reordered locally", base: "a b c", local: "c b a", remote: "a b c", preferRemote: true, want: "c b a"},
+		{name: "reordered on YouTube", base: "a b c", local: "a b c", remote: "c a b", want: "c a b"},
+		{
+			name: "both reordered keeps local order", base: "a b c", local: "c b a", remote: "b a c", want: "c b a",
+			conflicts: []string{"both sides were reordered, keeping the local order"},
+		},
+		{
+			name: "both reordered keeps remote order", base: "a b c", local: "c b a", remote: "b a c", preferRemote: true, want: "b a c",
+			conflicts: []string{"both sides were reordered, keeping the YouTube order"},
+		},
+		{name: "both reordered the same way", base: "a b c", local: "c b a", remote: "c b a", want: "c b a"},
+		{
+			name: "edits on both sides", base: "a b c d", local: "a c d x", remote: "y c a b",
+			// Only YouTube reordered the shared a and c, so its order wins
+			// and x follows c as it did locally
+			want: "y c x a",
+		},
+		{name: "repeated videos are kept once", base: "a b", local: "a b a", remote: "a a b", want: "a b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge(ids(tt.base), videos(tt.local), items(tt.remote), tt.preferRemote)
+			if got := strings.Join(videoIDs(got), " "); got != tt.want {
+				t.Errorf("Merge = %q, want %q", got, tt.want)
+			}
+			if got := conflictStrings(conflicts); !slices.Equal(got, tt.conflicts) {
+				t.Errorf("conflicts = %q, want %q", got, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestMergeUnavailable(t *testing.T) {
+	remote := items("a b z")
+	remote[1].Availability = yt.AvailabilityDeleted
+	remote[2].Availability = yt.AvailabilityPrivate
+
+	got, conflicts := Merge(ids("a b"), videos("a b"), remote, false)
+	// b is on both sides, so it stays; z was only added on YouTube and
+	// cannot be played, so it is not copied
+	if got := strings.Join(videoIDs(got), " "); got != "a b" {
+		t.Errorf("Merge = %q, want %q", got, "a b")
+	}
+	want := []string{"Video b: deleted on YouTube", "Video z: added on YouTube but private, not copied"}
+	if got := conflictStrings(conflicts); !slices.Equal(got, want) {
+		t.Errorf("conflicts = %q, want %q", got, want)
+	}
+}
+
+func TestMergeKeepsLocalDetails(t *testing.T) {
+	local := videos("a")
+	local[0].ChannelTitle = "Channel a"
+
+	got, _ := Merge(nil, local, items("a y"), false)
+	if len(got) != 2 {
+		t.Fatalf("Merge = %v, want 2 videos", videoIDs(got))
+	}
+	if got[0].ChannelTitle != "Channel a" {
+		t.Errorf("local video lost its details: %+v", got[0])
+	}
+	if got[1].URL != yt.VideoURL("y") || got[1].Title != "Video y" {
+		t.Errorf("remote video = %+v, want its URL and title", got[1])
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name          string
+		local, remote string
+		want          []string
+	}{
+		{name: "equal", local: "a b c", remote: "a b c"},
+		{name: "empty remote", local: "a b", remote: "", want: []string{"+ Video a (at 1)", "+ Video b (at 2)"}},
+		{name: "empty local", local: "", remote: "a b", want: []string{"- Video a", "- Video b"}},
+		{name: "add in the middle", local: "a x b", remote: "a b", want: []string{"+ Video x (at 2)"}},
+		{name: "remove", local: "a c", remote: "a b c", want: []string{"- Video b"}},
+		{name: "move to the front", local: "c a b", remote: "a b c", want: []string{"~ Video c (3 → 1)"}},
+		{name: "swap", local: "b a", remote: "a b", want: []string{"~ Video b (2 → 1)"}},
+		{
+			name: "remove, add and move", local: "c x a", remote: "a b c",
+			want: []string{"- Video b", "~ Video c (3 → 1)", "+ Video x (at 2)"},
+		},
+		{name: "add a duplicate", local: "a a b", remote: "a b", want: []string{"+ Video a (at 2)"}},
+		{name: "remove duplicates", local: "a b", remote: "a a b a", want: []string{"- Video a", "- Video a"}},
+		{name: "keep duplicates", local: "a b a", remote: "a b a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Plan(videos(tt.local), items(tt.remote))
+			got := make([]string, len(changes))
+			for i, c := range changes {
+				got[i] = c.String()
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Plan = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanRemovesTheExtraDuplicates(t *testing.T) {
+	changes := Plan(videos("a b"), items("a a b a"))
+	var removed []string
+	for _, c := range changes {
+		if c.Kind == ChangeRemove {
+			removed = append(removed, c.Item.ID)
+		}
+	}
+	// The first a is kept, the two after it go
+	if want := []string{"item-1", "item-3"}; !slices.Equal(removed, want) {
+		t.Errorf("removed %q, want %q", removed, want)
+	}
+}
+
+// TestPlanReplay makes the planned changes to the remote playlist for every
+// pair of lists of up to four videos drawn from three, repeats included,
+// and checks the result is the local list
+func TestPlanReplay(t *testing.T) {
+	lists := allLists("abc", 4)
+	for _, local := range lists {
+		for _, remote := range lists {
+			remoteItems := items(remote)
+			changes := Plan(videos(local), remoteItems)
+			got, err := replay(remoteItems, changes)
+			if err != nil {
+				t.Fatalf("Plan(%q, %q): %v", local, remote, err)
+			}
+			if want := ids(local); !slices.Equal(got, want) {
+				t.Fatalf("Plan(%q, %q) = %v, replayed to %q", local, remote, changes, got)
+			}
+
+			// Nothing the local list still needs is removed
+			removed := map[string]int{}
+			for _, c := range changes {
+				if c.Kind == ChangeRemove {
+					removed[c.VideoID]++
+				}
+			}
+			for id, n := range removed {
+				if count(remote, id)-n < count(local, id) {
+					t.Fatalf("Plan(%q, %q) removes %d of %s", local, remote, n, id)
+				}
+			}
+		}
+	}
+}
+
+// replay makes changes to items the way YouTube would, returning the
+// video IDs left
+func replay(items []yt.PlaylistItem, changes []Change) ([]string, error) {
+	items = slices.Clone(items)
+	find := func(id string) int {
+		return slices.IndexFunc(items, func(item yt.PlaylistItem) bool { return item.ID == id })
+	}
+	for n, c := range changes {
+		switch c.Kind {
+		case ChangeAdd:
+			if c.Position < 0 || c.Position > len(items) {
+				return nil, fmt.Errorf("%s: position out of range", c)
+			}
+			items = slices.Insert(items, c.Position, yt.PlaylistItem{ID: fmt.Sprintf("added-%d", n), VideoID: c.VideoID})
+		case ChangeRemove:
+			i := find(c.Item.ID)
+			if i < 0 {
+				return nil, fmt.Errorf("%s: no entry %s", c, c.Item.ID)
+			}
+			items = slices.Delete(items, i, i+1)
+		case ChangeMove:
+			i := find(c.Item.ID)
+			if i < 0 {
+				return nil, fmt.Errorf("%s: no entry %s", c, c.Item.ID)
+			}
+			item := items[i]
+			items = slices.Delete(items, i, i+1)
+			if c.Position < 0 || c.Position > len(items) {
+				return nil, fmt.Errorf("%s: position out of range", c)
+			}
+			items = slices.Insert(items, c.Position, item)
+		}
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = item.VideoID
+	}
+	return out, nil
+}
+
+// allLists returns every space separated list of up to n letters from
+// alphabet
+func allLists(alphabet string, n int) []string {
+	lists := []string{""}
+	last := []string{""}
+	for range n {
+		var next []string
+		for _, list := range last {
+			for _, c := range alphabet {
+				next = append(next, strings.TrimSpace(list+" "+string(c)))
+			}
+		}
+		lists = append(lists, next...)
+		last = next
+	}
+	return lists
+}
+
+func count(list, id string) int {
+	n := 0
+	for _, v := range ids(list) {
+		if v == id {
+			n++
+		}
+	}
+	return n
+}
+
+// ids splits a space separated list of video IDs
+func ids(list string) []string {
+	return strings.Fields(list)
+}
+
+func videos(list string) []yt.Video {
+	var out []yt.Video
+	for _, id := range ids(list) {
+		out = append(out, yt.Video{ID: id, Title: "Video " + id, URL: yt.VideoURL(id)})
+	}
+	return out
+}
+
+// items numbers the videos of list as remote playlist entries with IDs
+// item-0, item-1 and so on
+func items(list string) []yt.PlaylistItem {
+	var out []yt.PlaylistItem
+	for i, id := range ids(list) {
+		out = append(out, yt.PlaylistItem{ID: fmt.Sprintf("item-%d", i), VideoID: id, Position: i, Title: "Video " + id})
+	}
+	return out
+}
+
+func videoIDs(videos []yt.Video) []string {
+	out := make([]string, len(videos))
+	for i, v := range videos {
+		out[i] = v.ID
+	}
+	return out
+}
+
+func conflictStrings(conflicts []Conflict) []string {
+	var out []string
+	for _, c := range conflicts {
+		out = append(out, c.String())
+	}
+	return out
+}
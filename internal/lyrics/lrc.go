@@ -0,0 +1,103 @@
+package lyrics
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// lrcTime matches a [mm:ss.xx] line timestamp
+	lrcTime = regexp.MustCompile(`^\[(\d+):(\d{1,2}(?:[.:]\d{1,3})?)\]`)
+	// lrcTag matches an [id:value] header tag such as [ar:Artist]
+	lrcTag = regexp.MustCompile(`^\[([a-z#]+):(.*)\]$`)
+	// lrcWordTime matches the <mm:ss.xx> word timings of enhanced LRC
+	lrcWordTime = regexp.MustCompile(`<\d+:\d{1,2}(?:[.:]\d{1,3})?>`)
+)
+
+// ParseLRC reads lyrics in the LRC format. A line may carry several
+// timestamps, and an [offset:ms] tag shifts every line, positive values
+// making lines appear sooner. Text without any timestamps is returned as
+// unsynced lyrics.
+func ParseLRC(r io.Reader) (*Lyrics, error) {
+	var (
+		timed  []Line
+		plain  []Line
+		offset time.Duration
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := lrcTag.FindStringSubmatch(line); m != nil && !lrcTime.MatchString(line) {
+			if m[1] == "offset" {
+				if ms, err := strconv.Atoi(strings.TrimSpace(m[2])); err == nil {
+					offset = time.Duration(ms) * time.Millisecond
+				}
+			}
+			continue
+		}
+
+		var times []time.Duration
+		for {
+			m := lrcTime.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+			times = append(times, lrcTimestamp(m[1], m[2]))
+			line = line[len(m[0]):]
+		}
+		text := strings.TrimSpace(lrcWordTime.ReplaceAllString(line, ""))
+		if len(times) == 0 {
+			plain = append(plain, Line{Text: text})
+			continue
+		}
+		for _, t := range times {
+			timed = append(timed, Line{Time: t, Text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(timed) == 0 {
+		if len(strings.TrimSpace(joinLines(plain))) == 0 {
+			return nil, ErrNotFound
+		}
+		return &Lyrics{Lines: plain}, nil
+	}
+	for i := range timed {
+		timed[i].Time = max(timed[i].Time-offset, 0)
+	}
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].Time < timed[j].Time })
+	return &Lyrics{Lines: timed, Synced: true}, nil
+}
+
+// lrcTimestamp converts the minutes and seconds of a timestamp. Fractions
+// are hundredths in most files but may have one to three digits.
+func lrcTimestamp(minutes, seconds string) time.Duration {
+	m, _ := strconv.Atoi(minutes)
+	whole, fraction, _ := strings.Cut(strings.Replace(seconds, ":", ".", 1), ".")
+	s, _ := strconv.Atoi(whole)
+	d := time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	if fraction != "" {
+		f, _ := strconv.Atoi(fraction)
+		for i := len(fraction); i < 3; i++ {
+			f *= 10
+		}
+		d += time.Duration(f) * time.Millisecond
+	}
+	return d
+}
+
+func joinLines(lines []Line) string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.Text
+	}
+	return strings.Join(texts, "\n")
+}
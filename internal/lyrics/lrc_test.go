@@ -0,0 +1,148 @@
+package lyrics
+
+import (
+	"bufio"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLRC(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Line
+		synced  bool
+		wantErr error
+	}{
+		{
+			name:   "one timestamp per line",
+			in:     "[00:12.00]Hello\n[00:15.50]World\n",
+			want:   []Line{{12 * time.Second, "Hello"}, {15500 * time.Millisecond, "World"}},
+			synced: true,
+		},
+		{
+			name: "several timestamps on a line",
+			in:   "[00:10.00][00:30.00]Chorus\n[00:20.00]Verse",
+			want: []Line{
+				{10 * time.Second, "Chorus"},
+				{20 * time.Second, "Verse"},
+				{30 * time.Second, "Chorus"},
+			},
+			synced: true,
+		},
+		{
+			name:   "headers are skipped",
+			in:     "[ar:Artist]\n[ti:Title]\n[length: 03:20]\n[#:comment]\n[00:01.00]First",
+			want:   []Line{{time.Second, "First"}},
+			synced: true,
+		},
+		{
+			name:   "positive offset shows lines sooner",
+			in:     "[offset:+500]\n[00:00.20]Clamped\n[00:02.00]Two",
+			want:   []Line{{0, "Clamped"}, {1500 * time.Millisecond, "Two"}},
+			synced: true,
+		},
+		{
+			name:   "negative offset shows lines later",
+			in:     "[00:02.00]Two\n[offset: -250]",
+			want:   []Line{{2250 * time.Millisecond, "Two"}},
+			synced: true,
+		},
+		{
+			name:   "invalid offset is ignored",
+			in:     "[offset:soon]\n[00:02.00]Two",
+			want:   []Line{{2 * time.Second, "Two"}},
+			synced: true,
+		},
+		{
+			name: "fractions of one to three digits",
+			in:   "[01:02]Whole\n[01:02.5]Tenths\n[01:02.345]Millis\n[01:02:34]Colon",
+			want: []Line{
+				{62 * time.Second, "Whole"},
+				{62340 * time.Millisecond, "Colon"},
+				{62345 * time.Millisecond, "Millis"},
+				{62500 * time.Millisecond, "Tenths"},
+			},
+			synced: true,
+		},
+		{
+			name:   "enhanced word timings are dropped",
+			in:     "[00:01.00]<00:01.00>Hello <00:01.50>there",
+			want:   []Line{{time.Second, "Hello there"}},
+			synced: true,
+		},
+		{
+			name:   "empty timed lines mark breaks",
+			in:     "[00:01.00]Sing\n[00:04.00]\n[00:09.00]Again",
+			want:   []Line{{time.Second, "Sing"}, {4 * time.Second, ""}, {9 * time.Second, "Again"}},
+			synced: true,
+		},
+		{
+			name: "untimed text is plain lyrics",
+			in:   "[ar:Artist]\nFirst line\n\nSecond line",
+			want: []Line{{0, "First line"}, {0, ""}, {0, "Second line"}},
+		},
+		{name: "headers only", in: "[ar:Artist]\n[ti:Title]\n[offset:100]\n", wantErr: ErrNotFound},
+		{name: "empty", in: "", wantErr: ErrNotFound},
+		{name: "blank lines", in: "\n  \n\t\n", wantErr: ErrNotFound},
+		{name: "line too long", in: "[00:01.00]" + strings.Repeat("a", 70000), wantErr: bufio.ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLRC(strings.NewReader(tt.in))
+			checkLyrics(t, got, err, tt.want, tt.synced, tt.wantErr)
+		})
+	}
+}
+
+// errAny stands for any error where the exact one is the decoder's
+var errAny = errors.New("any error")
+
+// checkLyrics compares what a parser returned with the lines expected, or
+// the error expected
+func checkLyrics(t *testing.T, got *Lyrics, err error, want []Line, synced bool, wantErr error) {
+	t.Helper()
+	if wantErr != nil {
+		if err == nil {
+			t.Fatalf("got %+v, want an error", got)
+		}
+		if wantErr != errAny && !errors.Is(err, wantErr) {
+			t.Fatalf("error = %v, want %v", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Synced != synced {
+		t.Errorf("Synced = %v, want %v", got.Synced, synced)
+	}
+	if !slices.Equal(got.Lines, want) {
+		t.Errorf("lines = %+v\nwant %+v", got.Lines, want)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	l := &Lyrics{Lines: []Line{{time.Second, "a"}, {3 * time.Second, "b"}}, Synced: true}
+	tests := []struct {
+		pos  time.Duration
+		want int
+	}{
+		{0, -1},
+		{time.Second, 0},
+		{2 * time.Second, 0},
+		{3 * time.Second, 1},
+		{time.Hour, 1},
+	}
+	for _, tt := range tests {
+		if got := l.Index(tt.pos); got != tt.want {
+			t.Errorf("Index(%v) = %d, want %d", tt.pos, got, tt.want)
+		}
+	}
+	if got := (&Lyrics{Lines: l.Lines}).Index(2 * time.Second); got != -1 {
+		t.Errorf("Index of unsynced lyrics = %d, want -1", got)
+	}
+}
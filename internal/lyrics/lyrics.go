@@ -0,0 +1,99 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// ErrNotFound is returned when no source has lyrics for a track
+var ErrNotFound = errors.New("no lyrics found")
+
+// Line is one line of lyrics and when it starts
+type Line struct {
+	Time time.Duration
+	Text string
+}
+
+// Lyrics are the lines of a track. Unsynced lyrics have no timings and are
+// shown as plain text.
+type Lyrics struct {
+	Lines  []Line
+	Synced bool
+	// Source names where the lyrics came from
+	Source string
+}
+
+// Index returns the line being sung at pos, or -1 before the first one
+func (l *Lyrics) Index(pos time.Duration) int {
+	if l == nil || !l.Synced {
+		return -1
+	}
+	return sort.Search(len(l.Lines), func(i int) bool { return l.Lines[i].Time > pos }) - 1
+}
+
+// Provider looks up the lyrics of a track
+type Provider interface {
+	Lyrics(ctx context.Context, video yt.Video) (*Lyrics, error)
+}
+
+// Chain tries providers in order and returns the first lyrics found. Synced
+// lyrics are preferred; plain ones are kept in case no provider has better.
+type Chain []Provider
+
+// Lyrics asks each provider in turn
+func (c Chain) Lyrics(ctx context.Context, video yt.Video) (*Lyrics, error) {
+	var plain *Lyrics
+	var errs []error
+	for _, provider := range c {
+		lyrics, err := provider.Lyrics(ctx, video)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if lyrics.Synced {
+			return lyrics, nil
+		}
+		if plain == nil {
+			plain = lyrics
+		}
+	}
+	if plain != nil {
+		return plain, nil
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, errors.Join(errs...))
+	}
+	return nil, ErrNotFound
+}
+
+var (
+	// noiseGroup matches the bracketed extras of music video titles, e.g.
+	// "(Official Video)" or "[Lyrics]"
+	noiseGroup = regexp.MustCompile(`(?i)\s*[(\[][^)\]]*\b(official|video|audio|lyrics?|visuali[sz]er|hd|4k|remaster(ed)?|mv)\b[^)\]]*[)\]]`)
+	// channelNoise is what labels append to artist channel names
+	channelNoise = regexp.MustCompile(`(?i)\s*(- topic|vevo|official)$`)
+)
+
+// ArtistTitle guesses the artist and song of a music video from titles
+// like "Artist - Song (Official Video)", falling back to the channel name
+// for the artist
+func ArtistTitle(video yt.Video) (string, string) {
+	title := strings.TrimSpace(noiseGroup.ReplaceAllString(video.Title, ""))
+	if artist, song, ok := strings.Cut(title, " - "); ok {
+		return strings.TrimSpace(artist), strings.TrimSpace(song)
+	}
+	return strings.TrimSpace(channelNoise.ReplaceAllString(video.ChannelTitle, "")), title
+}
@@ -0,0 +1,249 @@
+package lyrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const (
+	defaultTimeout = 15 * time.Second
+	userAgent      = "gplay (https://github.com/alanpramil7/gplay)"
+)
+
+// LocalProvider reads .lrc files from a directory, named after the video
+// ID, "<artist> - <title>" or just the title
+type LocalProvider struct {
+	Dir string
+}
+
+// Lyrics looks for a matching file, ignoring case
+func (p LocalProvider) Lyrics(ctx context.Context, video yt.Video) (*Lyrics, error) {
+	entries, err := os.ReadDir(p.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading lyrics directory: %w", err)
+	}
+
+	artist, title := ArtistTitle(video)
+	names := []string{video.ID, artist + " - " + title, title}
+	for _, name := range names {
+		for _, entry := range entries {
+			file := entry.Name()
+			if !strings.EqualFold(filepath.Ext(file), ".lrc") || !strings.EqualFold(strings.TrimSuffix(file, filepath.Ext(file)), name) {
+				continue
+			}
+			f, err := os.Open(filepath.Join(p.Dir, file))
+			if err != nil {
+				return nil, fmt.Errorf("error opening lyrics: %w", err)
+			}
+			lyrics, err := ParseLRC(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("error reading lyrics %s: %w", file, err)
+			}
+			lyrics.Source = file
+			return lyrics, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// SubtitleProvider downloads the captions of a video with yt-dlp, the
+// uploaded ones if any and the automatic ones otherwise
+type SubtitleProvider struct {
+	YtDlpPath string
+	// Languages are yt-dlp --sub-langs patterns, e.g. "en.*"
+	Languages []string
+}
+
+// Lyrics fetches the captions into a temporary directory and parses them
+func (p SubtitleProvider) Lyrics(ctx context.Context, video yt.Video) (*Lyrics, error) {
+	dir, err := os.MkdirTemp("", "gplay-subs-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	languages := strings.Join(p.Languages, ",")
+	if languages == "" {
+		languages = "en.*"
+	}
+	cmd := exec.CommandContext(ctx, p.YtDlpPath,
+		"--no-warnings",
+		"--skip-download",
+		"--write-subs",
+		"--write-auto-subs",
+		"--sub-langs", languages,
+		"--sub-format", "srv3/vtt/best",
+		"--no-playlist",
+		"-o", filepath.Join(dir, "%(id)s.%(ext)s"),
+		yt.VideoURL(video.ID))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if line := strings.TrimSpace(stderr.String()); line != "" {
+			lines := strings.Split(line, "\n")
+			return nil, fmt.Errorf("yt-dlp failed: %s", strings.TrimSpace(lines[len(lines)-1]))
+		}
+		return nil, fmt.Errorf("error running yt-dlp: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil || len(files) == 0 {
+		return nil, ErrNotFound
+	}
+	// Files are named <id>.<language>.<ext>; several languages may match
+	for _, file := range files {
+		lyrics, err := readSubtitles(file)
+		if err != nil {
+			continue
+		}
+		lyrics.Source = "captions (" + strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(file, filepath.Ext(file))), ".") + ")"
+		return lyrics, nil
+	}
+	return nil, ErrNotFound
+}
+
+func readSubtitles(path string) (*Lyrics, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srv3":
+		return ParseSRV3(f)
+	case ".vtt":
+		return ParseVTT(f)
+	case ".lrc":
+		return ParseLRC(f)
+	}
+	return nil, fmt.Errorf("unsupported subtitle format %s", filepath.Ext(path))
+}
+
+// LRCLIBProvider searches an LRCLIB compatible lyrics server
+type LRCLIBProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// lrclibTrack is an entry of the /api/search response
+type lrclibTrack struct {
+	TrackName    string  `json:"trackName"`
+	ArtistName   string  `json:"artistName"`
+	Duration     float64 `json:"duration"`
+	Instrumental bool    `json:"instrumental"`
+	PlainLyrics  string  `json:"plainLyrics"`
+	SyncedLyrics string  `json:"syncedLyrics"`
+}
+
+// Lyrics searches by the artist and title guessed from the video and picks
+// the result closest in length, preferring synced lyrics
+func (p LRCLIBProvider) Lyrics(ctx context.Context, video yt.Video) (*Lyrics, error) {
+	artist, title := ArtistTitle(video)
+	if title == "" {
+		return nil, ErrNotFound
+	}
+	query := url.Values{"track_name": {title}}
+	if artist != "" {
+		query.Set("artist_name", artist)
+	}
+
+	var tracks []lrclibTrack
+	if err := p.getJSON(ctx, "/api/search", query, &tracks); err != nil {
+		return nil, err
+	}
+
+	length := video.Length
+	if length == 0 {
+		length, _ = yt.ParseISODuration(video.Duration)
+	}
+	var best *lrclibTrack
+	for i := range tracks {
+		track := &tracks[i]
+		if track.Instrumental || (track.SyncedLyrics == "" && track.PlainLyrics == "") {
+			continue
+		}
+		if best == nil || lrclibBetter(track, best, length) {
+			best = track
+		}
+	}
+	if best == nil {
+		return nil, ErrNotFound
+	}
+
+	text := best.SyncedLyrics
+	if text == "" {
+		text = best.PlainLyrics
+	}
+	lyrics, err := ParseLRC(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	lyrics.Source = "LRCLIB"
+	return lyrics, nil
+}
+
+// lrclibBetter reports whether a is a better match than b for a video of
+// the given length
+func lrclibBetter(a, b *lrclibTrack, length time.Duration) bool {
+	if (a.SyncedLyrics != "") != (b.SyncedLyrics != "") {
+		return a.SyncedLyrics != ""
+	}
+	if length == 0 {
+		return false
+	}
+	diff := func(t *lrclibTrack) time.Duration {
+		d := time.Duration(t.Duration*float64(time.Second)) - length
+		return max(d, -d)
+	}
+	return diff(a) < diff(b)
+}
+
+func (p LRCLIBProvider) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	target := strings.TrimRight(p.BaseURL, "/") + path + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error contacting lyrics server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("lyrics server returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding lyrics response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package lyrics
+
+import (
+	"bufio"
+	"encoding/xml"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// vttTiming matches the start of a cue timing line,
+	// "00:01:02.345 --> 00:01:04.000 align:start"
+	vttTiming = regexp.MustCompile(`^((?:\d+:)?\d{1,2}:\d{2}\.\d{3})\s+-->`)
+	// vttTag matches inline tags such as <c>, <i> and word timings
+	vttTag = regexp.MustCompile(`<[^>]*>`)
+)
+
+// ParseVTT reads WebVTT captions. YouTube's automatic captions repeat the
+// previous line in every cue as the text rolls up, so a line equal to the
+// one before it is dropped.
+func ParseVTT(r io.Reader) (*Lyrics, error) {
+	var lines []Line
+
+	scanner := bufio.NewScanner(r)
+	var start time.Duration
+	inCue := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := vttTiming.FindStringSubmatch(line); m != nil {
+			start = vttTimestamp(m[1])
+			inCue = true
+			continue
+		}
+		if line == "" {
+			inCue = false
+			continue
+		}
+		if !inCue {
+			continue
+		}
+
+		text := strings.TrimSpace(html.UnescapeString(vttTag.ReplaceAllString(line, "")))
+		if text == "" || (len(lines) > 0 && lines[len(lines)-1].Text == text) {
+			continue
+		}
+		lines = append(lines, Line{Time: start, Text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, ErrNotFound
+	}
+	return &Lyrics{Lines: lines, Synced: true}, nil
+}
+
+// vttTimestamp converts [hh:]mm:ss.mmm
+func vttTimestamp(stamp string) time.Duration {
+	parts := strings.Split(stamp, ":")
+	var d time.Duration
+	for _, part := range parts[:len(parts)-1] {
+		n, _ := strconv.Atoi(part)
+		d = d*60 + time.Duration(n)
+	}
+	d *= time.Minute
+	seconds, millis, _ := strings.Cut(parts[len(parts)-1], ".")
+	s, _ := strconv.Atoi(seconds)
+	ms, _ := strconv.Atoi(millis)
+	return d + time.Duration(s)*time.Second + time.Duration(ms)*time.Millisecond
+}
+
+// srv3Document is YouTube's own timed text format
+type srv3Document struct {
+	Paragraphs []struct {
+		Start    int    `xml:"t,attr"`
+		Text     string `xml:",chardata"`
+		Segments []struct {
+			Text string `xml:",chardata"`
+		} `xml:"s"`
+	} `xml:"body>p"`
+}
+
+// ParseSRV3 reads captions in YouTube's srv3 format, where each <p> is a
+// line starting t milliseconds in, its words optionally split into <s>
+// segments
+func ParseSRV3(r io.Reader) (*Lyrics, error) {
+	var doc srv3Document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var lines []Line
+	for _, p := range doc.Paragraphs {
+		text := p.Text
+		if len(p.Segments) > 0 {
+			var b strings.Builder
+			for _, s := range p.Segments {
+				b.WriteString(s.Text)
+			}
+			text = b.String()
+		}
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			continue
+		}
+		lines = append(lines, Line{Time: time.Duration(p.Start) * time.Millisecond, Text: text})
+	}
+	if len(lines) == 0 {
+		return nil, ErrNotFound
+	}
+	return &Lyrics{Lines: lines, Synced: true}, nil
+}
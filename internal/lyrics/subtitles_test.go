@@ -0,0 +1,138 @@
+package lyrics
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseVTT(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Line
+		wantErr error
+	}{
+		{
+			name: "cue settings and a header",
+			in: `WEBVTT
+Kind: captions
+Language: en
+
+00:00:01.000 --> 00:00:03.000 align:start position:0%
+Hello
+
+00:00:03.500 --> 00:00:05.000 line:90%
+World
+`,
+			want: []Line{{time.Second, "Hello"}, {3500 * time.Millisecond, "World"}},
+		},
+		{
+			name: "minutes and seconds only",
+			in:   "WEBVTT\n\n01:02.500 --> 01:04.000\nLate line\n",
+			want: []Line{{62500 * time.Millisecond, "Late line"}},
+		},
+		{
+			name: "hours",
+			in:   "WEBVTT\n\n1:00:00.250 --> 1:00:02.000\nAn hour in\n",
+			want: []Line{{time.Hour + 250*time.Millisecond, "An hour in"}},
+		},
+		{
+			name: "lines repeated by rolling captions",
+			in: `WEBVTT
+
+00:00:01.000 --> 00:00:02.000
+Hello
+
+00:00:02.000 --> 00:00:03.000
+Hello
+world
+
+00:00:03.000 --> 00:00:04.000
+world
+again
+`,
+			want: []Line{{time.Second, "Hello"}, {2 * time.Second, "world"}, {3 * time.Second, "again"}},
+		},
+		{
+			name: "tags, word timings and entities",
+			in: `WEBVTT
+
+00:00:01.000 --> 00:00:02.000
+<c.colorE5E5E5>Rock</c><00:00:01.500><c> &amp; roll</c>
+
+00:00:02.000 --> 00:00:03.000
+<i>&lt;quiet&gt;</i>
+
+00:00:03.000 --> 00:00:04.000
+<c></c>
+`,
+			want: []Line{{time.Second, "Rock & roll"}, {2 * time.Second, "<quiet>"}},
+		},
+		{
+			name: "identifiers and notes are skipped",
+			in: `WEBVTT
+
+NOTE made by hand
+
+intro
+00:00:01.000 --> 00:00:02.000
+Sung
+`,
+			want: []Line{{time.Second, "Sung"}},
+		},
+		{name: "header only", in: "WEBVTT\n\n", wantErr: ErrNotFound},
+		{name: "empty", in: "", wantErr: ErrNotFound},
+		{name: "not captions", in: "just some text\nwith no cues", wantErr: ErrNotFound},
+		{name: "bad timing", in: "WEBVTT\n\n1.000 --> 2.000\nNever shown\n", wantErr: ErrNotFound},
+		{name: "line too long", in: "WEBVTT\n\n00:01.000 --> 00:02.000\n" + strings.Repeat("a", 70000), wantErr: bufio.ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVTT(strings.NewReader(tt.in))
+			checkLyrics(t, got, err, tt.want, true, tt.wantErr)
+		})
+	}
+}
+
+func TestParseSRV3(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Line
+		wantErr error
+	}{
+		{
+			name: "paragraphs and segments",
+			in: `<?xml version="1.0" encoding="utf-8" ?>
+<timedtext format="3">
+<head><ws id="0"/></head>
+<body>
+<p t="1200" d="2000">Hello   world</p>
+<p t="3500" d="1500" w="1"><s ac="0">Split</s><s t="400" ac="0"> into</s><s t="800" ac="0"> words</s></p>
+<p t="4000" d="10"> </p>
+<p t="6000" d="900">a &amp; b
+across lines</p>
+</body>
+</timedtext>`,
+			want: []Line{
+				{1200 * time.Millisecond, "Hello world"},
+				{3500 * time.Millisecond, "Split into words"},
+				{6 * time.Second, "a & b across lines"},
+			},
+		},
+		{name: "no paragraphs", in: `<timedtext format="3"><body></body></timedtext>`, wantErr: ErrNotFound},
+		{name: "blank paragraphs", in: `<timedtext><body><p t="1"></p><p t="2"><s> </s></p></body></timedtext>`, wantErr: ErrNotFound},
+		{name: "bad start", in: `<timedtext><body><p t="soon">Hi</p></body></timedtext>`, wantErr: errAny},
+		{name: "not XML", in: `{"events": []}`, wantErr: errAny},
+		{name: "unclosed", in: `<timedtext><body><p t="1">Hi`, wantErr: errAny},
+		{name: "empty", in: "", wantErr: errAny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSRV3(strings.NewReader(tt.in))
+			checkLyrics(t, got, err, tt.want, true, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// Format identifies how a list of items is written
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatJSON     Format = "json"
+	FormatJSONL    Format = "jsonl"
+	FormatCSV      Format = "csv"
+	FormatTemplate Format = "template"
+)
+
+// Formats lists every supported format, in the order shown in help text
+var Formats = []Format{FormatTable, FormatJSON, FormatJSONL, FormatCSV, FormatTemplate}
+
+// Options controls how Write renders items
+type Options struct {
+	Format   Format
+	Template string
+}
+
+// Column describes a single column in table and CSV output
+type Column[T any] struct {
+	Header string
+	Value  func(item T) string
+}
+
+// ParseFormat validates a user supplied format name
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (valid: %s)", name, FormatNames())
+}
+
+// FormatNames returns the supported formats as a comma separated list
+func FormatNames() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Write renders items to w in the requested format. Table output uses
+// tableColumns while CSV output uses csvColumns so the latter can carry
+// every field without making the terminal view unreadable.
+func Write[T any](w io.Writer, opts Options, items []T, tableColumns, csvColumns []Column[T]) error {
+	switch opts.Format {
+	case FormatTable, "":
+		return writeTable(w, items, tableColumns)
+	case FormatJSON:
+		return writeJSON(w, items)
+	case FormatJSONL:
+		return writeJSONL(w, items)
+	case FormatCSV:
+		return writeCSV(w, items, csvColumns)
+	case FormatTemplate:
+		return writeTemplate(w, items, opts.Template)
+	default:
+		return fmt.Errorf("unknown output format %q", opts.Format)
+	}
+}
+
+func writeTable[T any](w io.Writer, items []T, columns []Column[T]) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, item := range items {
+		values := make([]string, len(columns))
+		for i, c := range columns {
+			// Tabs and newlines inside a value would break the alignment
+			values[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(c.Value(item))
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func writeJSON[T any](w io.Writer, items []T) error {
+	if items == nil {
+		items = []T{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
+
+func writeJSONL[T any](w io.Writer, items []T) error {
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV[T any](w io.Writer, items []T, columns []Column[T]) error {
+	cw := csv.NewWriter(w)
+
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header
+	}
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = c.Value(item)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTemplate executes the template once per item, adding a trailing
+// newline when the template does not end with one
+func writeTemplate[T any](w io.Writer, items []T, text string) error {
+	if text == "" {
+		return fmt.Errorf("template format requires --template")
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return fmt.Errorf("error executing template: %w", err)
+		}
+	}
+	return nil
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
@@ -0,0 +1,33 @@
+package output
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// playlistTableColumns is the compact view shown in a terminal
+var playlistTableColumns = []Column[yt.Playlist]{
+	{Header: "TITLE", Value: func(p yt.Playlist) string { return truncate(p.Title, tableTitleWidth) }},
+	{Header: "CHANNEL", Value: func(p yt.Playlist) string { return p.ChannelTitle }},
+	{Header: "ITEMS", Value: func(p yt.Playlist) string { return strconv.FormatInt(p.ItemCount, 10) }},
+	{Header: "URL", Value: func(p yt.Playlist) string { return p.URL }},
+}
+
+// playlistCSVColumns mirrors the JSON field names of yt.Playlist
+var playlistCSVColumns = []Column[yt.Playlist]{
+	{Header: "id", Value: func(p yt.Playlist) string { return p.ID }},
+	{Header: "title", Value: func(p yt.Playlist) string { return p.Title }},
+	{Header: "description", Value: func(p yt.Playlist) string { return p.Description }},
+	{Header: "channel_title", Value: func(p yt.Playlist) string { return p.ChannelTitle }},
+	{Header: "channel_id", Value: func(p yt.Playlist) string { return p.ChannelID }},
+	{Header: "item_count", Value: func(p yt.Playlist) string { return strconv.FormatInt(p.ItemCount, 10) }},
+	{Header: "thumbnail_url", Value: func(p yt.Playlist) string { return p.ThumbnailURL }},
+	{Header: "url", Value: func(p yt.Playlist) string { return p.URL }},
+}
+
+// Playlists writes a list of playlists in the requested format
+func Playlists(w io.Writer, opts Options, playlists []yt.Playlist) error {
+	return Write(w, opts, playlists, playlistTableColumns, playlistCSVColumns)
+}
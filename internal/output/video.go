@@ -0,0 +1,58 @@
+package output
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const tableTitleWidth = 50
+
+// videoTableColumns is the compact view shown in a terminal
+var videoTableColumns = []Column[yt.Video]{
+	{Header: "TITLE", Value: func(v yt.Video) string { return truncate(v.Title, tableTitleWidth) }},
+	{Header: "CHANNEL", Value: func(v yt.Video) string { return v.ChannelTitle }},
+	{Header: "DURATION", Value: func(v yt.Video) string {
+		if !v.Playable() {
+			return string(v.Availability)
+		}
+		if v.IsLive() {
+			return v.LiveBroadcast
+		}
+		return yt.FormatDuration(v.Length)
+	}},
+	{Header: "VIEWS", Value: func(v yt.Video) string { return strconv.FormatUint(v.ViewCount, 10) }},
+	{Header: "URL", Value: func(v yt.Video) string { return v.URL }},
+}
+
+// videoCSVColumns mirrors the JSON field names of yt.Video
+var videoCSVColumns = []Column[yt.Video]{
+	{Header: "id", Value: func(v yt.Video) string { return v.ID }},
+	{Header: "title", Value: func(v yt.Video) string { return v.Title }},
+	{Header: "channel_title", Value: func(v yt.Video) string { return v.ChannelTitle }},
+	{Header: "channel_id", Value: func(v yt.Video) string { return v.ChannelID }},
+	{Header: "published_at", Value: func(v yt.Video) string { return v.PublishedAt.Format(time.RFC3339) }},
+	{Header: "duration", Value: func(v yt.Video) string { return v.Duration }},
+	{Header: "view_count", Value: func(v yt.Video) string { return strconv.FormatUint(v.ViewCount, 10) }},
+	{Header: "like_count", Value: func(v yt.Video) string { return strconv.FormatUint(v.LikeCount, 10) }},
+	{Header: "live_broadcast", Value: func(v yt.Video) string { return v.LiveBroadcast }},
+	{Header: "category_id", Value: func(v yt.Video) string { return v.CategoryID }},
+	{Header: "availability", Value: func(v yt.Video) string { return string(v.Availability) }},
+	{Header: "thumbnail_url", Value: func(v yt.Video) string { return v.ThumbnailURL }},
+	{Header: "url", Value: func(v yt.Video) string { return v.URL }},
+}
+
+// Videos writes a list of videos in the requested format
+func Videos(w io.Writer, opts Options, videos []yt.Video) error {
+	return Write(w, opts, videos, videoTableColumns, videoCSVColumns)
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,284 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services"
+)
+
+// restartThreshold is how far into a track Prev restarts it instead of
+// going back
+const restartThreshold = 3 * time.Second
+
+// ErrNoTrack is returned when the queue has no track left to play
+var ErrNoTrack = errors.New("no track to play")
+
+// State is what the player is doing
+type State string
+
+const (
+	StateStopped State = "stopped"
+	StateLoading State = "loading"
+	StatePlaying State = "playing"
+	StatePaused  State = "paused"
+)
+
+// Status is a snapshot of the player
+type Status struct {
+	State State `json:"state"`
+	// Current is the track at Index, nil when the queue is empty
+	Current *yt.Video `json:"current,omitempty"`
+	// Index is the position of Current in the queue, -1 when there is none
+	Index    int           `json:"index"`
+	Queue    int           `json:"queue"` // number of queued tracks
+	Position time.Duration `json:"position"`
+	Live     bool          `json:"live,omitempty"`
+	// Error is why the last track failed to play, if it did
+	Error string `json:"error,omitempty"`
+	// Played counts the tracks started, so clients can tell the track
+	// changed even when the same one is played again
+	Played int `json:"played"`
+	// Ended is set once playback ran past the end of the queue
+	Ended bool `json:"ended,omitempty"`
+}
+
+// Player owns audio playback and a queue of tracks. It moves on to the
+// next playable track when one finishes.
+type Player struct {
+	// playMu serializes starting tracks, which waits on yt-dlp, so mu can
+	// stay free for status requests meanwhile
+	playMu sync.Mutex
+
+	mu      sync.Mutex
+	audio   *services.AudioService
+	history *library.History
+	queue   []yt.Video
+	index   int
+	loading bool
+	played  int
+	ended   bool
+	lastErr error
+}
+
+// New creates a player around audio. Played tracks are recorded in
+// history when it is not nil.
+func New(audio *services.AudioService, history *library.History) *Player {
+	p := &Player{audio: audio, history: history, index: -1}
+	go p.advance()
+	return p
+}
+
+// advance plays the next track each time one finishes
+func (p *Player) advance() {
+	for range p.audio.GetSongCompleteChannel() {
+		_, err := p.Next()
+		if err == nil {
+			continue
+		}
+		p.mu.Lock()
+		p.ended = true
+		p.mu.Unlock()
+		if !errors.Is(err, ErrNoTrack) {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// Play replaces the queue with videos and starts the one at index
+func (p *Player) Play(videos []yt.Video, index int) (Status, error) {
+	if len(videos) == 0 {
+		return p.Status(), ErrNoTrack
+	}
+	if index < 0 || index >= len(videos) {
+		return p.Status(), fmt.Errorf("index %d is outside the queue of %d tracks", index, len(videos))
+	}
+	p.mu.Lock()
+	p.queue = append([]yt.Video(nil), videos...)
+	p.index = -1
+	p.mu.Unlock()
+	return p.playFrom(index, 1)
+}
+
+// Add appends videos to the queue, starting the first of them when nothing
+// is playing
+func (p *Player) Add(videos []yt.Video) (Status, error) {
+	p.mu.Lock()
+	start := len(p.queue)
+	p.queue = append(p.queue, videos...)
+	idle := p.index < 0 || (!p.loading && !p.audio.IsPlaying() && !p.audio.IsPaused())
+	p.mu.Unlock()
+
+	if idle && len(videos) > 0 {
+		return p.playFrom(start, 1)
+	}
+	return p.Status(), nil
+}
+
+// Resume continues a paused track, or starts the current one when stopped
+func (p *Player) Resume() (Status, error) {
+	if p.audio.IsPaused() {
+		p.audio.Play()
+		return p.Status(), nil
+	}
+	if p.audio.IsPlaying() {
+		return p.Status(), nil
+	}
+	p.mu.Lock()
+	index := max(p.index, 0)
+	p.mu.Unlock()
+	return p.playFrom(index, 1)
+}
+
+// Pause pauses the current track
+func (p *Player) Pause() Status {
+	p.audio.Pause()
+	return p.Status()
+}
+
+// Toggle pauses a playing track and resumes otherwise, for media keys
+func (p *Player) Toggle() (Status, error) {
+	if p.audio.IsPlaying() {
+		return p.Pause(), nil
+	}
+	return p.Resume()
+}
+
+// Next plays the next playable track
+func (p *Player) Next() (Status, error) {
+	p.mu.Lock()
+	index := p.index + 1
+	p.mu.Unlock()
+	return p.playFrom(index, 1)
+}
+
+// Prev plays the previous playable track, or restarts the current one
+// once it has played for a few seconds or is the first
+func (p *Player) Prev() (Status, error) {
+	p.mu.Lock()
+	index := p.index
+	p.mu.Unlock()
+	if p.audio.Position() < restartThreshold && index > 0 {
+		if status, err := p.playFrom(index-1, -1); !errors.Is(err, ErrNoTrack) {
+			return status, err
+		}
+	}
+	return p.playFrom(max(index, 0), 1)
+}
+
+// Stop stops playback, keeping the queue
+func (p *Player) Stop() Status {
+	p.audio.Stop()
+	return p.Status()
+}
+
+// Queue returns the queued tracks
+func (p *Player) Queue() []yt.Video {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]yt.Video(nil), p.queue...)
+}
+
+// Status returns what the player is doing
+func (p *Player) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := Status{
+		State:  StateStopped,
+		Index:  p.index,
+		Queue:  len(p.queue),
+		Played: p.played,
+		Ended:  p.ended,
+	}
+	if p.index >= 0 && p.index < len(p.queue) {
+		current := p.queue[p.index]
+		status.Current = &current
+	}
+	if p.lastErr != nil {
+		status.Error = p.lastErr.Error()
+	}
+	switch {
+	case p.loading:
+		status.State = StateLoading
+	case p.audio.IsPlaying():
+		status.State = StatePlaying
+	case p.audio.IsPaused():
+		status.State = StatePaused
+	}
+	if status.State == StatePlaying || status.State == StatePaused {
+		status.Position = p.audio.Position()
+		status.Live = p.audio.IsLive()
+	}
+	return status
+}
+
+// playFrom plays the first playable track from index on in the given
+// direction. A track that fails to start is skipped.
+func (p *Player) playFrom(index, step int) (Status, error) {
+	// Stopping first aborts a track still being resolved, which would
+	// otherwise hold playMu until it starts only to be replaced
+	p.audio.Stop()
+	p.playMu.Lock()
+	defer p.playMu.Unlock()
+
+	var errs []error
+	for ; ; index += step {
+		p.mu.Lock()
+		if index < 0 || index >= len(p.queue) {
+			p.mu.Unlock()
+			break
+		}
+		video := p.queue[index]
+		if !video.Playable() {
+			p.mu.Unlock()
+			continue
+		}
+		p.index = index
+		p.loading = true
+		p.mu.Unlock()
+
+		url := video.URL
+		if url == "" {
+			url = yt.VideoURL(video.ID)
+		}
+		err := p.audio.PlayStream(url)
+		if errors.Is(err, context.Canceled) {
+			// Stopped, or skipped to another track, while resolving
+			p.mu.Lock()
+			p.loading = false
+			p.mu.Unlock()
+			return p.Status(), nil
+		}
+
+		p.mu.Lock()
+		p.loading = false
+		p.lastErr = err
+		if err == nil {
+			p.played++
+			p.ended = false
+		}
+		p.mu.Unlock()
+
+		if err == nil {
+			if p.history != nil {
+				if err := p.history.Add(video); err != nil {
+					log.Printf("Warning: %v", err)
+				}
+			}
+			return p.Status(), nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", url, err))
+	}
+
+	if len(errs) > 0 {
+		return p.Status(), errors.Join(errs...)
+	}
+	return p.Status(), ErrNoTrack
+}
@@ -0,0 +1,229 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const (
+	appDirName     = "gplay"
+	socketFileName = "daemon.sock"
+
+	// serviceName prefixes the RPC methods, e.g. "Player.Status"
+	serviceName = "Player"
+)
+
+// ErrNotRunning is returned by Dial when no daemon listens on the socket
+var ErrNotRunning = errors.New("gplay daemon is not running (start it with 'gplay daemon')")
+
+// DefaultSocketPath returns the daemon socket location: in the user's
+// runtime directory when there is one, otherwise in a per-user directory
+// under the temporary directory
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, appDirName, socketFileName)
+	}
+	return filepath.Join(os.TempDir(), appDirName+"-"+strconv.Itoa(os.Getuid()), socketFileName)
+}
+
+// None is the argument of methods that take none
+type None struct{}
+
+// PlayArgs replaces the queue and starts the track at Index
+type PlayArgs struct {
+	Videos []yt.Video `json:"videos"`
+	Index  int        `json:"index"`
+}
+
+// AddArgs appends tracks to the queue
+type AddArgs struct {
+	Videos []yt.Video `json:"videos"`
+}
+
+// QueueReply lists the queue and where playback is in it
+type QueueReply struct {
+	Videos []yt.Video `json:"videos"`
+	Index  int        `json:"index"`
+}
+
+// Service exposes a Player over RPC. Every method that changes playback
+// replies with the resulting status.
+type Service struct {
+	player *Player
+}
+
+func (s *Service) Play(args PlayArgs, reply *Status) (err error) {
+	*reply, err = s.player.Play(args.Videos, args.Index)
+	return err
+}
+
+func (s *Service) Add(args AddArgs, reply *Status) (err error) {
+	*reply, err = s.player.Add(args.Videos)
+	return err
+}
+
+func (s *Service) Resume(_ None, reply *Status) (err error) {
+	*reply, err = s.player.Resume()
+	return err
+}
+
+func (s *Service) Pause(_ None, reply *Status) error {
+	*reply = s.player.Pause()
+	return nil
+}
+
+func (s *Service) Toggle(_ None, reply *Status) (err error) {
+	*reply, err = s.player.Toggle()
+	return err
+}
+
+func (s *Service) Next(_ None, reply *Status) (err error) {
+	*reply, err = s.player.Next()
+	return err
+}
+
+func (s *Service) Prev(_ None, reply *Status) (err error) {
+	*reply, err = s.player.Prev()
+	return err
+}
+
+func (s *Service) Stop(_ None, reply *Status) error {
+	*reply = s.player.Stop()
+	return nil
+}
+
+func (s *Service) Status(_ None, reply *Status) error {
+	*reply = s.player.Status()
+	return nil
+}
+
+func (s *Service) Queue(_ None, reply *QueueReply) error {
+	*reply = QueueReply{Videos: s.player.Queue(), Index: s.player.Status().Index}
+	return nil
+}
+
+// Listen opens the control socket at path. A socket left behind by a
+// daemon that died is replaced, but a live daemon on it is an error. The
+// socket's directory must belong to the user and be private to them.
+func Listen(path string) (net.Listener, error) {
+	if client, err := Dial(path); err == nil {
+		client.Close()
+		return nil, fmt.Errorf("a gplay daemon is already listening on %s", path)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating socket directory: %w", err)
+	}
+	// Anyone who can connect controls playback, and another user could
+	// have created the directory first
+	if err := checkPrivateDir(dir); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error removing stale socket: %w", err)
+	}
+
+	listener, err := listenPrivate(path)
+	if err != nil {
+		return nil, fmt.Errorf("error listening on %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// Serve answers JSON-RPC 1.0 requests for p on listener until ctx is done,
+// then closes it
+func Serve(ctx context.Context, listener net.Listener, p *Player) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(serviceName, &Service{player: p}); err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("error accepting connection: %w", err)
+		}
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// Client controls a daemon over its socket
+type Client struct {
+	rpc *rpc.Client
+}
+
+// Dial connects to the daemon listening at path
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotRunning, err)
+	}
+	return &Client{rpc: jsonrpc.NewClient(conn)}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// Play replaces the queue with videos and starts the one at index
+func (c *Client) Play(videos []yt.Video, index int) (Status, error) {
+	return c.call("Play", PlayArgs{Videos: videos, Index: index})
+}
+
+// Add appends videos to the queue
+func (c *Client) Add(videos []yt.Video) (Status, error) {
+	return c.call("Add", AddArgs{Videos: videos})
+}
+
+// Resume continues playback
+func (c *Client) Resume() (Status, error) { return c.call("Resume", None{}) }
+
+// Pause pauses playback
+func (c *Client) Pause() (Status, error) { return c.call("Pause", None{}) }
+
+// Toggle pauses or resumes playback
+func (c *Client) Toggle() (Status, error) { return c.call("Toggle", None{}) }
+
+// Next plays the next track
+func (c *Client) Next() (Status, error) { return c.call("Next", None{}) }
+
+// Prev plays the previous track
+func (c *Client) Prev() (Status, error) { return c.call("Prev", None{}) }
+
+// Stop stops playback
+func (c *Client) Stop() (Status, error) { return c.call("Stop", None{}) }
+
+// Status returns what the daemon is playing
+func (c *Client) Status() (Status, error) { return c.call("Status", None{}) }
+
+// Queue returns the queued tracks and the index of the current one
+func (c *Client) Queue() ([]yt.Video, int, error) {
+	var reply QueueReply
+	if err := c.rpc.Call(serviceName+".Queue", None{}, &reply); err != nil {
+		return nil, -1, err
+	}
+	return reply.Videos, reply.Index, nil
+}
+
+func (c *Client) call(method string, args any) (Status, error) {
+	var status Status
+	err := c.rpc.Call(serviceName+"."+method, args, &status)
+	return status, err
+}
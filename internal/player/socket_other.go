@@ -0,0 +1,15 @@
+//go:build !unix
+
+package player
+
+import "net"
+
+// checkPrivateDir has no ownership to check outside unix; the directory is
+// created with the user's default access
+func checkPrivateDir(dir string) error {
+	return nil
+}
+
+func listenPrivate(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package player
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// checkPrivateDir makes sure dir is a real directory owned by the current
+// user with mode 0700
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("error checking socket directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("socket directory %s is not a directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("socket directory %s is owned by uid %d, not by you", dir, stat.Uid)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		return fmt.Errorf("socket directory %s has mode %#o, want 0700", dir, perm)
+	}
+	return nil
+}
+
+// listenPrivate creates the socket with no access for group and others, so
+// there is no window before a chmod in which they could connect
+func listenPrivate(path string) (net.Listener, error) {
+	old := syscall.Umask(0o077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,67 @@
+//go:build unix
+
+package player
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListenCreatesPrivateSocket(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "gplay")
+	path := filepath.Join(dir, socketFileName)
+
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Errorf("directory mode = %#o, want 0700", perm)
+	}
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		t.Errorf("socket mode = %#o, want no access for group and others", perm)
+	}
+}
+
+func TestListenRejectsSharedDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "gplay")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0o777); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Listen(filepath.Join(dir, socketFileName))
+	if err == nil || !strings.Contains(err.Error(), "want 0700") {
+		t.Errorf("error = %v, want the directory mode rejected", err)
+	}
+}
+
+func TestListenRejectsSymlinkedDir(t *testing.T) {
+	base := t.TempDir()
+	target := filepath.Join(base, "elsewhere")
+	if err := os.Mkdir(target, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(base, "gplay")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Listen(filepath.Join(link, socketFileName)); err == nil {
+		t.Error("expected a symlinked socket directory to be rejected")
+	}
+}
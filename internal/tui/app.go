@@ -1,12 +1,16 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"strings"
 
+	"github.com/alanpramil7/gplay/internal/config"
+	"github.com/alanpramil7/gplay/internal/lyrics"
 	"github.com/alanpramil7/gplay/internal/yt"
 	"github.com/alanpramil7/gplay/internal/yt/services"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,78 +18,41 @@ import (
 )
 
 const (
-	defaultPlaylistID = "PLdavpelzZMWVhADtPAMJWzGrT0OKVpDAp"
-	defaultMaxResults = int64(100)
-	searchCharLimit   = 100
-	searchWidth       = 50
+	playlistPageSize    = int64(50) // API maximum, every page is loaded
+	searchPageSize      = int64(10)
+	channelUploadsLimit = 50
+	searchCharLimit     = 100
+	searchWidth         = 50
+	// maxEmptyPages is how many pages in a row are loaded on their own
+	// while the exclude rules or the list filter keep every result out
+	maxEmptyPages = 3
 )
 
-// UI color constants
-const (
-	colorPrimary   = "#00D9FF"
-	colorSecondary = "#BD93F9"
-	colorText      = "#F8F8F2"
-	colorMuted     = "#6272A4"
-	colorBorder    = "#3C3C3C"
-	colorError     = "#FF5555"
-	colorWarning   = "#FFB86C"
-	colorSuccess   = "#50FA7B"
-	colorPaused    = "#F1FA8C"
-	colorHelp      = "#626262"
-)
-
-// Styles for the UI with modern transparent design
-var (
-	leftPanelStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(colorBorder)).
-			Padding(0, 1)
-
-	rightPanelStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(colorBorder)).
-			Padding(0, 1)
-
-	modalStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(colorPrimary)).
-			Padding(1, 3).
-			Margin(1, 0)
-
-	modalTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorPrimary)).
-			Bold(true).
-			MarginBottom(1)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorHelp))
-
-	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorPrimary)).
-			Bold(true).
-			MarginBottom(1).
-			PaddingLeft(1)
-
-	emptyStateStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorHelp)).
-			Italic(true).
-			Align(lipgloss.Center).
-			MarginTop(2)
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorError)).
-			Bold(true)
+// Deps are the services the TUI needs that the caller builds from the
+// config
+type Deps struct {
+	// Connect creates the search backend from the config as it is when
+	// called, so an API key entered in the TUI takes effect
+	Connect func(maxResults int64) (*services.Backend, error)
+	// Quota, when set, is checked for the status bar warning
+	Quota *yt.QuotaTracker
+	// Lyrics finds lyrics for the lyrics pane; LyricsErr explains why
+	// there is no provider
+	Lyrics    lyrics.Provider
+	LyricsErr error
+	// SocketPath is where a running 'gplay daemon' listens
+	SocketPath string
+}
 
-	loadingStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorWarning)).
-			Bold(true)
-)
+// NewApp creates a new TUI application instance. It never fails: when the
+// YouTube API is unavailable the app starts without it and reports the
+// problem inline.
+func NewApp(cfg *config.Config, cfgPath string, deps Deps) *AppModel {
+	applyTheme(cfg.Theme)
 
-// NewApp creates a new TUI application instance
-func NewApp() *AppModel {
 	// Initialize text input
 	searchInput := textinput.New()
-	searchInput.Placeholder = "Enter the song name..."
+	searchInput.Placeholder = "Enter a song name or paste a URL..."
 	searchInput.Focus()
 	searchInput.CharLimit = searchCharLimit
 	searchInput.Width = searchWidth
@@ -96,35 +63,51 @@ func NewApp() *AppModel {
 	resultsViewport := viewport.New(0, 0)
 	resultsViewport.MouseWheelEnabled = true
 
-	// Initialize YouTube client
-	client, err := yt.NewClient()
-	if err != nil {
-		log.Fatalf("Failed to create YouTube client: %v", err)
-	}
-
 	// Initialize services
-	audioService := services.NewAudioService()
-	playlistService := services.NewPlaylistService(client)
-
-	// Load initial playlist
-	initialResults, err := playlistService.GetPlaylistItems(defaultPlaylistID, defaultMaxResults)
-	if err != nil {
-		log.Fatalf("Failed to load initial playlist: %v", err)
-	}
+	audioService := services.NewAudioServiceWithOptions(services.AudioOptions{
+		SampleRate: cfg.Audio.SampleRate,
+		Channels:   cfg.Audio.Channels,
+		BufferSize: cfg.Audio.BufferSize,
+		Format:     cfg.Audio.Format,
+		YtDlpPath:  cfg.YtDlpPath,
+	})
 
 	app := &AppModel{
 		state:         StateNormal,
-		client:        client,
 		searchInput:   searchInput,
+		apiKeyInput:   newAPIKeyInput(),
 		results:       resultsViewport,
-		searchResults: initialResults,
+		listTitle:     "Search Results",
 		searchMode:    SearchModeQuery,
 		selected:      0,
 		isLoadingSong: false,
-
-		AudioService:    audioService,
-		PlaylistService: playlistService,
+		config:        cfg,
+		configPath:    cfgPath,
+		deps:          deps,
+		quota:         deps.Quota,
+		keys:          newKeyMap(cfg.Keys),
+		filterInputs:  newFilterInputs(),
+		commandInput:  newCommandInput(),
+		playlistInput: newPlaylistInput(),
+		radioOn:       cfg.Radio.Enabled,
+		filters: yt.SearchConfig{
+			RegionCode:        cfg.Search.RegionCode,
+			RelevanceLanguage: cfg.Search.RelevanceLanguage,
+		},
+
+		AudioService: audioService,
+		playback:     audioService,
 	}
+	app.connectDaemon()
+	app.lyricsProvider, app.lyricsConfigErr = deps.Lyrics, deps.LyricsErr
+	app.filterInputs[filterRegion].SetValue(cfg.Search.RegionCode)
+	app.filterInputs[filterLanguage].SetValue(cfg.Search.RelevanceLanguage)
+
+	// The search backend is optional; without it the app still plays from
+	// history
+	app.connect()
+
+	app.loadHistory()
 
 	// Set up completion callback (kept for compatibility)
 	audioService.SetOnComplete(func() {
@@ -136,11 +119,18 @@ func NewApp() *AppModel {
 }
 
 func (m *AppModel) Init() tea.Cmd {
-	return m.listenForSongCompletion()
+	if m.daemon != nil {
+		return tea.Batch(m.pollDaemon(), m.loadInitialPlaylist())
+	}
+	return tea.Batch(m.listenForSongCompletion(), m.loadInitialPlaylist())
 }
 
-// listenForSongCompletion returns a command that listens for song completion
+// listenForSongCompletion returns a command that listens for song
+// completion. A daemon is polled instead, see followDaemon.
 func (m *AppModel) listenForSongCompletion() tea.Cmd {
+	if m.daemon != nil {
+		return nil
+	}
 	return func() tea.Msg {
 		<-m.AudioService.GetSongCompleteChannel()
 		return songCompleteMsg{}
@@ -161,6 +151,12 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateResultsViewport()
 
 	case tea.KeyMsg:
+		// Errors, notices and warnings stay on screen until the next key
+		// press; the daemon poll and lyrics ticks redraw without clearing
+		// them
+		m.err = nil
+		m.notice = ""
+		m.warning = ""
 		switch m.state {
 		case StateNormal:
 			return m.handleNormalKeys(msg)
@@ -168,38 +164,151 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleSearchInputKeys(msg)
 		case StateLoading:
 			return m.handleLoadingKeys(msg)
+		case StateDoctor:
+			return m.handleDoctorKeys(msg)
+		case StateAPIKeyInput:
+			return m.handleAPIKeyKeys(msg)
+		case StateFilters:
+			return m.handleFilterKeys(msg)
+		case StateCommand:
+			return m.handleCommandKeys(msg)
+		case StatePlaylists:
+			return m.handlePlaylistKeys(msg)
+		}
+
+	case warningMsg:
+		m.warning = string(msg)
+
+	case myPlaylistsMsg:
+		if !m.isCurrent(msg.id) {
+			break
+		}
+		m.myPlaylists = msg.playlists
+		m.playlistCursor = min(m.playlistCursor, max(len(msg.playlists)-1, 0))
+		m.playlistPrompt = ""
+		m.playlistConfirm = ""
+		m.notice = msg.notice
+		m.state = StatePlaylists
+
+	case addedToPlaylistMsg:
+		if !m.isCurrent(msg.id) {
+			break
+		}
+		m.state = StateNormal
+		m.notice = fmt.Sprintf("Added %q to %s", msg.video, msg.playlist)
+
+	case playlistEditedMsg:
+		if !m.isCurrent(msg.id) {
+			break
+		}
+		m.state = StateNormal
+		m.notice = msg.notice
+		if msg.removed != "" {
+			m.dropResult(msg.removed)
+		}
+
+	case filtersAppliedMsg:
+		if !m.isCurrent(msg.id) {
+			break
+		}
+		m.filters = msg.config
+		m.state = StateNormal
+		// Redo the last query search with the new filters
+		if m.lastQuery != "" && m.searchMode == SearchModeQuery {
+			m.state = StateLoading
+			m.loadingText = "Searching YouTube..."
+			m.nextPageToken = ""
+			return m, m.performSearch(m.lastQuery)
+		}
+
+	case doctorCompleteMsg:
+		if !m.isCurrent(msg.id) {
+			break
 		}
+		m.doctorResults = msg.results
+		m.state = StateDoctor
 
 	case searchCompleteMsg:
+		m.refreshQuota()
+		if !m.isCurrent(msg.id) {
+			// Superseded or cancelled
+			break
+		}
 		m.state = StateNormal
-		m.searchResults = msg
-		m.selected = 0
-		m.updateResultsViewport()
+		m.isLoadingList = false
+		m.listTitle = msg.title
+		m.nextPageToken = msg.nextPageToken
+		m.emptyPages = 0
+		m.setResults(msg.results)
+		m.editPlaylist = msg.playlist
+		if msg.play && len(m.searchResults) > 0 {
+			m.selectedItem = &m.searchResults[0]
+			m.isLoadingSong = true
+			return m, m.playSelectedSong()
+		}
+		return m, m.loadPastEmptyPage(0)
+
+	case searchMoreMsg:
+		m.refreshQuota()
+		if !m.isCurrentBackground(msg.id) {
+			// Results belong to a search that has since been replaced
+			break
+		}
+		m.isLoadingMore = false
+		shown := len(m.searchResults)
+		m.allResults = append(m.allResults, msg.results...)
+		m.nextPageToken = msg.nextPageToken
+		m.refreshResults()
+		return m, m.loadPastEmptyPage(shown)
 
 	case searchErrorMsg:
+		m.refreshQuota()
+		if !m.isCurrent(msg.id) {
+			break
+		}
 		m.state = StateNormal
-		m.err = msg
+		m.isLoadingList = false
+		m.err = m.explainError(msg.err)
+
+	case backgroundErrorMsg:
+		m.refreshQuota()
+		if !m.isCurrentBackground(msg.id) {
+			break
+		}
+		m.isLoadingMore = false
+		m.isLoadingRadio = false
+		m.radioWaiting = false
+		m.err = m.explainError(msg.err)
+
+	case radioMsg:
+		if !m.isCurrentBackground(msg.id) {
+			break
+		}
+		return m, m.addRadioTracks(msg)
 
 	case songLoadCompleteMsg:
 		m.isLoadingSong = false
 		// Continue listening for song completion
-		return m, m.listenForSongCompletion()
+		return m, tea.Batch(m.listenForSongCompletion(), m.loadLyrics())
+
+	case lyricsMsg:
+		m.setLyrics(msg)
+
+	case lyricsTickMsg:
+		m.lyricsTicking = false
+		if m.lyricsOn {
+			return m, m.tickLyrics()
+		}
 
 	case songLoadErrorMsg:
 		m.isLoadingSong = false
 		m.err = msg.error
 
 	case songCompleteMsg:
-		// Song completed naturally, play next song
-		if m.selected < len(m.searchResults)-1 {
-			m.selected++
-			m.selectedItem = &m.searchResults[m.selected]
-			m.updateResultsViewport()
-			m.isLoadingSong = true
-			return m, m.playSelectedSong()
-		}
-		// No more songs, continue listening for completion
-		return m, m.listenForSongCompletion()
+		return m, m.songComplete()
+
+	case daemonStatusMsg:
+		return m, m.followDaemon(msg)
 
 	default:
 		var cmd tea.Cmd
@@ -210,42 +319,48 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *AppModel) handleNormalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "ctrl+c":
-		// Stop audio before quitting
-		m.AudioService.Stop()
-		return m, tea.Quit
-	case "/":
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, m.quit()
+	case key.Matches(msg, m.keys.Search):
 		m.state = StateSearchInput
 		m.searchInput.SetValue("")
 		m.searchInput.Focus()
 		return m, textinput.Blink
-	case "up", "k":
+	case key.Matches(msg, m.keys.Up):
 		if m.selected > 0 {
 			m.selected--
 			m.updateResultsViewport()
 		}
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		if m.selected < len(m.searchResults)-1 {
 			m.selected++
 			m.updateResultsViewport()
 		}
-	case "s":
+		return m, m.maybeLoadMore()
+	case msg.String() == "s":
 		// Suffle playlist
-	case "enter":
+	case key.Matches(msg, m.keys.Play):
 		if len(m.searchResults) > 0 && m.selected >= 0 && m.selected < len(m.searchResults) {
+			// Region and age restrictions may not apply to yt-dlp, so only
+			// videos that are gone are refused
+			switch availability := m.searchResults[m.selected].Availability; availability {
+			case yt.AvailabilityPrivate, yt.AvailabilityDeleted:
+				m.err = fmt.Errorf("this video is %s", availability)
+				return m, nil
+			}
 			m.selectedItem = &m.searchResults[m.selected]
 			m.isLoadingSong = true
-			return m, m.playSelectedSong()
+			return m, tea.Batch(m.playSelectedSong(), m.maybeLoadRadio())
 		}
 
-	case " ":
-		if m.AudioService.IsPlaying() {
-			m.AudioService.Pause()
+	case key.Matches(msg, m.keys.Pause):
+		if m.playback.IsPlaying() {
+			m.playback.Pause()
 		} else {
 			if m.selectedItem != nil {
-				if m.AudioService.GetCurrentSong() == m.selectedItem.URL {
-					m.AudioService.Play()
+				if m.playback.GetCurrentSong() == m.selectedItem.URL {
+					m.playback.Play()
 				} else {
 					m.isLoadingSong = true
 					return m, m.playSelectedSong()
@@ -256,8 +371,58 @@ func (m *AppModel) handleNormalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, m.playSelectedSong()
 			}
 		}
-	case "x":
-		m.AudioService.Stop()
+	case key.Matches(msg, m.keys.Stop):
+		m.playback.Stop()
+		m.isLoadingSong = false
+	case key.Matches(msg, m.keys.APIKey):
+		m.state = StateAPIKeyInput
+		m.apiKeyInput.SetValue("")
+		m.apiKeyInput.Focus()
+		return m, textinput.Blink
+	case key.Matches(msg, m.keys.History):
+		m.showHistory()
+	case key.Matches(msg, m.keys.Filters):
+		return m, m.openFilters()
+	case key.Matches(msg, m.keys.Command):
+		return m, m.openCommand()
+	case key.Matches(msg, m.keys.Radio):
+		return m, m.toggleRadio()
+	case key.Matches(msg, m.keys.Lyrics):
+		return m, m.toggleLyrics()
+	case key.Matches(msg, m.keys.LyricsEarlier):
+		m.shiftLyrics(lyricsOffsetStep)
+	case key.Matches(msg, m.keys.LyricsLater):
+		m.shiftLyrics(-lyricsOffsetStep)
+	case key.Matches(msg, m.keys.MyPlaylists):
+		m.state = StateLoading
+		m.loadingText = "Loading your playlists..."
+		return m, m.openMyPlaylists(nil)
+	case key.Matches(msg, m.keys.Liked):
+		m.state = StateLoading
+		m.loadingText = "Loading liked videos..."
+		return m, m.showLiked()
+	case key.Matches(msg, m.keys.AddToPlaylist):
+		if track := m.currentTrack(); track != nil {
+			m.state = StateLoading
+			m.loadingText = "Loading your playlists..."
+			return m, m.openMyPlaylists(track)
+		}
+	case key.Matches(msg, m.keys.RemoveFromPlaylist):
+		if m.editPlaylist != nil && m.selected >= 0 && m.selected < len(m.searchResults) {
+			m.state = StateLoading
+			m.loadingText = "Removing from " + m.editPlaylist.Title + "..."
+			return m, m.removeFromPlaylist(m.searchResults[m.selected], *m.editPlaylist)
+		}
+	case key.Matches(msg, m.keys.Channel):
+		if m.selected >= 0 && m.selected < len(m.searchResults) {
+			m.state = StateLoading
+			m.loadingText = "Loading channel..."
+			return m, m.openChannel(m.searchResults[m.selected])
+		}
+	case key.Matches(msg, m.keys.Doctor):
+		m.state = StateLoading
+		m.loadingText = "Running diagnostics..."
+		return m, m.runDoctor()
 	}
 	return m, nil
 }
@@ -270,10 +435,10 @@ func (m *AppModel) handleSearchInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		switch m.searchMode {
 		case SearchModeQuery:
 			m.searchMode = SearchModePlaylist
-			m.searchInput.Placeholder = "Enter the Playlidt ID..."
+			m.searchInput.Placeholder = "Enter a playlist ID or URL..."
 		case SearchModePlaylist:
 			m.searchMode = SearchModeQuery
-			m.searchInput.Placeholder = "Enter the song name..."
+			m.searchInput.Placeholder = "Enter a song name or paste a URL..."
 		}
 		return m, nil
 	case "esc":
@@ -288,7 +453,10 @@ func (m *AppModel) handleSearchInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.state = StateLoading
+		m.loadingText = "Searching YouTube..."
 		m.searchInput.Blur()
+		m.lastQuery = query
+		m.nextPageToken = ""
 		return m, m.performSearch(query)
 	default:
 		var cmd tea.Cmd
@@ -298,8 +466,13 @@ func (m *AppModel) handleSearchInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *AppModel) handleLoadingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	if msg.String() == "ctrl+c" {
+	switch msg.String() {
+	case "ctrl+c":
+		m.cancelPending()
 		return m, tea.Quit
+	case "esc":
+		m.cancelPending()
+		m.state = StateNormal
 	}
 	return m, nil
 }
@@ -310,46 +483,232 @@ type songLoadErrorMsg struct {
 }
 
 func (m *AppModel) playSelectedSong() tea.Cmd {
+	// Whatever plays now replaces the track radio was waiting to start
+	m.radioWaiting = false
+	if m.daemon != nil && m.selectedItem != nil {
+		// The daemon records the history itself
+		daemon := m.daemon
+		videos := m.upNext()
+		return func() tea.Msg {
+			if err := daemon.play(videos); err != nil {
+				return songLoadErrorMsg{err}
+			}
+			return songLoadCompleteMsg{}
+		}
+	}
 	return func() tea.Msg {
 		if m.selectedItem == nil {
 			return songLoadErrorMsg{fmt.Errorf("no song selected")}
 		}
+		item := *m.selectedItem
 
-		err := m.AudioService.PlayStream(m.selectedItem.URL)
+		err := m.AudioService.PlayStream(item.URL)
+		if errors.Is(err, context.Canceled) {
+			// Stopped, or another track was started, while resolving
+			return nil
+		}
 		if err != nil {
 			return songLoadErrorMsg{err}
 		}
 
+		if m.history != nil {
+			if err := m.history.Add(item); err != nil {
+				return songLoadErrorMsg{err}
+			}
+		}
+
 		return songLoadCompleteMsg{}
 	}
 }
 
+// songComplete moves on once a track has finished naturally
+func (m *AppModel) songComplete() tea.Cmd {
+	if m.nextPlayable() >= 0 {
+		return m.playNext()
+	}
+	// Out of songs; radio may still be fetching some and plays the first
+	// once they arrive
+	if cmd := m.maybeLoadRadio(); cmd != nil || m.isLoadingRadio {
+		m.radioWaiting = true
+		return cmd
+	}
+	// No more songs, continue listening for completion
+	return m.listenForSongCompletion()
+}
+
+// nextPlayable returns the index of the next track after the selection
+// that can be played, or -1 when there is none
+func (m *AppModel) nextPlayable() int {
+	for i := m.selected + 1; i < len(m.searchResults); i++ {
+		if m.searchResults[i].Playable() {
+			return i
+		}
+	}
+	return -1
+}
+
+// playNext advances the selection to the next playable track and plays it,
+// loading more tracks when the list is about to run out. Callers check
+// nextPlayable first.
+func (m *AppModel) playNext() tea.Cmd {
+	m.selected = m.nextPlayable()
+	m.selectedItem = &m.searchResults[m.selected]
+	m.updateResultsViewport()
+	m.isLoadingSong = true
+	return tea.Batch(m.playSelectedSong(), m.maybeLoadMore(), m.maybeLoadRadio())
+}
+
 func (m *AppModel) performSearch(query string) tea.Cmd {
+	ctx, id := m.startRequest()
+	mode := m.searchMode
+
 	return func() tea.Msg {
-		switch m.searchMode {
+		if m.SearchService == nil {
+			return searchErrorMsg{id, m.apiUnavailableError()}
+		}
+
+		switch mode {
 		case SearchModeQuery:
+			// A pasted URL opens what it points at rather than searching
+			if ref, err := yt.ParseURL(query); err == nil {
+				return m.openRef(ctx, id, ref)
+			}
 
-			service := services.NewSearchService(m.client, 10)
-			response, err := service.Search(query)
+			response, err := m.SearchService.SearchWithConfigContext(ctx, query, m.searchConfig())
 			if err != nil {
-				return searchErrorMsg(fmt.Errorf("search failed: %w", err))
+				return searchErrorMsg{id, fmt.Errorf("search failed: %w", err)}
 			}
-			return searchCompleteMsg(response.Videos)
+			return searchCompleteMsg{id: id, title: "Search Results", results: response.Videos, nextPageToken: response.NextPageToken}
 		case SearchModePlaylist:
-			results, err := m.PlaylistService.GetPlaylistItems(query, defaultMaxResults)
+			playlistID, err := yt.ParsePlaylistID(query)
+			if err != nil {
+				return searchErrorMsg{id, err}
+			}
+			results, err := m.PlaylistService.GetPlaylistItemsContext(ctx, playlistID, playlistPageSize)
 			if err != nil {
-				return searchErrorMsg(fmt.Errorf("search failed: %w", err))
+				return searchErrorMsg{id, fmt.Errorf("search failed: %w", err)}
 			}
-			return searchCompleteMsg(results)
+			return searchCompleteMsg{id: id, title: "Playlist", results: results}
+		}
+		return searchErrorMsg{id, fmt.Errorf("Invalid search mode.")}
+	}
+}
+
+// openRef loads what a pasted URL points at: a playlist, a channel's
+// uploads, or a single video that starts playing right away
+func (m *AppModel) openRef(ctx context.Context, id int, ref yt.Ref) tea.Msg {
+	switch {
+	case ref.PlaylistID != "":
+		results, err := m.PlaylistService.GetPlaylistItemsContext(ctx, ref.PlaylistID, playlistPageSize)
+		if err != nil {
+			return searchErrorMsg{id, fmt.Errorf("loading playlist failed: %w", err)}
 		}
-		return searchErrorMsg(fmt.Errorf("Invalid search mode."))
+		return searchCompleteMsg{id: id, title: "Playlist", results: results}
+	case ref.Kind == yt.RefChannel:
+		return m.channelUploads(ctx, id, ref.ID())
+	}
+	video := yt.SearchResult{ID: ref.VideoID, Title: ref.URL(), URL: ref.URL()}
+	return searchCompleteMsg{id: id, title: "Video", results: []yt.SearchResult{video}, play: true}
+}
+
+// openChannel replaces the results list with the newest uploads of the
+// channel that published item
+func (m *AppModel) openChannel(item yt.SearchResult) tea.Cmd {
+	ctx, id := m.startRequest()
+
+	return func() tea.Msg {
+		if item.ChannelID == "" {
+			return searchErrorMsg{id, fmt.Errorf("%q has no channel to open", item.Title)}
+		}
+		return m.channelUploads(ctx, id, item.ChannelID)
+	}
+}
+
+// channelUploads looks up a channel ID or handle and lists its uploads
+func (m *AppModel) channelUploads(ctx context.Context, id int, ref string) tea.Msg {
+	if m.ChannelService == nil {
+		return searchErrorMsg{id, m.apiUnavailableError()}
+	}
+	channel, err := m.ChannelService.Channel(ctx, ref)
+	if err != nil {
+		return searchErrorMsg{id, fmt.Errorf("loading channel failed: %w", err)}
 	}
+	uploads, err := m.ChannelService.Uploads(ctx, channel, channelUploadsLimit)
+	if err != nil {
+		return searchErrorMsg{id, fmt.Errorf("loading channel failed: %w", err)}
+	}
+	return searchCompleteMsg{id: id, title: channel.Title, results: uploads}
+}
+
+// searchConfig builds a single page search from the configured defaults
+// and the filters set in the filter panel
+func (m *AppModel) searchConfig() *yt.SearchConfig {
+	return m.withFilters(&yt.SearchConfig{
+		MaxResults:    searchPageSize,
+		Order:         m.config.Search.Order,
+		SafeSearch:    m.config.Search.SafeSearch,
+		VideoDuration: m.config.Search.VideoDuration,
+		VideoType:     m.config.Search.VideoType,
+	})
+}
+
+// maybeLoadMore fetches the next page of search results once the selection
+// reaches the bottom of the list. Nothing is loaded while a request is
+// about to replace the list.
+func (m *AppModel) maybeLoadMore() tea.Cmd {
+	if m.SearchService == nil || m.isLoadingMore || m.nextPageToken == "" || m.selected < len(m.searchResults)-1 ||
+		m.state == StateLoading {
+		return nil
+	}
+	ctx, id := m.startBackground()
+	m.isLoadingMore = true
+
+	query := m.lastQuery
+	pageToken := m.nextPageToken
+	return func() tea.Msg {
+		config := m.searchConfig()
+		config.PageToken = pageToken
+		response, err := m.SearchService.SearchWithConfigContext(ctx, query, config)
+		if err != nil {
+			return backgroundErrorMsg{id, fmt.Errorf("loading more results failed: %w", err)}
+		}
+		return searchMoreMsg{id: id, results: response.Videos, nextPageToken: response.NextPageToken}
+	}
+}
+
+// loadPastEmptyPage loads the next page when the last one added nothing
+// to the list, shown being its length before. The exclude rules or the list
+// filter can drop a whole page, leaving no row to scroll to that would load
+// more. Only maxEmptyPages are skipped in a row so a search with nothing to
+// show does not use up the quota; moving down loads further.
+func (m *AppModel) loadPastEmptyPage(shown int) tea.Cmd {
+	if len(m.searchResults) > shown {
+		m.emptyPages = 0
+		return nil
+	}
+	if m.emptyPages >= maxEmptyPages {
+		return nil
+	}
+	m.emptyPages++
+	return m.maybeLoadMore()
 }
 
 func (m *AppModel) updateResultsViewport() {
 	var b strings.Builder
 	for i, r := range m.searchResults {
-		if i == m.selected {
+		if !r.Playable() {
+			// Greyed out, with why it cannot be played in place of the
+			// channel
+			indicator := "  "
+			if i == m.selected {
+				indicator = lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render("▶ ")
+			}
+			title := lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Strikethrough(true).
+				Render(truncate(r.Title, 40))
+			reason := lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Italic(true).
+				Render("⊘ " + string(r.Availability))
+			fmt.Fprintf(&b, "%s%s\n  %s\n", indicator, title, reason)
+		} else if i == m.selected {
 			indicator := lipgloss.NewStyle().Foreground(lipgloss.Color(colorPrimary)).Render("▶ ")
 			title := lipgloss.NewStyle().Foreground(lipgloss.Color(colorPrimary)).Bold(true).
 				Render(truncate(r.Title, 40))
@@ -390,15 +749,39 @@ func (m *AppModel) View() string {
 
 	leftContent := ""
 	if len(m.searchResults) == 0 {
-		emptyMsg := `
-    Press '/' or 's' to search
-    Press 'q' to quit`
+		emptyMsg := fmt.Sprintf(`
+    Press '%s' to search
+    Press '%s' to quit`, m.keys.Search.Help().Key, m.keys.Quit.Help().Key)
+		if m.isLoadingList {
+			emptyMsg = "\n    Loading playlist..."
+		} else if m.apiErr != nil {
+			emptyMsg = fmt.Sprintf(`
+    YouTube API unavailable:
+    %s
+
+    Press '%s' to enter an API key
+    Press '%s' to play from history
+    Press '%s' for diagnostics`, m.apiErr, m.keys.APIKey.Help().Key, m.keys.History.Help().Key, m.keys.Doctor.Help().Key)
+		}
 		leftContent = emptyStateStyle.
 			Width(leftWidth - 4).
 			Height(panelHeight - 4).
 			Render(emptyMsg)
 	} else {
-		title := titleStyle.Render("Search Results")
+		listTitle := m.listTitle
+		tracks := fmt.Sprintf("%d tracks", len(m.searchResults))
+		if len(m.searchResults) != len(m.allResults) {
+			tracks = fmt.Sprintf("%d of %d tracks", len(m.searchResults), len(m.allResults))
+		}
+		if total := yt.TotalLength(m.searchResults); total > 0 {
+			listTitle = fmt.Sprintf("%s  •  %s  •  %s", listTitle, tracks, yt.HumanDuration(total))
+		} else if len(m.searchResults) != len(m.allResults) {
+			listTitle = fmt.Sprintf("%s  •  %s", listTitle, tracks)
+		}
+		if m.radioOn {
+			listTitle += "  •  radio"
+		}
+		title := titleStyle.Render(listTitle)
 		leftContent = title + "\n" + m.results.View()
 	}
 	leftPanel := leftPanelStyle.
@@ -415,12 +798,17 @@ func (m *AppModel) View() string {
 			Foreground(lipgloss.Color(colorWarning)).
 			Bold(true).
 			Render("⏳ LOADING...")
-	} else if m.AudioService.IsPlaying() {
+	} else if m.playback.IsPlaying() && m.playback.IsLive() {
+		statusLine = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(colorError)).
+			Bold(true).
+			Render("● LIVE")
+	} else if m.playback.IsPlaying() {
 		statusLine = lipgloss.NewStyle().
 			Foreground(lipgloss.Color(colorSuccess)).
 			Bold(true).
 			Render("▶ NOW PLAYING")
-	} else if m.selectedItem != nil && m.AudioService.GetCurrentSong() == m.selectedItem.URL {
+	} else if m.selectedItem != nil && m.playback.GetCurrentSong() == m.selectedItem.URL {
 		// Song is loaded but paused
 		statusLine = lipgloss.NewStyle().
 			Foreground(lipgloss.Color(colorPaused)).
@@ -432,7 +820,14 @@ func (m *AppModel) View() string {
 			Render("⏹ STOPPED")
 	}
 
-	if m.selectedItem != nil {
+	if m.lyricsOn {
+		rightTitle = titleStyle.Render("Lyrics")
+		rightContent = m.lyricsView(statusLine, rightWidth-4, panelHeight)
+	} else if m.selectedItem != nil {
+		duration := yt.FormatDuration(m.selectedItem.Length)
+		if m.selectedItem.IsLive() {
+			duration = m.selectedItem.LiveBroadcast
+		}
 		rightContent = fmt.Sprintf(
 			"%s\n\n%s\n\nChannel: %s\n\nVideo ID: %s\n\nDescription: %s\n\nDuration: %s\n\nThumbnail URL: %s\n\nURL: %s",
 			statusLine,
@@ -440,7 +835,7 @@ func (m *AppModel) View() string {
 			lipgloss.NewStyle().Foreground(lipgloss.Color(colorSecondary)).Italic(true).Render(m.selectedItem.ChannelTitle),
 			lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render(m.selectedItem.ID),
 			lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render(truncate(m.selectedItem.Description, 100)),
-			lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render(m.selectedItem.Duration),
+			lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render(duration),
 			lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render(m.selectedItem.ThumbnailURL),
 			lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render(m.selectedItem.URL),
 		)
@@ -462,25 +857,77 @@ func (m *AppModel) View() string {
 		if m.isLoadingSong {
 			helpText = loadingStyle.Render("Loading song...")
 		} else if len(m.searchResults) > 0 {
-			if m.AudioService.IsPlaying() {
-				helpText = "'/' search  •  ↑↓ navigate  •  ↵ play  •  space pause  •  x stop  •  q quit"
-			} else if m.selectedItem != nil && m.AudioService.GetCurrentSong() == m.selectedItem.URL {
-				helpText = "'/' search  •  ↑↓ navigate  •  ↵ play  •  space resume  •  x stop  •  q quit"
-			} else {
-				helpText = "'/' search  •  ↑↓ navigate  •  ↵ play  •  space toggle  •  x stop  •  q quit"
+			pauseAction := "toggle"
+			if m.playback.IsPlaying() {
+				pauseAction = "pause"
+			} else if m.selectedItem != nil && m.playback.GetCurrentSong() == m.selectedItem.URL {
+				pauseAction = "resume"
+			}
+			radioAction := "radio on"
+			if m.radioOn {
+				radioAction = "radio off"
+			}
+			helpText = fmt.Sprintf("'%s' search  •  %s%s navigate  •  %s play  •  %s %s  •  %s stop  •  %s channel  •  %s filters  •  %s filter/sort  •  %s %s  •  %s quit",
+				m.keys.Search.Help().Key, m.keys.Up.Help().Key, m.keys.Down.Help().Key, m.keys.Play.Help().Key,
+				m.keys.Pause.Help().Key, pauseAction, m.keys.Stop.Help().Key, m.keys.Channel.Help().Key, m.keys.Filters.Help().Key, m.keys.Command.Help().Key,
+				m.keys.Radio.Help().Key, radioAction, m.keys.Quit.Help().Key)
+			helpText += fmt.Sprintf("  •  %s lyrics", m.keys.Lyrics.Help().Key)
+			if m.lyricsOn {
+				helpText += fmt.Sprintf("  •  %s%s sync lyrics", m.keys.LyricsEarlier.Help().Key, m.keys.LyricsLater.Help().Key)
+			}
+			if m.LibraryService != nil {
+				helpText += fmt.Sprintf("  •  %s playlists  •  %s liked  •  %s add to playlist",
+					m.keys.MyPlaylists.Help().Key, m.keys.Liked.Help().Key, m.keys.AddToPlaylist.Help().Key)
+			}
+			if m.editPlaylist != nil {
+				helpText += fmt.Sprintf("  •  %s remove from playlist", m.keys.RemoveFromPlaylist.Help().Key)
 			}
 		} else {
-			helpText = "Press '/' or 's' to search  •  Press 'q' to quit"
+			helpText = fmt.Sprintf("Press '%s' to search  •  Press '%s' to quit", m.keys.Search.Help().Key, m.keys.Quit.Help().Key)
 		}
 	case StateLoading:
-		helpText = loadingStyle.Render("Searching YouTube...")
+		helpText = loadingStyle.Render(m.loadingText) + "  •  esc cancel"
+	}
+	if m.isLoadingMore && m.state == StateNormal && !m.isLoadingSong {
+		helpText = loadingStyle.Render("Loading more results...")
+	}
+	if m.isLoadingRadio && m.state == StateNormal && !m.isLoadingSong {
+		helpText = loadingStyle.Render("Finding related tracks...")
 	}
 
+	// The playlist picker shows its own notices
+	if m.notice != "" && m.state != StatePlaylists {
+		helpText = lipgloss.NewStyle().Foreground(lipgloss.Color(colorSuccess)).Render(m.notice)
+	}
+	if m.warning != "" {
+		helpText = loadingStyle.Render("⚠ " + m.warning)
+	}
 	if m.err != nil {
 		helpText = errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
-		m.err = nil
 	}
 	help := helpStyle.Render(helpText)
+	if m.state == StateCommand {
+		help = m.commandInput.View()
+	}
+	if m.quotaWarning != "" {
+		help += helpStyle.Render("  •  ") + loadingStyle.Render("⚠ "+m.quotaWarning)
+	}
+
+	if m.state == StateDoctor {
+		return m.doctorView()
+	}
+
+	if m.state == StateAPIKeyInput {
+		return m.apiKeyView()
+	}
+
+	if m.state == StateFilters {
+		return m.filtersView()
+	}
+
+	if m.state == StatePlaylists {
+		return m.playlistsView()
+	}
 
 	if m.state == StateSearchInput {
 		modeLabel := ""
@@ -490,14 +937,27 @@ func (m *AppModel) View() string {
 			modeLabel = "[ Search Mode: Playlist ]"
 		}
 
+		action := "search"
+		if ref, err := yt.ParseURL(m.searchInput.Value()); err == nil && m.searchMode == SearchModeQuery {
+			action = "open " + ref.Kind.String()
+			if ref.PlaylistID != "" {
+				action = "open playlist"
+			}
+		}
+
 		title := modalTitleStyle.Render("Search YouTube " + modeLabel)
 		input := m.searchInput.View()
 		helperText := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262")).
+			Foreground(lipgloss.Color(colorHelp)).
 			Italic(true).
-			Render("↵ Enter to search  •  ESC to cancel  •  TAB to toggle mode")
+			Render("↵ Enter to " + action + "  •  ESC to cancel  •  TAB to toggle mode")
 
 		modalContent := fmt.Sprintf("%s\n\n%s\n\n%s", title, input, helperText)
+		if filters := m.filters.Filters(); len(filters) > 0 && m.searchMode == SearchModeQuery {
+			active := lipgloss.NewStyle().Foreground(lipgloss.Color(colorSecondary)).
+				Render(fmt.Sprintf("Filters: %s ('%s' to change)", strings.Join(filters, ", "), m.keys.Filters.Help().Key))
+			modalContent += "\n" + active
+		}
 		modal := modalStyle.Render(modalContent)
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
 			lipgloss.WithWhitespaceBackground(lipgloss.NoColor{}))
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/filter"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newCommandInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = ":"
+	input.Placeholder = "filter duration>3m and views>100k  •  sort views:desc"
+	input.CharLimit = searchCharLimit * 2
+	input.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(colorPrimary))
+	input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(colorText))
+	return input
+}
+
+// openCommand shows the command prompt in place of the help line
+func (m *AppModel) openCommand() tea.Cmd {
+	m.state = StateCommand
+	m.commandInput.SetValue("")
+	return m.commandInput.Focus()
+}
+
+func (m *AppModel) handleCommandKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc":
+		m.state = StateNormal
+		m.commandInput.Blur()
+		return m, nil
+	case "enter":
+		m.state = StateNormal
+		m.commandInput.Blur()
+		m.err = m.runCommand(m.commandInput.Value())
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.commandInput, cmd = m.commandInput.Update(msg)
+	return m, cmd
+}
+
+// runCommand executes a line typed at the command prompt
+func (m *AppModel) runCommand(line string) error {
+	name, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "":
+		return nil
+	case "filter":
+		if arg == "" {
+			m.listFilter = nil
+			break
+		}
+		expr, err := filter.Parse(arg)
+		if err != nil {
+			return err
+		}
+		m.listFilter = expr
+	case "sort":
+		if arg == "" {
+			m.listSort = nil
+			break
+		}
+		sort, err := filter.ParseSort(arg)
+		if err != nil {
+			return err
+		}
+		m.listSort = sort
+	default:
+		return fmt.Errorf("unknown command %q (try filter or sort)", name)
+	}
+
+	m.refreshResults()
+	return nil
+}
+
+// setResults replaces the list, applying the active filter and sort
+func (m *AppModel) setResults(results []yt.SearchResult) {
+	m.allResults = results
+	// Nothing to keep selected in a new list
+	m.searchResults = nil
+	m.editPlaylist = nil
+	m.refreshResults()
+}
+
+// refreshResults rebuilds the visible list from allResults, keeping the
+// selection on the same track when it is still shown
+func (m *AppModel) refreshResults() {
+	var selectedURL string
+	if m.selected >= 0 && m.selected < len(m.searchResults) {
+		selectedURL = m.searchResults[m.selected].URL
+	}
+
+	results := append([]yt.SearchResult(nil), m.allResults...)
+	if m.listFilter != nil {
+		results = m.listFilter.Apply(results)
+	}
+	if m.listSort != nil {
+		m.listSort.Apply(results)
+	}
+	m.searchResults = results
+
+	m.selected = 0
+	for i := range m.searchResults {
+		if m.searchResults[i].URL == selectedURL {
+			m.selected = i
+			break
+		}
+	}
+	if m.selectedItem != nil {
+		// The rebuilt list no longer holds the playing track's entry
+		for i := range m.searchResults {
+			if m.searchResults[i].URL == m.selectedItem.URL {
+				m.selectedItem = &m.searchResults[i]
+				break
+			}
+		}
+	}
+	m.updateResultsViewport()
+}
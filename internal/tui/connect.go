@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const apiKeyCharLimit = 100
+
+func newAPIKeyInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "Paste a YouTube Data API key..."
+	input.CharLimit = apiKeyCharLimit
+	input.Width = searchWidth
+	input.EchoMode = textinput.EchoPassword
+	input.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(colorPrimary))
+	input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(colorText))
+	return input
+}
+
+// connect creates the search and playlist services for the configured
+// backend, recording why when that is not possible
+func (m *AppModel) connect() error {
+	backend, err := m.deps.Connect(searchPageSize)
+	if err != nil {
+		m.apiErr = err
+		return err
+	}
+
+	radio, err := services.NewRadio(m.config.Radio.Strategy, backend.Mix, backend.Search)
+	if err != nil {
+		m.apiErr = err
+		return err
+	}
+
+	m.AudioService.SetStreamResolver(backend.Streams)
+	m.SearchService = backend.Search
+	m.PlaylistService = backend.Playlist
+	m.ChannelService = backend.Channel
+	m.LibraryService = backend.Library
+	m.PlaylistEditor = backend.Editor
+	m.radio = radio
+	m.apiErr = nil
+	return nil
+}
+
+// refreshQuota updates the quota warning shown in the status bar. It reads
+// the usage file, so it runs after API activity rather than on every render.
+func (m *AppModel) refreshQuota() {
+	m.quotaWarning = ""
+	if m.quota == nil || !m.quota.NearBudget(m.config.Quota.WarnPercent) {
+		return
+	}
+	usage, err := m.quota.Usage()
+	if err != nil {
+		return
+	}
+	m.quotaWarning = fmt.Sprintf("API quota %d/%d", usage.Total(), m.quota.Budget())
+}
+
+// apiUnavailableError explains why YouTube requests cannot be made
+func (m *AppModel) apiUnavailableError() error {
+	return fmt.Errorf("YouTube API unavailable (press '%s' to enter an API key): %w", m.keys.APIKey.Help().Key, m.apiErr)
+}
+
+// explainError adds what to do about the typed YouTube errors
+func (m *AppModel) explainError(err error) error {
+	switch {
+	case errors.Is(err, yt.ErrKeyInvalid):
+		return fmt.Errorf("%w (press '%s' to enter a new key)", err, m.keys.APIKey.Help().Key)
+	case errors.Is(err, yt.ErrQuotaExceeded), errors.Is(err, yt.ErrQuotaBudgetExceeded):
+		return fmt.Errorf("%w (resets at midnight Pacific time)", err)
+	case errors.Is(err, yt.ErrPrivatePlaylist):
+		return fmt.Errorf("%w (only public and unlisted playlists can be loaded)", err)
+	case errors.Is(err, yt.ErrNotFound):
+		return fmt.Errorf("%w (check the playlist ID)", err)
+	}
+	return err
+}
+
+// loadInitialPlaylist fetches the configured default playlist in the
+// background so the UI appears immediately
+func (m *AppModel) loadInitialPlaylist() tea.Cmd {
+	playlistID := m.config.DefaultPlaylist
+	if m.PlaylistService == nil || playlistID == "" {
+		return nil
+	}
+	ctx, id := m.startRequest()
+	m.isLoadingList = true
+
+	return func() tea.Msg {
+		listID, err := yt.ParsePlaylistID(playlistID)
+		if err != nil {
+			return searchErrorMsg{id, fmt.Errorf("invalid default playlist: %w", err)}
+		}
+		results, err := m.PlaylistService.GetPlaylistItemsContext(ctx, listID, playlistPageSize)
+		if err != nil {
+			return searchErrorMsg{id, fmt.Errorf("failed to load initial playlist: %w", err)}
+		}
+		return searchCompleteMsg{id: id, title: "Playlist", results: results}
+	}
+}
+
+func (m *AppModel) handleAPIKeyKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc":
+		m.state = StateNormal
+		m.apiKeyInput.Blur()
+		return m, nil
+	case "enter":
+		m.state = StateNormal
+		m.apiKeyInput.Blur()
+
+		previousKey := m.config.APIKey
+		m.config.APIKey = strings.TrimSpace(m.apiKeyInput.Value())
+		if err := m.connect(); err != nil {
+			m.config.APIKey = previousKey
+			m.err = err
+			return m, nil
+		}
+		return m, m.loadInitialPlaylist()
+	default:
+		var cmd tea.Cmd
+		m.apiKeyInput, cmd = m.apiKeyInput.Update(msg)
+		return m, cmd
+	}
+}
+
+func (m *AppModel) apiKeyView() string {
+	title := modalTitleStyle.Render("YouTube API Key")
+	helperText := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorHelp)).
+		Italic(true).
+		Render("↵ Enter to connect  •  ESC to cancel\nUse 'gplay config set api_key' to keep it")
+
+	modal := modalStyle.Render(fmt.Sprintf("%s\n\n%s\n\n%s", title, m.apiKeyInput.View(), helperText))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.NoColor{}))
+}
+
+// loadHistory opens the play history. Failing to read it only disables
+// history rather than stopping the app.
+func (m *AppModel) loadHistory() {
+	path, err := library.DefaultHistoryPath()
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	history, err := library.LoadHistory(path)
+	if err != nil {
+		m.err = err
+	}
+	m.history = history
+}
+
+// showHistory replaces the results list with recently played tracks
+func (m *AppModel) showHistory() {
+	if m.daemon != nil {
+		// The daemon records what it plays in the same file
+		m.loadHistory()
+	}
+	if m.history == nil {
+		m.err = fmt.Errorf("history is not available")
+		return
+	}
+
+	// A search or page still loading would land in the history list
+	m.cancelPending()
+	m.listTitle = "History"
+	m.lastQuery = ""
+	m.nextPageToken = ""
+	m.setResults(m.history.Videos())
+}
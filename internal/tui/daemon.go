@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/player"
+	"github.com/alanpramil7/gplay/internal/yt"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// daemonPoll is how often the TUI asks a daemon what it is playing
+const daemonPoll = 500 * time.Millisecond
+
+// playback is what the TUI controls and renders: the in-process
+// AudioService, or a gplay daemon
+type playback interface {
+	Pause()
+	Play()
+	Stop()
+	IsPlaying() bool
+	IsPaused() bool
+	IsLive() bool
+	GetCurrentSong() string
+	Position() time.Duration
+}
+
+// daemonPlayback plays through a gplay daemon, so the music outlives the
+// TUI. It answers from the last status the daemon sent, so rendering never
+// waits on the socket.
+type daemonPlayback struct {
+	client *player.Client
+
+	mu        sync.Mutex
+	status    player.Status
+	fetchedAt time.Time
+}
+
+type daemonStatusMsg struct {
+	status player.Status
+	err    error
+}
+
+// connectDaemon switches playback to a running daemon, if there is one,
+// and shows what it is playing
+func (m *AppModel) connectDaemon() {
+	client, err := player.Dial(m.deps.SocketPath)
+	if err != nil {
+		return
+	}
+	status, err := client.Status()
+	if err != nil {
+		client.Close()
+		return
+	}
+	m.daemon = &daemonPlayback{client: client}
+	m.daemon.update(status, nil)
+	m.playback = m.daemon
+	if status.Current != nil && status.State != player.StateStopped {
+		current := *status.Current
+		m.selectedItem = &current
+	}
+}
+
+// pollDaemon fetches the daemon status after a short wait
+func (m *AppModel) pollDaemon() tea.Cmd {
+	daemon := m.daemon
+	return tea.Tick(daemonPoll, func(time.Time) tea.Msg {
+		status, err := daemon.client.Status()
+		return daemonStatusMsg{status, err}
+	})
+}
+
+// followDaemon reacts to what the daemon did on its own: moving to another
+// track, or running out of tracks
+func (m *AppModel) followDaemon(msg daemonStatusMsg) tea.Cmd {
+	if m.daemon == nil {
+		return nil
+	}
+	if msg.err != nil {
+		// The daemon is gone; keep going with local playback
+		m.daemon.client.Close()
+		m.daemon = nil
+		m.playback = m.AudioService
+		m.err = fmt.Errorf("lost the gplay daemon, playing locally from now on: %w", msg.err)
+		return m.listenForSongCompletion()
+	}
+
+	previous := m.daemon.update(msg.status, nil)
+	cmds := []tea.Cmd{m.pollDaemon()}
+	current := msg.status.Current
+	switch {
+	case msg.status.Played != previous.Played && current != nil:
+		cmds = append(cmds, m.showTrack(*current))
+	case msg.status.Ended && !previous.Ended:
+		cmds = append(cmds, m.songComplete())
+	}
+	return tea.Batch(cmds...)
+}
+
+// showTrack selects the track the daemon moved to, which is in the list
+// unless something else queued it
+func (m *AppModel) showTrack(video yt.Video) tea.Cmd {
+	if m.selectedItem != nil && m.selectedItem.ID == video.ID {
+		return nil
+	}
+	m.selectedItem = &video
+	for i := range m.searchResults {
+		if m.searchResults[i].ID == video.ID {
+			m.selected = i
+			m.selectedItem = &m.searchResults[i]
+			m.updateResultsViewport()
+			break
+		}
+	}
+	return tea.Batch(m.loadLyrics(), m.maybeLoadMore(), m.maybeLoadRadio())
+}
+
+// upNext is the selected track and the playable ones after it, handed to
+// the daemon so it keeps playing the list once the TUI is closed
+func (m *AppModel) upNext() []yt.Video {
+	item := *m.selectedItem
+	if m.selected < 0 || m.selected >= len(m.searchResults) || m.searchResults[m.selected].ID != item.ID {
+		return []yt.Video{item}
+	}
+	videos := []yt.Video{item}
+	for _, v := range m.searchResults[m.selected+1:] {
+		if v.Playable() {
+			videos = append(videos, v)
+		}
+	}
+	return videos
+}
+
+// quit exits the TUI, stopping local playback. A daemon keeps playing.
+func (m *AppModel) quit() tea.Cmd {
+	if m.daemon != nil {
+		m.daemon.client.Close()
+	} else {
+		m.AudioService.Stop()
+	}
+	return tea.Quit
+}
+
+// update stores a status and returns the one it replaces. A failed call
+// keeps the old status; polling notices when the daemon is gone.
+func (d *daemonPlayback) update(status player.Status, err error) player.Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	previous := d.status
+	if err != nil {
+		return previous
+	}
+	d.status = status
+	d.fetchedAt = time.Now()
+	return previous
+}
+
+// play replaces the daemon queue and starts its first track
+func (d *daemonPlayback) play(videos []yt.Video) error {
+	status, err := d.client.Play(videos, 0)
+	d.update(status, err)
+	return err
+}
+
+func (d *daemonPlayback) Pause() {
+	status, err := d.client.Pause()
+	d.update(status, err)
+}
+
+func (d *daemonPlayback) Play() {
+	status, err := d.client.Resume()
+	d.update(status, err)
+}
+
+func (d *daemonPlayback) Stop() {
+	status, err := d.client.Stop()
+	d.update(status, err)
+}
+
+func (d *daemonPlayback) IsPlaying() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status.State == player.StatePlaying
+}
+
+func (d *daemonPlayback) IsPaused() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status.State == player.StatePaused
+}
+
+func (d *daemonPlayback) IsLive() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status.Live
+}
+
+func (d *daemonPlayback) GetCurrentSong() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.status.Current == nil || d.status.State == player.StateStopped {
+		return ""
+	}
+	return d.status.Current.URL
+}
+
+// Position moves on from the last reported position while playing, so
+// lyrics stay smooth between polls
+func (d *daemonPlayback) Position() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.status.State == player.StatePlaying {
+		return d.status.Position + time.Since(d.fetchedAt)
+	}
+	return d.status.Position
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/doctor"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// runDoctor runs the environment checks in the background. The checks
+// cannot be interrupted, but cancelling drops their report.
+func (m *AppModel) runDoctor() tea.Cmd {
+	_, id := m.startRequest()
+	return func() tea.Msg {
+		return doctorCompleteMsg{id: id, results: doctor.Run(doctor.Options{
+			Config:     m.config,
+			ConfigPath: m.configPath,
+			Audio:      m.AudioService,
+		})}
+	}
+}
+
+func (m *AppModel) handleDoctorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc", "enter", "q":
+		m.state = StateNormal
+	}
+	return m, nil
+}
+
+// doctorView renders the diagnostics report as a modal
+func (m *AppModel) doctorView() string {
+	var b strings.Builder
+	for _, r := range m.doctorResults {
+		var symbol string
+		switch r.Status {
+		case doctor.StatusOK:
+			symbol = lipgloss.NewStyle().Foreground(lipgloss.Color(colorSuccess)).Render("✓")
+		case doctor.StatusWarn:
+			symbol = lipgloss.NewStyle().Foreground(lipgloss.Color(colorWarning)).Render("!")
+		default:
+			symbol = lipgloss.NewStyle().Foreground(lipgloss.Color(colorError)).Render("✗")
+		}
+		fmt.Fprintf(&b, "%s %s  %s\n", symbol,
+			lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorText)).Render(r.Name),
+			lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render(r.Detail))
+		if r.Status != doctor.StatusOK && r.Fix != "" {
+			fmt.Fprintf(&b, "    %s\n", helpStyle.Render("fix: "+r.Fix))
+		}
+	}
+
+	title := modalTitleStyle.Render("Diagnostics")
+	helperText := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorHelp)).
+		Italic(true).
+		Render("ESC to close")
+
+	modal := modalStyle.Render(fmt.Sprintf("%s\n\n%s\n%s", title, b.String(), helperText))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.NoColor{}))
+}
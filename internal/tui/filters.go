@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Search filter panel fields, in display order
+const (
+	filterAfter = iota
+	filterBefore
+	filterRegion
+	filterLanguage
+	filterCategory
+	filterEvent
+	filterCaptions
+	filterDefinition
+	filterChannel
+	filterCount
+)
+
+var filterLabels = [filterCount]string{
+	filterAfter:      "Published after",
+	filterBefore:     "Published before",
+	filterRegion:     "Region",
+	filterLanguage:   "Language",
+	filterCategory:   "Category",
+	filterEvent:      "Live",
+	filterCaptions:   "Captions",
+	filterDefinition: "Definition",
+	filterChannel:    "Channel",
+}
+
+var filterPlaceholders = [filterCount]string{
+	filterAfter:      "YYYY-MM-DD or 7d",
+	filterBefore:     "YYYY-MM-DD or 7d",
+	filterRegion:     "US",
+	filterLanguage:   "en",
+	filterCategory:   "music, gaming, 10...",
+	filterEvent:      strings.Join(yt.EventTypes, ", "),
+	filterCaptions:   strings.Join(yt.VideoCaptions, ", "),
+	filterDefinition: strings.Join(yt.VideoDefinitions, ", "),
+	filterChannel:    "ID, @handle or URL",
+}
+
+const filterInputWidth = 30
+
+// filtersAppliedMsg carries the parsed filters once a channel, if any, has
+// been resolved
+type filtersAppliedMsg struct {
+	id     int
+	config yt.SearchConfig
+}
+
+func newFilterInputs() []textinput.Model {
+	inputs := make([]textinput.Model, filterCount)
+	for i := range inputs {
+		input := textinput.New()
+		input.Placeholder = filterPlaceholders[i]
+		input.Width = filterInputWidth
+		input.Prompt = ""
+		input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(colorText))
+		inputs[i] = input
+	}
+	return inputs
+}
+
+// openFilters shows the filter panel with the focus on the first field
+func (m *AppModel) openFilters() tea.Cmd {
+	m.state = StateFilters
+	m.filterErr = nil
+	m.filterFocus = 0
+	return m.focusFilter(0)
+}
+
+func (m *AppModel) focusFilter(i int) tea.Cmd {
+	m.filterInputs[m.filterFocus].Blur()
+	m.filterFocus = (i + filterCount) % filterCount
+	return m.filterInputs[m.filterFocus].Focus()
+}
+
+func (m *AppModel) handleFilterKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc":
+		m.filterInputs[m.filterFocus].Blur()
+		m.state = StateNormal
+		return m, nil
+	case "tab", "down":
+		return m, m.focusFilter(m.filterFocus + 1)
+	case "shift+tab", "up":
+		return m, m.focusFilter(m.filterFocus - 1)
+	case "ctrl+r":
+		for i := range m.filterInputs {
+			m.filterInputs[i].SetValue("")
+		}
+		m.filterErr = nil
+		return m, nil
+	case "enter":
+		config, channel, err := m.parseFilters()
+		if err != nil {
+			m.filterErr = err
+			return m, nil
+		}
+		m.filterInputs[m.filterFocus].Blur()
+		m.state = StateLoading
+		m.loadingText = "Applying filters..."
+		return m, m.applyFilters(config, channel)
+	}
+
+	var cmd tea.Cmd
+	m.filterInputs[m.filterFocus], cmd = m.filterInputs[m.filterFocus].Update(msg)
+	return m, cmd
+}
+
+// parseFilters reads the panel into a config holding only the filters. The
+// channel is returned separately since a handle has to be looked up.
+func (m *AppModel) parseFilters() (yt.SearchConfig, string, error) {
+	value := func(i int) string { return strings.TrimSpace(m.filterInputs[i].Value()) }
+
+	var config yt.SearchConfig
+	var err error
+	now := time.Now()
+	if config.PublishedAfter, err = yt.ParseTimeBound(value(filterAfter), now); err != nil {
+		return config, "", err
+	}
+	if config.PublishedBefore, err = yt.ParseTimeBound(value(filterBefore), now); err != nil {
+		return config, "", err
+	}
+	if config.VideoCategoryID, err = yt.ParseCategory(value(filterCategory)); err != nil {
+		return config, "", err
+	}
+	config.RegionCode = strings.ToUpper(value(filterRegion))
+	config.RelevanceLanguage = value(filterLanguage)
+	config.EventType = value(filterEvent)
+	config.VideoCaption = value(filterCaptions)
+	config.VideoDefinition = value(filterDefinition)
+	if err := config.Validate(); err != nil {
+		return config, "", err
+	}
+	return config, value(filterChannel), nil
+}
+
+// applyFilters resolves the channel filter to an ID in the background
+func (m *AppModel) applyFilters(config yt.SearchConfig, channelRef string) tea.Cmd {
+	ctx, id := m.startRequest()
+
+	return func() tea.Msg {
+		if channelRef != "" {
+			if m.ChannelService == nil {
+				return searchErrorMsg{id, m.apiUnavailableError()}
+			}
+			channel, err := m.ChannelService.Channel(ctx, channelRef)
+			if err != nil {
+				return searchErrorMsg{id, fmt.Errorf("channel filter: %w", err)}
+			}
+			config.ChannelID = channel.ID
+		}
+		return filtersAppliedMsg{id: id, config: config}
+	}
+}
+
+// withFilters copies the active filters onto a search config
+func (m *AppModel) withFilters(config *yt.SearchConfig) *yt.SearchConfig {
+	f := m.filters
+	config.PublishedAfter = f.PublishedAfter
+	config.PublishedBefore = f.PublishedBefore
+	config.RegionCode = f.RegionCode
+	config.RelevanceLanguage = f.RelevanceLanguage
+	config.VideoCategoryID = f.VideoCategoryID
+	config.EventType = f.EventType
+	config.VideoCaption = f.VideoCaption
+	config.VideoDefinition = f.VideoDefinition
+	config.ChannelID = f.ChannelID
+	return config
+}
+
+// filtersView renders the filter panel as a modal
+func (m *AppModel) filtersView() string {
+	labelStyle := lipgloss.NewStyle().Width(18).Foreground(lipgloss.Color(colorMuted))
+	focusedStyle := labelStyle.Foreground(lipgloss.Color(colorPrimary)).Bold(true)
+
+	var b strings.Builder
+	for i, input := range m.filterInputs {
+		style := labelStyle
+		if i == m.filterFocus {
+			style = focusedStyle
+		}
+		fmt.Fprintf(&b, "%s %s\n", style.Render(filterLabels[i]), input.View())
+	}
+
+	if m.filterErr != nil {
+		b.WriteString("\n" + errorStyle.Render(m.filterErr.Error()) + "\n")
+	}
+
+	title := modalTitleStyle.Render("Search Filters")
+	helperText := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorHelp)).
+		Italic(true).
+		Render("↵ Enter to apply  •  TAB/↑↓ to move  •  CTRL+R to clear  •  ESC to cancel")
+
+	modal := modalStyle.Render(fmt.Sprintf("%s\n\n%s\n%s", title, b.String(), helperText))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.NoColor{}))
+}
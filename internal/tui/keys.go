@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"github.com/alanpramil7/gplay/internal/config"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the configurable key bindings used in StateNormal
+type keyMap struct {
+	Quit    key.Binding
+	Search  key.Binding
+	Up      key.Binding
+	Down    key.Binding
+	Play    key.Binding
+	Pause   key.Binding
+	Stop    key.Binding
+	Doctor  key.Binding
+	APIKey  key.Binding
+	History key.Binding
+	Channel key.Binding
+	Filters key.Binding
+	Command key.Binding
+	Radio   key.Binding
+
+	MyPlaylists   key.Binding
+	Liked         key.Binding
+	AddToPlaylist key.Binding
+
+	RemoveFromPlaylist key.Binding
+
+	Lyrics        key.Binding
+	LyricsEarlier key.Binding
+	LyricsLater   key.Binding
+}
+
+func newKeyMap(cfg config.KeyConfig) keyMap {
+	return keyMap{
+		Quit:    newBinding(cfg.Quit, "quit"),
+		Search:  newBinding(cfg.Search, "search"),
+		Up:      newBinding(cfg.Up, "up"),
+		Down:    newBinding(cfg.Down, "down"),
+		Play:    newBinding(cfg.Play, "play"),
+		Pause:   newBinding(cfg.Pause, "pause"),
+		Stop:    newBinding(cfg.Stop, "stop"),
+		Doctor:  newBinding(cfg.Doctor, "diagnostics"),
+		APIKey:  newBinding(cfg.APIKey, "api key"),
+		History: newBinding(cfg.History, "history"),
+		Channel: newBinding(cfg.Channel, "channel"),
+		Filters: newBinding(cfg.Filters, "filters"),
+		Command: newBinding(cfg.Command, "command"),
+		Radio:   newBinding(cfg.Radio, "radio"),
+
+		MyPlaylists:   newBinding(cfg.MyPlaylists, "my playlists"),
+		Liked:         newBinding(cfg.Liked, "liked"),
+		AddToPlaylist: newBinding(cfg.AddToPlaylist, "add to playlist"),
+
+		RemoveFromPlaylist: newBinding(cfg.RemoveFromPlaylist, "remove from playlist"),
+
+		Lyrics:        newBinding(cfg.Lyrics, "lyrics"),
+		LyricsEarlier: newBinding(cfg.LyricsEarlier, "lyrics earlier"),
+		LyricsLater:   newBinding(cfg.LyricsLater, "lyrics later"),
+	}
+}
+
+func newBinding(keys []string, desc string) key.Binding {
+	label := ""
+	if len(keys) > 0 {
+		label = keyLabel(keys[0])
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(label, desc))
+}
+
+// keyLabel returns a display name for a key as reported by tea.KeyMsg
+func keyLabel(k string) string {
+	switch k {
+	case " ":
+		return "space"
+	case "enter":
+		return "↵"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	default:
+		return k
+	}
+}
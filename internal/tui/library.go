@@ -0,0 +1,383 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// likedLimit bounds the liked videos view
+const likedLimit = 200
+
+// Picker prompts, see playlistPrompt
+const (
+	promptNew    = "New playlist"
+	promptRename = "Rename to"
+)
+
+type myPlaylistsMsg struct {
+	id        int
+	playlists []yt.Playlist
+	// notice reports the edit the list was reloaded after
+	notice string
+}
+
+// playlistEditedMsg reports an edit made from the main list. removed is
+// the video taken out of the open playlist.
+type playlistEditedMsg struct {
+	id      int
+	notice  string
+	removed string
+}
+
+type addedToPlaylistMsg struct {
+	id       int
+	video    string
+	playlist string
+}
+
+// openMyPlaylists loads the user's playlists into the picker. With a
+// track to add, picking a playlist adds it there instead of opening it.
+func (m *AppModel) openMyPlaylists(add *yt.SearchResult) tea.Cmd {
+	ctx, id := m.startRequest()
+	m.playlistCursor = 0
+	m.addTarget = nil
+	if add != nil {
+		// The list may be rebuilt while the picker is open
+		target := *add
+		m.addTarget = &target
+	}
+	library := m.LibraryService
+
+	return func() tea.Msg {
+		if library == nil {
+			return searchErrorMsg{id, services.ErrNoLibrary}
+		}
+		playlists, err := library.MyPlaylists(ctx)
+		if err != nil {
+			return searchErrorMsg{id, fmt.Errorf("loading your playlists failed: %w", err)}
+		}
+		return myPlaylistsMsg{id: id, playlists: playlists}
+	}
+}
+
+// showLiked replaces the list with the user's liked videos
+func (m *AppModel) showLiked() tea.Cmd {
+	ctx, id := m.startRequest()
+	library := m.LibraryService
+
+	return func() tea.Msg {
+		if library == nil {
+			return searchErrorMsg{id, services.ErrNoLibrary}
+		}
+		liked, err := library.Liked(ctx, likedLimit)
+		if err != nil {
+			return searchErrorMsg{id, fmt.Errorf("loading liked videos failed: %w", err)}
+		}
+		return searchCompleteMsg{id: id, title: "Liked", results: liked}
+	}
+}
+
+func newPlaylistInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "Playlist title"
+	input.CharLimit = searchCharLimit
+	input.Width = searchWidth
+	input.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(colorPrimary))
+	input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(colorText))
+	return input
+}
+
+func (m *AppModel) handlePlaylistKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.playlistPrompt != "" {
+		return m.handlePlaylistPromptKeys(msg)
+	}
+
+	// Deleting and pushing are only done when their key is pressed twice
+	confirm := m.playlistConfirm
+	m.playlistConfirm = ""
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc", "q":
+		m.state = StateNormal
+		m.addTarget = nil
+	case "n":
+		return m, m.openPlaylistPrompt(promptNew, "")
+	case "r":
+		if len(m.myPlaylists) > 0 {
+			return m, m.openPlaylistPrompt(promptRename, m.myPlaylists[m.playlistCursor].Title)
+		}
+	case "d", "p":
+		if len(m.myPlaylists) == 0 {
+			break
+		}
+		if confirm != msg.String() {
+			m.playlistConfirm = msg.String()
+			break
+		}
+		playlist := m.myPlaylists[m.playlistCursor]
+		if msg.String() == "d" {
+			return m, m.editPlaylists(func(ctx context.Context, editor services.PlaylistEditor) (string, error) {
+				if err := editor.DeletePlaylist(ctx, playlist.ID); err != nil {
+					return "", err
+				}
+				return "Deleted " + playlist.Title, nil
+			})
+		}
+		m.state = StateLoading
+		m.loadingText = "Pushing the list to " + playlist.Title + "..."
+		return m, m.pushList(playlist)
+	case "up", "k":
+		if m.playlistCursor > 0 {
+			m.playlistCursor--
+		}
+	case "down", "j":
+		if m.playlistCursor < len(m.myPlaylists)-1 {
+			m.playlistCursor++
+		}
+	case "enter":
+		if len(m.myPlaylists) == 0 {
+			m.state = StateNormal
+			return m, nil
+		}
+		playlist := m.myPlaylists[m.playlistCursor]
+		m.state = StateLoading
+		if m.addTarget != nil {
+			m.loadingText = "Adding to " + playlist.Title + "..."
+			return m, m.addToPlaylist(*m.addTarget, playlist)
+		}
+		m.loadingText = "Loading playlist..."
+		return m, m.openPlaylist(playlist)
+	}
+	return m, nil
+}
+
+func (m *AppModel) handlePlaylistPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc":
+		m.playlistPrompt = ""
+		m.playlistInput.Blur()
+		return m, nil
+	case "enter":
+		title := strings.TrimSpace(m.playlistInput.Value())
+		if title == "" {
+			return m, nil
+		}
+		prompt := m.playlistPrompt
+		m.playlistPrompt = ""
+		m.playlistInput.Blur()
+
+		if prompt == promptNew {
+			return m, m.editPlaylists(func(ctx context.Context, editor services.PlaylistEditor) (string, error) {
+				if _, err := editor.CreatePlaylist(ctx, title, "", services.PrivacyPrivate); err != nil {
+					return "", err
+				}
+				return "Created private playlist " + title, nil
+			})
+		}
+		playlist := m.myPlaylists[m.playlistCursor]
+		return m, m.editPlaylists(func(ctx context.Context, editor services.PlaylistEditor) (string, error) {
+			if err := editor.RenamePlaylist(ctx, playlist.ID, title); err != nil {
+				return "", err
+			}
+			return "Renamed " + playlist.Title + " to " + title, nil
+		})
+	}
+
+	var cmd tea.Cmd
+	m.playlistInput, cmd = m.playlistInput.Update(msg)
+	return m, cmd
+}
+
+// openPlaylistPrompt asks for a playlist title in the picker
+func (m *AppModel) openPlaylistPrompt(prompt, value string) tea.Cmd {
+	m.playlistPrompt = prompt
+	m.playlistInput.SetValue(value)
+	m.playlistInput.CursorEnd()
+	return m.playlistInput.Focus()
+}
+
+// editPlaylists makes an edit from the picker and reloads it after
+func (m *AppModel) editPlaylists(edit func(context.Context, services.PlaylistEditor) (string, error)) tea.Cmd {
+	ctx, id := m.startRequest()
+	m.state = StateLoading
+	m.loadingText = "Updating your playlists..."
+	editor, library := m.PlaylistEditor, m.LibraryService
+
+	return func() tea.Msg {
+		if editor == nil {
+			return searchErrorMsg{id, services.ErrNoLibrary}
+		}
+		notice, err := edit(ctx, editor)
+		if err != nil {
+			return searchErrorMsg{id, err}
+		}
+		playlists, err := library.MyPlaylists(ctx)
+		if err != nil {
+			return searchErrorMsg{id, fmt.Errorf("loading your playlists failed: %w", err)}
+		}
+		return myPlaylistsMsg{id: id, playlists: playlists, notice: notice}
+	}
+}
+
+// openPlaylist lists the items of one of the user's playlists
+func (m *AppModel) openPlaylist(playlist yt.Playlist) tea.Cmd {
+	ctx, id := m.startRequest()
+
+	return func() tea.Msg {
+		results, err := m.PlaylistService.GetPlaylistItemsContext(ctx, playlist.ID, playlistPageSize)
+		if err != nil {
+			return searchErrorMsg{id, fmt.Errorf("loading playlist failed: %w", err)}
+		}
+		return searchCompleteMsg{id: id, title: playlist.Title, results: results, playlist: &playlist}
+	}
+}
+
+func (m *AppModel) addToPlaylist(video yt.SearchResult, playlist yt.Playlist) tea.Cmd {
+	ctx, id := m.startRequest()
+	m.addTarget = nil
+	editor := m.PlaylistEditor
+
+	return func() tea.Msg {
+		if editor == nil {
+			return searchErrorMsg{id, services.ErrNoLibrary}
+		}
+		if _, err := editor.InsertItem(ctx, playlist.ID, video.ID, -1); err != nil {
+			return searchErrorMsg{id, err}
+		}
+		return addedToPlaylistMsg{id: id, video: video.Title, playlist: playlist.Title}
+	}
+}
+
+// removeFromPlaylist takes the first entry of a video out of the open
+// playlist
+func (m *AppModel) removeFromPlaylist(video yt.SearchResult, playlist yt.Playlist) tea.Cmd {
+	ctx, id := m.startRequest()
+	editor := m.PlaylistEditor
+
+	return func() tea.Msg {
+		if editor == nil {
+			return searchErrorMsg{id, services.ErrNoLibrary}
+		}
+		// The list holds videos, not the entry IDs removing takes
+		items, err := editor.Items(ctx, playlist.ID)
+		if err != nil {
+			return searchErrorMsg{id, err}
+		}
+		for _, item := range items {
+			if item.VideoID == video.ID {
+				if err := editor.RemoveItem(ctx, item.ID); err != nil {
+					return searchErrorMsg{id, err}
+				}
+				return playlistEditedMsg{id: id, notice: fmt.Sprintf("Removed %q from %s", video.Title, playlist.Title), removed: video.ID}
+			}
+		}
+		return searchErrorMsg{id, fmt.Errorf("%q is no longer in %s", video.Title, playlist.Title)}
+	}
+}
+
+// pushList makes a playlist match the list as shown, filter and sort
+// included
+func (m *AppModel) pushList(playlist yt.Playlist) tea.Cmd {
+	videos := append([]yt.Video(nil), m.searchResults...)
+	ctx, id := m.startRequest()
+	m.addTarget = nil
+	editor := m.PlaylistEditor
+
+	return func() tea.Msg {
+		if editor == nil {
+			return searchErrorMsg{id, services.ErrNoLibrary}
+		}
+		items, err := editor.Items(ctx, playlist.ID)
+		if err != nil {
+			return searchErrorMsg{id, err}
+		}
+		changes := library.Plan(videos, items)
+		if err := services.ApplyChanges(ctx, editor, playlist.ID, changes, nil); err != nil {
+			return searchErrorMsg{id, fmt.Errorf("pushing to %s failed: %w", playlist.Title, err)}
+		}
+		return playlistEditedMsg{id: id, notice: playlist.Title + ": " + library.Summary(changes)}
+	}
+}
+
+// dropResult removes the first entry of a video from the list
+func (m *AppModel) dropResult(videoID string) {
+	for i, r := range m.allResults {
+		if r.ID == videoID {
+			m.allResults = append(m.allResults[:i:i], m.allResults[i+1:]...)
+			break
+		}
+	}
+	m.refreshResults()
+}
+
+// currentTrack is the playing track or, with nothing playing, the
+// highlighted one
+func (m *AppModel) currentTrack() *yt.SearchResult {
+	if m.selectedItem != nil && m.playback.GetCurrentSong() == m.selectedItem.URL {
+		return m.selectedItem
+	}
+	if m.selected >= 0 && m.selected < len(m.searchResults) {
+		return &m.searchResults[m.selected]
+	}
+	return nil
+}
+
+// playlistsView renders the playlist picker as a modal
+func (m *AppModel) playlistsView() string {
+	var b strings.Builder
+	if len(m.myPlaylists) == 0 {
+		b.WriteString(emptyStateStyle.Render("You have no playlists") + "\n")
+	}
+	for i, p := range m.myPlaylists {
+		line := fmt.Sprintf("%s  %s", truncate(p.Title, 40),
+			lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted)).Render(fmt.Sprintf("%d tracks", p.ItemCount)))
+		if i == m.playlistCursor {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color(colorPrimary)).Bold(true).Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+
+	heading := "My Playlists"
+	action := "open"
+	if m.addTarget != nil {
+		heading = "Add \"" + truncate(m.addTarget.Title, 30) + "\" to"
+		action = "add"
+	}
+	title := modalTitleStyle.Render(heading)
+
+	help := "↵ Enter to " + action + "  •  ↑↓ to move  •  ESC to cancel\nn new  •  r rename  •  d delete  •  p push the list here"
+	if m.notice != "" {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color(colorSuccess)).Render(m.notice) + "\n")
+	}
+	switch {
+	case m.playlistPrompt != "":
+		b.WriteString("\n" + m.playlistPrompt + ": " + m.playlistInput.View() + "\n")
+		help = "↵ Enter to save  •  ESC to cancel"
+	case m.playlistConfirm == "d":
+		help = "Press d again to delete this playlist"
+	case m.playlistConfirm == "p":
+		help = fmt.Sprintf("Press p again to replace this playlist with the %d tracks of the list", len(m.searchResults))
+	}
+	helperText := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorHelp)).
+		Italic(true).
+		Render(help)
+
+	modal := modalStyle.Render(fmt.Sprintf("%s\n\n%s\n%s", title, b.String(), helperText))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.NoColor{}))
+}
@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// warningMsg carries a line logged while the TUI is running
+type warningMsg string
+
+// LogWriter returns a writer for the log package that shows each line in
+// the status bar of program instead of printing it over the TUI
+func LogWriter(program *tea.Program) io.Writer {
+	return logWriter{program: program}
+}
+
+type logWriter struct {
+	program *tea.Program
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	line := strings.TrimPrefix(strings.TrimSpace(string(p)), "Warning: ")
+	// Update may log too, and Send would then wait on the loop it blocks
+	go w.program.Send(warningMsg(line))
+	return len(p), nil
+}
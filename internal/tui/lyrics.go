@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/lyrics"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// lyricsTick is how often the pane follows playback
+	lyricsTick = 200 * time.Millisecond
+	// lyricsOffsetStep is how far one press of the earlier or later keys
+	// shifts the lyrics
+	lyricsOffsetStep = 250 * time.Millisecond
+)
+
+type lyricsMsg struct {
+	videoID string
+	lyrics  *lyrics.Lyrics
+	err     error
+}
+type lyricsTickMsg struct{}
+
+// toggleLyrics shows or hides the lyrics pane, loading the lyrics of the
+// playing track when shown
+func (m *AppModel) toggleLyrics() tea.Cmd {
+	m.lyricsOn = !m.lyricsOn
+	if !m.lyricsOn {
+		m.cancelLyricsLoad()
+		return nil
+	}
+	return tea.Batch(m.loadLyrics(), m.tickLyrics())
+}
+
+// loadLyrics fetches the lyrics of the selected track unless the pane is
+// hidden or already has them. Lookups run apart from startRequest so they
+// never cancel a search.
+func (m *AppModel) loadLyrics() tea.Cmd {
+	if !m.lyricsOn || m.selectedItem == nil || m.lyricsFor == m.selectedItem.ID {
+		return nil
+	}
+	m.cancelLyricsLoad()
+	m.lyricsFor = m.selectedItem.ID
+	m.lyrics = nil
+	m.lyricsErr = nil
+	m.lyricsOffset = 0
+	if m.lyricsProvider == nil {
+		m.lyricsErr = m.lyricsConfigErr
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelLyrics = cancel
+	m.isLoadingLyrics = true
+
+	video := *m.selectedItem
+	provider := m.lyricsProvider
+	return func() tea.Msg {
+		found, err := provider.Lyrics(ctx, video)
+		return lyricsMsg{videoID: video.ID, lyrics: found, err: err}
+	}
+}
+
+// cancelLyricsLoad aborts the lookup in flight. The pane then loads again
+// for whichever track is selected next.
+func (m *AppModel) cancelLyricsLoad() {
+	if m.cancelLyrics != nil {
+		m.cancelLyrics()
+		m.cancelLyrics = nil
+	}
+	if m.isLoadingLyrics {
+		m.isLoadingLyrics = false
+		m.lyricsFor = ""
+	}
+}
+
+// setLyrics stores fetched lyrics if they are for the track still shown
+func (m *AppModel) setLyrics(msg lyricsMsg) {
+	if msg.videoID != m.lyricsFor {
+		return
+	}
+	m.isLoadingLyrics = false
+	m.cancelLyrics = nil
+	m.lyrics = msg.lyrics
+	m.lyricsErr = msg.err
+}
+
+// tickLyrics redraws the pane regularly while it is shown so the current
+// line follows playback. Only one tick loop runs at a time. A tick changes
+// nothing but the redraw, so errors and notices outlive it.
+func (m *AppModel) tickLyrics() tea.Cmd {
+	if m.lyricsTicking {
+		return nil
+	}
+	m.lyricsTicking = true
+	return tea.Tick(lyricsTick, func(time.Time) tea.Msg { return lyricsTickMsg{} })
+}
+
+// shiftLyrics moves the lyrics by d, positive values showing lines sooner
+func (m *AppModel) shiftLyrics(d time.Duration) {
+	if m.lyricsOn && m.lyrics != nil && m.lyrics.Synced {
+		m.lyricsOffset += d
+	}
+}
+
+// lyricsView renders the right panel while the lyrics pane is on: the
+// player status and track, then the lines around the current one
+func (m *AppModel) lyricsView(statusLine string, width, height int) string {
+	if m.selectedItem == nil {
+		return statusLine + "\n\n" + emptyStateStyle.Render("No video selected")
+	}
+
+	header := fmt.Sprintf("%s\n\n%s\n%s",
+		statusLine,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorPrimary)).MaxWidth(width).Render(m.selectedItem.Title),
+		lipgloss.NewStyle().Foreground(lipgloss.Color(colorSecondary)).Italic(true).Render(m.selectedItem.ChannelTitle),
+	)
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color(colorMuted))
+
+	switch {
+	case m.isLoadingLyrics:
+		return header + "\n\n" + loadingStyle.Render("Looking for lyrics...")
+	case m.lyricsErr != nil:
+		return header + "\n\n" + muted.Render(m.lyricsErr.Error())
+	case m.lyrics == nil:
+		return header
+	}
+
+	info := m.lyrics.Source
+	if !m.lyrics.Synced {
+		info += "  •  not synced"
+	} else if m.lyricsOffset != 0 {
+		direction := "earlier"
+		if m.lyricsOffset < 0 {
+			direction = "later"
+		}
+		info += fmt.Sprintf("  •  %.2fs %s", max(m.lyricsOffset, -m.lyricsOffset).Seconds(), direction)
+	}
+
+	// Room left below the header and the info line
+	rows := max(height-8, 1)
+	current := m.lyrics.Index(m.playback.Position() + m.lyricsOffset)
+	start := 0
+	if m.lyrics.Synced {
+		// Keep the current line a third of the way down
+		start = max(min(current-rows/3, len(m.lyrics.Lines)-rows), 0)
+	}
+
+	line := lipgloss.NewStyle().MaxWidth(width)
+	var b strings.Builder
+	for i := start; i < min(start+rows, len(m.lyrics.Lines)); i++ {
+		text := m.lyrics.Lines[i].Text
+		switch {
+		case !m.lyrics.Synced:
+			b.WriteString(line.Foreground(lipgloss.Color(colorText)).Render(text))
+		case i == current:
+			b.WriteString(line.Foreground(lipgloss.Color(colorPrimary)).Bold(true).Render(text))
+		case i < current:
+			b.WriteString(line.Foreground(lipgloss.Color(colorMuted)).Render(text))
+		default:
+			b.WriteString(line.Foreground(lipgloss.Color(colorText)).Render(text))
+		}
+		b.WriteByte('\n')
+	}
+	return header + "\n" + muted.Render(info) + "\n\n" + strings.TrimRight(b.String(), "\n")
+}
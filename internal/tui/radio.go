@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toggleRadio turns radio on or off and, when turned on near the end of
+// the list, starts fetching right away
+func (m *AppModel) toggleRadio() tea.Cmd {
+	m.radioOn = !m.radioOn
+	if !m.radioOn {
+		m.radioWaiting = false
+		if m.isLoadingRadio {
+			m.cancelBackground()
+		}
+		return nil
+	}
+	return m.maybeLoadRadio()
+}
+
+// maybeLoadRadio fetches tracks related to the selected one once radio is
+// on and no more than the configured number of tracks are left to play.
+// Lists with more pages to load are left to maybeLoadMore.
+func (m *AppModel) maybeLoadRadio() tea.Cmd {
+	if !m.radioOn || m.radio == nil || m.isLoadingRadio || m.isLoadingMore || m.nextPageToken != "" ||
+		m.state == StateLoading || m.selectedItem == nil {
+		return nil
+	}
+	if len(m.searchResults)-1-m.selected > m.config.Radio.Remaining {
+		return nil
+	}
+
+	ctx, id := m.startBackground()
+	m.isLoadingRadio = true
+
+	seed := *m.selectedItem
+	skip := m.recentIDs()
+	batch := max(m.config.Radio.Batch, 1)
+	return func() tea.Msg {
+		related, err := m.radio.Related(ctx, seed, skip, batch)
+		if err != nil {
+			return backgroundErrorMsg{id, fmt.Errorf("radio failed: %w", err)}
+		}
+		return radioMsg{id: id, results: related}
+	}
+}
+
+// recentIDs returns the IDs radio must not add again: everything already
+// listed and the most recent plays
+func (m *AppModel) recentIDs() map[string]bool {
+	ids := make(map[string]bool, len(m.allResults))
+	for _, r := range m.allResults {
+		ids[r.ID] = true
+	}
+	if m.history != nil {
+		recent := m.history.Videos()
+		for _, v := range recent[:min(len(recent), max(m.config.Radio.AvoidRecent, 0))] {
+			ids[v.ID] = true
+		}
+	}
+	return ids
+}
+
+// addRadioTracks appends fetched tracks and, when playback already ran
+// out, plays the first of them
+func (m *AppModel) addRadioTracks(msg radioMsg) tea.Cmd {
+	m.isLoadingRadio = false
+	waiting := m.radioWaiting
+	m.radioWaiting = false
+	if len(msg.results) == 0 {
+		m.err = fmt.Errorf("radio found no new tracks")
+		return nil
+	}
+
+	m.allResults = append(m.allResults, msg.results...)
+	m.refreshResults()
+
+	if waiting && m.nextPlayable() >= 0 {
+		return m.playNext()
+	}
+	return nil
+}
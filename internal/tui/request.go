@@ -0,0 +1,59 @@
+package tui
+
+import "context"
+
+// startRequest cancels whatever request is still in flight and returns the
+// context and ID for a new one. Every result message carries the ID of the
+// request that produced it; messages with an older ID are stale and dropped
+// so a slow search can never overwrite a newer one.
+func (m *AppModel) startRequest() (context.Context, int) {
+	m.cancelPending()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelRequest = cancel
+	return ctx, m.requestID
+}
+
+// cancelPending aborts the request in flight, if any, and invalidates its
+// results. Background loads extend the list being replaced, so they go too.
+func (m *AppModel) cancelPending() {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+		m.cancelRequest = nil
+	}
+	m.requestID++
+	m.isLoadingList = false
+	m.cancelBackground()
+}
+
+// isCurrent reports whether a result belongs to the latest request
+func (m *AppModel) isCurrent(id int) bool {
+	return id == m.requestID
+}
+
+// startBackground is startRequest for loads that add to the current list,
+// more pages and radio tracks. They have their own slot so they never
+// cancel a search the user is waiting for.
+func (m *AppModel) startBackground() (context.Context, int) {
+	m.cancelBackground()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelLoad = cancel
+	return ctx, m.backgroundID
+}
+
+// cancelBackground aborts the background load in flight, if any, and
+// invalidates its results
+func (m *AppModel) cancelBackground() {
+	if m.cancelLoad != nil {
+		m.cancelLoad()
+		m.cancelLoad = nil
+	}
+	m.backgroundID++
+	m.isLoadingMore = false
+	m.isLoadingRadio = false
+}
+
+// isCurrentBackground reports whether a result belongs to the latest
+// background load
+func (m *AppModel) isCurrentBackground(id int) bool {
+	return id == m.backgroundID
+}
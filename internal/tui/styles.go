@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"github.com/alanpramil7/gplay/internal/config"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// UI colors, replaced by the configured theme in applyTheme
+var (
+	colorPrimary   string
+	colorSecondary string
+	colorText      string
+	colorMuted     string
+	colorBorder    string
+	colorError     string
+	colorWarning   string
+	colorSuccess   string
+	colorPaused    string
+	colorHelp      string
+)
+
+// Styles for the UI with modern transparent design
+var (
+	leftPanelStyle  lipgloss.Style
+	rightPanelStyle lipgloss.Style
+	modalStyle      lipgloss.Style
+	modalTitleStyle lipgloss.Style
+	helpStyle       lipgloss.Style
+	titleStyle      lipgloss.Style
+	emptyStateStyle lipgloss.Style
+	errorStyle      lipgloss.Style
+	loadingStyle    lipgloss.Style
+)
+
+// applyTheme sets the UI colors from the config and rebuilds the styles
+func applyTheme(theme config.ThemeConfig) {
+	colorPrimary = theme.Primary
+	colorSecondary = theme.Secondary
+	colorText = theme.Text
+	colorMuted = theme.Muted
+	colorBorder = theme.Border
+	colorError = theme.Error
+	colorWarning = theme.Warning
+	colorSuccess = theme.Success
+	colorPaused = theme.Paused
+	colorHelp = theme.Help
+
+	buildStyles()
+}
+
+func buildStyles() {
+	leftPanelStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(colorBorder)).
+		Padding(0, 1)
+
+	rightPanelStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(colorBorder)).
+		Padding(0, 1)
+
+	modalStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(colorPrimary)).
+		Padding(1, 3).
+		Margin(1, 0)
+
+	modalTitleStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorPrimary)).
+		Bold(true).
+		MarginBottom(1)
+
+	helpStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorHelp))
+
+	titleStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorPrimary)).
+		Bold(true).
+		MarginBottom(1).
+		PaddingLeft(1)
+
+	emptyStateStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorHelp)).
+		Italic(true).
+		Align(lipgloss.Center).
+		MarginTop(2)
+
+	errorStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorError)).
+		Bold(true)
+
+	loadingStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorWarning)).
+		Bold(true)
+}
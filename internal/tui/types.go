@@ -1,6 +1,14 @@
 package tui
 
 import (
+	"context"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/config"
+	"github.com/alanpramil7/gplay/internal/doctor"
+	"github.com/alanpramil7/gplay/internal/filter"
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/lyrics"
 	"github.com/alanpramil7/gplay/internal/yt"
 	"github.com/alanpramil7/gplay/internal/yt/services"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -14,31 +22,156 @@ const (
 	StateNormal State = iota
 	StateSearchInput
 	StateLoading
+	StateDoctor
+	StateAPIKeyInput
+	StateFilters
+	StateCommand
+	StatePlaylists
 )
 
 // Model represents the TUI application state
 type Model struct {
 	state         State
-	client        *yt.Client
 	searchInput   textinput.Model
+	apiKeyInput   textinput.Model
 	results       viewport.Model
+	listTitle     string
 	searchResults []yt.SearchResult
+	// allResults is the list before the :filter and :sort commands
+	allResults    []yt.SearchResult
+	commandInput  textinput.Model
+	listFilter    *filter.Expr
+	listSort      *filter.Sort
 	searchMode    SearchMode
 	selected      int
 	selectedItem  *yt.SearchResult
 	isLoadingSong bool
+	isLoadingList bool
 	width, height int
-	err           error
+	err           error // shown until the next key press
+	config        *config.Config
+	configPath    string
+	deps          Deps
+	keys          keyMap
+	loadingText   string
+	doctorResults []doctor.Result
+	history       *library.History
+	quota         *yt.QuotaTracker
+	quotaWarning  string
+
+	// apiErr is set while no search backend can be used
+	apiErr error
+
+	// The latest background request; see startRequest
+	requestID     int
+	cancelRequest context.CancelFunc
+	// The latest load of more pages or radio tracks; see startBackground
+	backgroundID int
+	cancelLoad   context.CancelFunc
+
+	// Search filter panel; filters holds the applied values
+	filterInputs []textinput.Model
+	filterFocus  int
+	filterErr    error
+	filters      yt.SearchConfig
+
+	// Pagination state for query searches. emptyPages counts the pages in
+	// a row that added nothing to the list, see loadPastEmptyPage.
+	lastQuery     string
+	nextPageToken string
+	isLoadingMore bool
+	emptyPages    int
+
+	// Radio keeps adding related tracks once the list runs out; see
+	// radio.go
+	radio          *services.Radio
+	radioOn        bool
+	isLoadingRadio bool
+	// radioWaiting is set when playback reached the end of the list before
+	// the radio tracks arrived
+	radioWaiting bool
+
+	// The user's playlists, see library.go. addTarget is the track to add
+	// when the picker was opened to add one.
+	myPlaylists    []yt.Playlist
+	playlistCursor int
+	addTarget      *yt.SearchResult
+	// playlistInput names the playlist being created or renamed while
+	// playlistPrompt is set; playlistConfirm is the picker key waiting to
+	// be pressed again before a delete or push
+	playlistInput   textinput.Model
+	playlistPrompt  string
+	playlistConfirm string
+	// editPlaylist is the user's playlist the list shows, if any
+	editPlaylist *yt.Playlist
+	// The lyrics pane, see lyrics.go. lyricsFor is the video the lyrics
+	// were looked up for and lyricsOffset how much sooner lines show.
+	lyricsProvider  lyrics.Provider
+	lyricsConfigErr error
+	lyricsOn        bool
+	lyrics          *lyrics.Lyrics
+	lyricsFor       string
+	lyricsErr       error
+	lyricsOffset    time.Duration
+	isLoadingLyrics bool
+	cancelLyrics    context.CancelFunc
+	lyricsTicking   bool
+
+	// notice is a one-off success message shown in place of the help line
+	// until the next key press
+	notice string
+	// warning is the latest line the services logged, e.g. a quota
+	// fallback, shown like notice
+	warning string
+
+	// playback is AudioService, or daemon while a gplay daemon plays
+	playback playback
+	daemon   *daemonPlayback
 
 	AudioService    *services.AudioService
+	SearchService   services.SearchService
 	PlaylistService services.PlaylistService
+	ChannelService  services.ChannelService
+	LibraryService  services.LibraryService
+	PlaylistEditor  services.PlaylistEditor
 }
 
 // Custom messages for async operations
 type searchStartMsg string
-type searchCompleteMsg []yt.SearchResult
-type searchErrorMsg error
+type searchCompleteMsg struct {
+	id            int
+	title         string
+	results       []yt.SearchResult
+	nextPageToken string
+	// play starts the first result, used when a video URL was pasted
+	play bool
+	// playlist is set when the results are one of the user's playlists
+	playlist *yt.Playlist
+}
+type searchMoreMsg struct {
+	id            int
+	results       []yt.SearchResult
+	nextPageToken string
+}
+type searchErrorMsg struct {
+	id  int
+	err error
+}
+
+// backgroundErrorMsg is a failed load of more pages or radio tracks
+type backgroundErrorMsg struct {
+	id  int
+	err error
+}
+type radioMsg struct {
+	id      int
+	results []yt.SearchResult
+}
 type songCompleteMsg struct{}
+type doctorCompleteMsg struct {
+	id      int
+	results []doctor.Result
+}
 
 // AppModel is an alias for Model for backward compatibility
 type AppModel = Model
@@ -0,0 +1,50 @@
+package yt
+
+import "strings"
+
+// Availability tells why a video cannot be played. It is empty for
+// videos that can.
+type Availability string
+
+const (
+	Available                 Availability = ""
+	AvailabilityPrivate       Availability = "private"
+	AvailabilityDeleted       Availability = "deleted"
+	AvailabilityRegionBlocked Availability = "region-blocked"
+	AvailabilityAgeRestricted Availability = "age-restricted"
+)
+
+// TitleAvailability recognises the placeholder titles YouTube and its
+// frontends give playlist entries whose video is gone
+func TitleAvailability(title string) Availability {
+	switch strings.Trim(title, "[]") {
+	case "Private video":
+		return AvailabilityPrivate
+	case "Deleted video":
+		return AvailabilityDeleted
+	}
+	return Available
+}
+
+// RegionAvailability applies a video's region restriction to the region
+// the user is in. Nothing is blocked when the region is unknown.
+func RegionAvailability(region string, allowed, blocked []string) Availability {
+	if region == "" {
+		return Available
+	}
+	region = strings.ToUpper(region)
+	for _, r := range blocked {
+		if r == region {
+			return AvailabilityRegionBlocked
+		}
+	}
+	if allowed == nil {
+		return Available
+	}
+	for _, r := range allowed {
+		if r == region {
+			return Available
+		}
+	}
+	return AvailabilityRegionBlocked
+}
@@ -0,0 +1,253 @@
+package yt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// CacheMode controls how the response cache is used
+type CacheMode int
+
+const (
+	// CacheNormal serves fresh entries and revalidates stale ones
+	CacheNormal CacheMode = iota
+	// CacheRefresh ignores stored entries but stores new responses
+	CacheRefresh
+	// CacheDisabled neither reads nor writes the cache
+	CacheDisabled
+)
+
+const cacheDirName = "responses"
+
+// Default time to live per API call
+var DefaultCacheTTLs = map[string]time.Duration{
+	CallSearchList:        15 * time.Minute,
+	CallPlaylistItemsList: 6 * time.Hour,
+	CallVideosList:        7 * 24 * time.Hour,
+	CallChannelsList:      24 * time.Hour,
+	CallPlaylistsList:     6 * time.Hour,
+}
+
+// Cache stores API responses on disk, one directory per API call, keyed by
+// a hash of the request parameters
+type Cache struct {
+	dir  string
+	ttls map[string]time.Duration
+	mode CacheMode
+	now  func() time.Time
+}
+
+// cacheEntry is the on-disk format of a cached response
+type cacheEntry struct {
+	ETag     string          `json:"etag"`
+	StoredAt time.Time       `json:"stored_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// CacheStats describes the cached responses of one API call
+type CacheStats struct {
+	Call    string `json:"call"`
+	Entries int    `json:"entries"`
+	Expired int    `json:"expired"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// DefaultCacheDir returns the response cache location in the user cache
+// directory
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "gplay", cacheDirName), nil
+}
+
+// NewCache creates a cache in dir. ttls override DefaultCacheTTLs per call;
+// calls without a TTL are not cached.
+func NewCache(dir string, mode CacheMode, ttls map[string]time.Duration) *Cache {
+	merged := make(map[string]time.Duration, len(DefaultCacheTTLs))
+	for call, ttl := range DefaultCacheTTLs {
+		merged[call] = ttl
+	}
+	for call, ttl := range ttls {
+		merged[call] = ttl
+	}
+	return &Cache{dir: dir, ttls: merged, mode: mode, now: time.Now}
+}
+
+// Fetch returns the response for call and key from the cache when it is
+// fresh. Otherwise it runs fetch, passing the ETag of a stale entry so the
+// request can be revalidated with If-None-Match, and stores the result.
+// A nil client cache simply runs fetch.
+func Fetch[T any](c *Client, call string, key any, fetch func(etag string) (*T, error)) (*T, error) {
+	cache := c.cache
+	if cache == nil || cache.mode == CacheDisabled || cache.ttls[call] <= 0 {
+		return fetch("")
+	}
+	if c.cacheScope != "" {
+		key = []any{c.cacheScope, key}
+	}
+
+	path, err := cache.path(call, key)
+	if err != nil {
+		return fetch("")
+	}
+
+	var entry *cacheEntry
+	if cache.mode != CacheRefresh {
+		entry = cache.read(path)
+	}
+
+	if entry != nil && cache.now().Sub(entry.StoredAt) < cache.ttls[call] {
+		var cached T
+		if err := json.Unmarshal(entry.Data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	etag := ""
+	if entry != nil {
+		etag = entry.ETag
+	}
+
+	response, err := fetch(etag)
+	if googleapi.IsNotModified(err) && entry != nil {
+		var cached T
+		if err := json.Unmarshal(entry.Data, &cached); err == nil {
+			entry.StoredAt = cache.now()
+			cache.write(path, entry)
+			return &cached, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(response); err == nil {
+		var tagged struct {
+			Etag string `json:"etag"`
+		}
+		json.Unmarshal(data, &tagged)
+		cache.write(path, &cacheEntry{ETag: tagged.Etag, StoredAt: cache.now(), Data: data})
+	}
+	return response, nil
+}
+
+// SetCache makes the client store API responses in cache
+func (c *Client) SetCache(cache *Cache) {
+	c.cache = cache
+}
+
+// SetCacheScope keys the client's cached responses by scope as well, so
+// an authorized client never sees what another account cached
+func (c *Client) SetCacheScope(scope string) {
+	c.cacheScope = scope
+}
+
+// Clear removes every cached response
+func (c *Cache) Clear() error {
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("error clearing cache: %w", err)
+	}
+	return nil
+}
+
+// Stats summarises the cache contents per API call
+func (c *Cache) Stats() ([]CacheStats, error) {
+	byCall := map[string]*CacheStats{}
+
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipAll
+		}
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return err
+		}
+
+		call := filepath.Base(filepath.Dir(path))
+		stats, ok := byCall[call]
+		if !ok {
+			stats = &CacheStats{Call: call}
+			byCall[call] = stats
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stats.Entries++
+		stats.Bytes += info.Size()
+		if entry := c.read(path); entry == nil || c.now().Sub(entry.StoredAt) >= c.ttls[call] {
+			stats.Expired++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading cache: %w", err)
+	}
+
+	result := make([]CacheStats, 0, len(byCall))
+	for _, stats := range byCall {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Call < result[j].Call })
+	return result, nil
+}
+
+func (c *Cache) path(call string, key any) (string, error) {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return filepath.Join(c.dir, call, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+func (c *Cache) read(path string) *cacheEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// write stores an entry. Failing to cache is not worth failing the request
+// for, so errors are dropped.
+func (c *Cache) write(path string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	// Write through a temp file so concurrent readers never see half a file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,78 @@
+package yt
+
+import (
+	"testing"
+	"time"
+)
+
+type cachedResponse struct {
+	Etag  string `json:"etag"`
+	Value string `json:"value"`
+}
+
+func TestFetchCacheScope(t *testing.T) {
+	cache := NewCache(t.TempDir(), CacheNormal, nil)
+	fetch := func(value string) func(string) (*cachedResponse, error) {
+		return func(string) (*cachedResponse, error) {
+			return &cachedResponse{Value: value}, nil
+		}
+	}
+	get := func(c *Client, value string) string {
+		t.Helper()
+		response, err := Fetch(c, CallPlaylistItemsList, "PLprivate", fetch(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response.Value
+	}
+
+	alice := &Client{cache: cache, cacheScope: "alice"}
+	bob := &Client{cache: cache, cacheScope: "bob"}
+	anonymous := &Client{cache: cache}
+
+	if got := get(alice, "alice's items"); got != "alice's items" {
+		t.Fatalf("first fetch = %q", got)
+	}
+	if got := get(alice, "fetched again"); got != "alice's items" {
+		t.Errorf("alice was not served from the cache: %q", got)
+	}
+	if got := get(bob, "bob's items"); got != "bob's items" {
+		t.Errorf("bob was served another account's response: %q", got)
+	}
+	if got := get(anonymous, "public items"); got != "public items" {
+		t.Errorf("an unscoped client was served an account's response: %q", got)
+	}
+}
+
+func TestFetchCacheTTL(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(t.TempDir(), CacheNormal, map[string]time.Duration{CallVideosList: time.Hour})
+	cache.now = func() time.Time { return now }
+	c := &Client{cache: cache}
+
+	calls := 0
+	fetch := func(etag string) (*cachedResponse, error) {
+		calls++
+		return &cachedResponse{Etag: "tag", Value: "v"}, nil
+	}
+	for range 2 {
+		if _, err := Fetch(c, CallVideosList, "id", fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fresh entry fetched %d times, want 1", calls)
+	}
+
+	now = now.Add(2 * time.Hour)
+	var sentETag string
+	if _, err := Fetch(c, CallVideosList, "id", func(etag string) (*cachedResponse, error) {
+		sentETag = etag
+		return &cachedResponse{Etag: "tag2", Value: "v2"}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if sentETag != "tag" {
+		t.Errorf("stale entry revalidated with ETag %q, want %q", sentETag, "tag")
+	}
+}
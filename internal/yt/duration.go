@@ -0,0 +1,139 @@
+package yt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseISODuration parses the ISO 8601 durations used by the Data API's
+// contentDetails.duration, e.g. "PT4M13S", "P1DT2H" or "P0D" for live
+// streams. Years and months are rejected since their length varies.
+func ParseISODuration(s string) (time.Duration, error) {
+	if len(s) < 2 || s[0] != 'P' {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+	}
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	units, timeUnits := 0, 0
+
+	for _, r := range s[1:] {
+		switch {
+		case r >= '0' && r <= '9' || r == '.' || r == ',':
+			number += string(r)
+			continue
+		case r == 'T':
+			if inTime || number != "" {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+			}
+			inTime = true
+			continue
+		}
+
+		if number == "" {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+		}
+		value, err := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+		}
+		number = ""
+
+		var unit time.Duration
+		switch {
+		case !inTime && r == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && r == 'D':
+			unit = 24 * time.Hour
+		case inTime && r == 'H':
+			unit = time.Hour
+		case inTime && r == 'M':
+			unit = time.Minute
+		case inTime && r == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("unsupported unit %q in ISO 8601 duration %q", r, s)
+		}
+		total += time.Duration(value * float64(unit))
+		units++
+		if inTime {
+			timeUnits++
+		}
+	}
+
+	if number != "" || units == 0 || inTime && timeUnits == 0 {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+	}
+	return total, nil
+}
+
+// FormatISODuration formats d like the Data API, e.g. 253s becomes
+// "PT4M13S" and zero becomes "P0D"
+func FormatISODuration(d time.Duration) string {
+	total := int64(d / time.Second)
+	if total <= 0 {
+		return "P0D"
+	}
+
+	var b strings.Builder
+	days := total / 86400
+	total %= 86400
+	b.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if total == 0 {
+		return b.String()
+	}
+
+	b.WriteString("T")
+	if h := total / 3600; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+	}
+	if m := total % 3600 / 60; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+	}
+	if s := total % 60; s > 0 {
+		fmt.Fprintf(&b, "%dS", s)
+	}
+	return b.String()
+}
+
+// FormatDuration formats d as a clock, e.g. "4:13" or "1:02:03". Zero,
+// which is what live streams report, is shown as "--:--".
+func FormatDuration(d time.Duration) string {
+	total := int64(d / time.Second)
+	if total <= 0 {
+		return "--:--"
+	}
+	h, m, s := total/3600, total%3600/60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// HumanDuration formats d for totals, e.g. "1h 4m" or "42m 10s"
+func HumanDuration(d time.Duration) string {
+	total := int64(d / time.Second)
+	h, m, s := total/3600, total%3600/60, total%60
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh %dm", h, m)
+	case m > 0:
+		return fmt.Sprintf("%dm %ds", m, s)
+	}
+	return fmt.Sprintf("%ds", s)
+}
+
+// TotalLength adds up the length of videos
+func TotalLength(videos []Video) time.Duration {
+	var total time.Duration
+	for _, v := range videos {
+		total += v.Length
+	}
+	return total
+}
@@ -0,0 +1,80 @@
+package yt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "P0D", want: 0},
+		{in: "PT0S", want: 0},
+		{in: "PT4M13S", want: 4*time.Minute + 13*time.Second},
+		{in: "P1DT2H", want: 26 * time.Hour},
+		{in: "PT2H", want: 2 * time.Hour},
+		{in: "PT1H2M3S", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{in: "P1W", want: 7 * 24 * time.Hour},
+		{in: "PT1.5S", want: 1500 * time.Millisecond},
+		{in: "PT0,5S", want: 500 * time.Millisecond},
+		{in: "", wantErr: true},
+		{in: "P", wantErr: true},
+		{in: "PT", wantErr: true},
+		{in: "4M13S", wantErr: true},
+		{in: "PT4M13", wantErr: true},
+		{in: "P4M", wantErr: true},
+		{in: "PTM", wantErr: true},
+		{in: "PT1HT2M", wantErr: true},
+		{in: "P1DT", wantErr: true},
+		{in: "PT1..5S", wantErr: true},
+		{in: "PT4X", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseISODuration(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseISODuration(%q) = %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseISODuration(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseISODuration(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatISODuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "P0D"},
+		{-time.Second, "P0D"},
+		{999 * time.Millisecond, "P0D"},
+		{4*time.Minute + 13*time.Second, "PT4M13S"},
+		{26 * time.Hour, "P1DT2H"},
+		{2 * time.Hour, "PT2H"},
+		{24 * time.Hour, "P1D"},
+		{time.Hour + 3*time.Second, "PT1H3S"},
+	}
+	for _, tt := range tests {
+		if got := FormatISODuration(tt.in); got != tt.want {
+			t.Errorf("FormatISODuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+		if tt.in < time.Second {
+			continue
+		}
+		// Whole seconds survive a round trip
+		if back, err := ParseISODuration(tt.want); err != nil || back != tt.in {
+			t.Errorf("ParseISODuration(%q) = %v, %v; want %v", tt.want, back, err, tt.in)
+		}
+	}
+}
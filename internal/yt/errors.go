@@ -0,0 +1,96 @@
+package yt
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Kinds of API failure worth telling the user about. Use errors.Is to test
+// for them; the wrapped googleapi.Error stays reachable with errors.As.
+var (
+	ErrQuotaExceeded   = errors.New("YouTube API quota exceeded")
+	ErrKeyInvalid      = errors.New("YouTube API key is invalid")
+	ErrNotFound        = errors.New("not found")
+	ErrPrivatePlaylist = errors.New("playlist is private")
+)
+
+// APIError is a Data API error classified into one of the kinds above
+type APIError struct {
+	Kind error
+	Err  *googleapi.Error
+}
+
+func (e *APIError) Error() string {
+	if e.Err.Message == "" {
+		return e.Kind.Error()
+	}
+	return e.Kind.Error() + ": " + e.Err.Message
+}
+
+// Unwrap exposes both the kind and the original API error
+func (e *APIError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// classifyError wraps API errors of a known kind in an APIError and returns
+// every other error unchanged
+func classifyError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	if kind := errorKind(apiErr); kind != nil {
+		return &APIError{Kind: kind, Err: apiErr}
+	}
+	return err
+}
+
+func errorKind(apiErr *googleapi.Error) error {
+	switch {
+	case hasReason(apiErr, "quotaExceeded", "dailyLimitExceeded"):
+		return ErrQuotaExceeded
+	case hasReason(apiErr, "keyInvalid", "keyExpired"),
+		apiErr.Code == http.StatusBadRequest && strings.Contains(apiErr.Message, "API key"):
+		return ErrKeyInvalid
+	case hasReason(apiErr, "playlistItemsNotAccessible", "playlistForbidden"):
+		return ErrPrivatePlaylist
+	case apiErr.Code == http.StatusNotFound:
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isRetryable reports whether a failed call may succeed when repeated:
+// server errors, rate limiting and network failures
+func isRetryable(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		// Transport errors such as timeouts and reset connections
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	switch {
+	case apiErr.Code >= http.StatusInternalServerError,
+		apiErr.Code == http.StatusTooManyRequests:
+		return true
+	case apiErr.Code == http.StatusForbidden:
+		return hasReason(apiErr, "rateLimitExceeded", "userRateLimitExceeded")
+	}
+	return false
+}
+
+func hasReason(apiErr *googleapi.Error, reasons ...string) bool {
+	for _, item := range apiErr.Errors {
+		for _, reason := range reasons {
+			if item.Reason == reason {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package yt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestClassifyError(t *testing.T) {
+	keyNotValid := apiError(http.StatusBadRequest)
+	keyNotValid.Message = "API key not valid. Please pass a valid API key."
+
+	tests := []struct {
+		name string
+		err  *googleapi.Error
+		want error
+	}{
+		{name: "quotaExceeded", err: apiError(403, "quotaExceeded"), want: ErrQuotaExceeded},
+		{name: "dailyLimitExceeded", err: apiError(403, "dailyLimitExceeded"), want: ErrQuotaExceeded},
+		{name: "keyInvalid", err: apiError(400, "keyInvalid"), want: ErrKeyInvalid},
+		{name: "keyExpired", err: apiError(400, "keyExpired"), want: ErrKeyInvalid},
+		{name: "bad request about the key", err: keyNotValid, want: ErrKeyInvalid},
+		{name: "playlistItemsNotAccessible", err: apiError(403, "playlistItemsNotAccessible"), want: ErrPrivatePlaylist},
+		{name: "playlistForbidden", err: apiError(403, "playlistForbidden"), want: ErrPrivatePlaylist},
+		{name: "404", err: apiError(404, "playlistNotFound"), want: ErrNotFound},
+		{name: "reason wins over 404", err: apiError(404, "quotaExceeded"), want: ErrQuotaExceeded},
+		{name: "500", err: apiError(500, "backendError")},
+		{name: "rate limited", err: apiError(403, "rateLimitExceeded")},
+		{name: "other bad request", err: apiError(400, "invalidParameter")},
+	}
+	kinds := []error{ErrQuotaExceeded, ErrKeyInvalid, ErrPrivatePlaylist, ErrNotFound}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Services wrap errors before they get here
+			got := classifyError(fmt.Errorf("search: %w", tt.err))
+
+			for _, kind := range kinds {
+				if is := errors.Is(got, kind); is != (kind == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", got, kind, is)
+				}
+			}
+			var apiErr *googleapi.Error
+			if !errors.As(got, &apiErr) || apiErr != tt.err {
+				t.Errorf("the googleapi.Error is not reachable from %v", got)
+			}
+			if tt.want != nil && got.Error() != tt.want.Error()+": "+tt.err.Message {
+				t.Errorf("Error() = %q", got.Error())
+			}
+		})
+	}
+}
+
+func TestClassifyErrorLeavesOthers(t *testing.T) {
+	for _, err := range []error{io.EOF, errors.New("boom"), ErrQuotaBudgetExceeded} {
+		if got := classifyError(err); got != err {
+			t.Errorf("classifyError(%v) = %v, want it unchanged", err, got)
+		}
+	}
+}
+
+func TestAPIErrorWithoutMessage(t *testing.T) {
+	err := classifyError(&googleapi.Error{Code: 404})
+	if err.Error() != ErrNotFound.Error() {
+		t.Errorf("Error() = %q, want %q", err.Error(), ErrNotFound.Error())
+	}
+}
@@ -0,0 +1,155 @@
+package yt
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values accepted by the SearchConfig filters
+var (
+	EventTypes       = []string{"live", "upcoming", "completed"}
+	VideoCaptions    = []string{"any", "closedCaption", "none"}
+	VideoDefinitions = []string{"any", "high", "standard"}
+)
+
+// VideoCategories maps the names of the assignable video categories to
+// their IDs, which are the same in every region
+var VideoCategories = map[string]string{
+	"film":          "1",
+	"autos":         "2",
+	"music":         "10",
+	"pets":          "15",
+	"sports":        "17",
+	"travel":        "19",
+	"gaming":        "20",
+	"people":        "22",
+	"comedy":        "23",
+	"entertainment": "24",
+	"news":          "25",
+	"howto":         "26",
+	"education":     "27",
+	"science":       "28",
+	"nonprofits":    "29",
+}
+
+// ParseCategory accepts a category ID or one of the VideoCategories names
+func ParseCategory(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	if _, err := strconv.Atoi(s); err == nil {
+		return s, nil
+	}
+	if id, ok := VideoCategories[strings.ToLower(s)]; ok {
+		return id, nil
+	}
+	names := make([]string, 0, len(VideoCategories))
+	for name := range VideoCategories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return "", fmt.Errorf("unknown category %q (use an ID or one of: %s)", s, strings.Join(names, ", "))
+}
+
+// CategoryID looks up a category by its display name, such as "Music" or
+// "Howto & Style", returning "" when it is not known
+func CategoryID(name string) string {
+	first, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(name)), " ")
+	return VideoCategories[first]
+}
+
+// ParseTimeBound parses a published date filter: an RFC 3339 time, a
+// YYYY-MM-DD date, or an age such as "12h", "7d" or "2w" counted back
+// from now. Ages are rounded down to the hour so the same search made
+// again later still hits the cache.
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return t, nil
+	}
+
+	unit := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+	if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && unit > 0 && n >= 0 {
+		return now.Add(-time.Duration(n) * unit).Truncate(time.Hour), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD, RFC 3339 or an age like 7d)", s)
+}
+
+// Validate checks the filter values before they are sent to a backend
+func (c *SearchConfig) Validate() error {
+	if c.EventType != "" && !slices.Contains(EventTypes, c.EventType) {
+		return fmt.Errorf("invalid event type %q (valid: %s)", c.EventType, strings.Join(EventTypes, ", "))
+	}
+	if c.VideoCaption != "" && !slices.Contains(VideoCaptions, c.VideoCaption) {
+		return fmt.Errorf("invalid caption filter %q (valid: %s)", c.VideoCaption, strings.Join(VideoCaptions, ", "))
+	}
+	if c.VideoDefinition != "" && !slices.Contains(VideoDefinitions, c.VideoDefinition) {
+		return fmt.Errorf("invalid definition %q (valid: %s)", c.VideoDefinition, strings.Join(VideoDefinitions, ", "))
+	}
+	if c.RegionCode != "" && len(c.RegionCode) != 2 {
+		return fmt.Errorf("invalid region %q: expected a two letter country code", c.RegionCode)
+	}
+	if !c.PublishedAfter.IsZero() && !c.PublishedBefore.IsZero() && !c.PublishedAfter.Before(c.PublishedBefore) {
+		return fmt.Errorf("published after %s is not before %s",
+			c.PublishedAfter.Format(time.DateOnly), c.PublishedBefore.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// Filters describes the filters that are set, e.g. "after 2024-01-31"
+func (c *SearchConfig) Filters() []string {
+	var filters []string
+	if !c.PublishedAfter.IsZero() {
+		filters = append(filters, "after "+c.PublishedAfter.Format(time.DateOnly))
+	}
+	if !c.PublishedBefore.IsZero() {
+		filters = append(filters, "before "+c.PublishedBefore.Format(time.DateOnly))
+	}
+	if c.RegionCode != "" {
+		filters = append(filters, "region "+c.RegionCode)
+	}
+	if c.RelevanceLanguage != "" {
+		filters = append(filters, "language "+c.RelevanceLanguage)
+	}
+	if c.VideoCategoryID != "" {
+		filters = append(filters, "category "+c.VideoCategoryID)
+	}
+	if c.EventType != "" {
+		filters = append(filters, c.EventType)
+	}
+	if c.VideoCaption != "" && c.VideoCaption != "any" {
+		filters = append(filters, "captions "+c.VideoCaption)
+	}
+	if c.VideoDefinition != "" && c.VideoDefinition != "any" {
+		filters = append(filters, c.VideoDefinition+" definition")
+	}
+	if c.ChannelID != "" {
+		filters = append(filters, "channel "+c.ChannelID)
+	}
+	return filters
+}
+
+// PublishedWithin reports whether v falls inside the configured date
+// range. Videos without a known publish date are kept.
+func (c *SearchConfig) PublishedWithin(v Video) bool {
+	if v.PublishedAt.IsZero() {
+		return true
+	}
+	if !c.PublishedAfter.IsZero() && v.PublishedAt.Before(c.PublishedAfter) {
+		return false
+	}
+	if !c.PublishedBefore.IsZero() && !v.PublishedAt.Before(c.PublishedBefore) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package yt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeBound(t *testing.T) {
+	cet := time.FixedZone("CET", 3600)
+	now := time.Date(2024, 3, 15, 10, 42, 7, 0, cet)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "", want: time.Time{}},
+		{in: "  ", want: time.Time{}},
+		// Ages count back from now, rounded down to the hour
+		{in: "12h", want: time.Date(2024, 3, 14, 22, 0, 0, 0, cet)},
+		{in: "7d", want: time.Date(2024, 3, 8, 10, 0, 0, 0, cet)},
+		{in: "2w", want: time.Date(2024, 3, 1, 10, 0, 0, 0, cet)},
+		{in: "0d", want: time.Date(2024, 3, 15, 10, 0, 0, 0, cet)},
+		{in: " 1d ", want: time.Date(2024, 3, 14, 10, 0, 0, 0, cet)},
+		// Times and dates are taken as given
+		{in: "2024-01-31T12:30:45Z", want: time.Date(2024, 1, 31, 12, 30, 45, 0, time.UTC)},
+		{in: "2024-01-31T12:30:45+05:00", want: time.Date(2024, 1, 31, 7, 30, 45, 0, time.UTC)},
+		{in: "2024-01-31", want: time.Date(2024, 1, 31, 0, 0, 0, 0, cet)},
+		{in: "7", wantErr: true},
+		{in: "d", wantErr: true},
+		{in: "-1d", wantErr: true},
+		{in: "7y", wantErr: true},
+		{in: "1.5d", wantErr: true},
+		{in: "2024-13-01", wantErr: true},
+		{in: "31/01/2024", wantErr: true},
+		{in: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTimeBound(tt.in, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTimeBound(%q) = %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil || !got.Equal(tt.want) {
+				t.Errorf("ParseTimeBound(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeBoundStableWithinTheHour(t *testing.T) {
+	first, _ := ParseTimeBound("7d", time.Date(2024, 3, 15, 10, 1, 0, 0, time.UTC))
+	second, _ := ParseTimeBound("7d", time.Date(2024, 3, 15, 10, 59, 0, 0, time.UTC))
+	if !first.Equal(second) {
+		t.Errorf("7d resolved to %v and then %v within the same hour", first, second)
+	}
+}
+
+func TestParseCategory(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: ""},
+		{in: "10", want: "10"},
+		{in: " 42 ", want: "42"},
+		{in: "music", want: "10"},
+		{in: "Music", want: "10"},
+		{in: " GAMING ", want: "20"},
+		{in: "howto", want: "26"},
+		{in: "songs", wantErr: true},
+		{in: "Howto & Style", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseCategory(tt.in)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "music") {
+					t.Errorf("ParseCategory(%q) = %q, %v; want an error listing the names", tt.in, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseCategory(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchConfigValidate(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		config  SearchConfig
+		wantErr string
+	}{
+		{name: "no filters"},
+		{
+			name: "every filter",
+			config: SearchConfig{
+				PublishedAfter: jan, PublishedBefore: feb, RegionCode: "DE",
+				EventType: "live", VideoCaption: "closedCaption", VideoDefinition: "high",
+			},
+		},
+		{name: "only after", config: SearchConfig{PublishedAfter: feb}},
+		{name: "after later than before", config: SearchConfig{PublishedAfter: feb, PublishedBefore: jan}, wantErr: "published after 2024-02-01 is not before 2024-01-01"},
+		{name: "after equal to before", config: SearchConfig{PublishedAfter: jan, PublishedBefore: jan}, wantErr: "is not before"},
+		{name: "event type", config: SearchConfig{EventType: "past"}, wantErr: `invalid event type "past"`},
+		{name: "caption", config: SearchConfig{VideoCaption: "yes"}, wantErr: `invalid caption filter "yes"`},
+		{name: "definition", config: SearchConfig{VideoDefinition: "4k"}, wantErr: `invalid definition "4k"`},
+		{name: "enums are case sensitive", config: SearchConfig{EventType: "Live"}, wantErr: "invalid event type"},
+		{name: "region", config: SearchConfig{RegionCode: "DEU"}, wantErr: `invalid region "DEU"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
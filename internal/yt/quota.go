@@ -0,0 +1,281 @@
+package yt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// API calls tracked by the quota tracker
+const (
+	CallSearchList          = "search.list"
+	CallVideosList          = "videos.list"
+	CallPlaylistItemsList   = "playlistItems.list"
+	CallVideoCategoriesList = "videoCategories.list"
+	CallChannelsList        = "channels.list"
+	CallPlaylistsList       = "playlists.list"
+	CallPlaylistItemsInsert = "playlistItems.insert"
+	CallPlaylistItemsUpdate = "playlistItems.update"
+	CallPlaylistItemsDelete = "playlistItems.delete"
+	CallPlaylistsInsert     = "playlists.insert"
+	CallPlaylistsUpdate     = "playlists.update"
+	CallPlaylistsDelete     = "playlists.delete"
+)
+
+// QuotaCosts holds the quota units the Data API charges per call
+var QuotaCosts = map[string]int64{
+	CallSearchList:          100,
+	CallVideosList:          1,
+	CallPlaylistItemsList:   1,
+	CallVideoCategoriesList: 1,
+	CallChannelsList:        1,
+	CallPlaylistsList:       1,
+	CallPlaylistItemsInsert: 50,
+	CallPlaylistItemsUpdate: 50,
+	CallPlaylistItemsDelete: 50,
+	CallPlaylistsInsert:     50,
+	CallPlaylistsUpdate:     50,
+	CallPlaylistsDelete:     50,
+}
+
+const (
+	// DefaultQuotaBudget is the daily quota of a new Google Cloud project
+	DefaultQuotaBudget = int64(10000)
+
+	quotaFileName = "quota.json"
+
+	// quotaLockWait is how long Spend waits for another process to finish
+	// updating the usage file
+	quotaLockWait = 2 * time.Second
+	// quotaLockStale is the age after which a lock is assumed to belong to
+	// a process that died holding it
+	quotaLockStale = 10 * time.Second
+)
+
+// ErrQuotaBudgetExceeded is returned when a call would take the day's usage
+// over the configured budget
+var ErrQuotaBudgetExceeded = errors.New("daily YouTube API quota budget exceeded")
+
+// pacific is where Google resets the daily quota
+var pacific = loadPacific()
+
+func loadPacific() *time.Location {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		// Without tzdata ignore daylight saving rather than fail
+		return time.FixedZone("PST", -8*60*60)
+	}
+	return loc
+}
+
+// QuotaUsage is the usage recorded for a single day
+type QuotaUsage struct {
+	Day   string           `json:"day"` // YYYY-MM-DD in Pacific time
+	Units map[string]int64 `json:"units"`
+	Calls map[string]int64 `json:"calls"`
+}
+
+// Total returns the units used across all calls
+func (u QuotaUsage) Total() int64 {
+	var total int64
+	for _, units := range u.Units {
+		total += units
+	}
+	return total
+}
+
+// CallNames returns the recorded calls in a stable order
+func (u QuotaUsage) CallNames() []string {
+	names := make([]string, 0, len(u.Calls))
+	for name := range u.Calls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// QuotaTracker counts Data API units per day and enforces a budget. Usage
+// is stored on disk so separate gplay processes share the same total.
+type QuotaTracker struct {
+	mu     sync.Mutex
+	path   string
+	budget int64
+	now    func() time.Time
+}
+
+// DefaultQuotaPath returns the usage file location in the user cache
+// directory
+func DefaultQuotaPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "gplay", quotaFileName), nil
+}
+
+// NewQuotaTracker creates a tracker storing usage at path. A budget of zero
+// or less disables enforcement but still counts usage.
+func NewQuotaTracker(path string, budget int64) *QuotaTracker {
+	return &QuotaTracker{path: path, budget: budget, now: time.Now}
+}
+
+// Budget returns the configured daily budget
+func (q *QuotaTracker) Budget() int64 {
+	return q.budget
+}
+
+// Spend records a call, refusing it with ErrQuotaBudgetExceeded when it
+// would go over budget. The usage file is locked for the update so
+// concurrent gplay processes don't lose each other's calls. Failing to
+// read or store usage only logs a warning; it is not worth failing the
+// call for.
+func (q *QuotaTracker) Spend(call string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	unlock, err := q.lock()
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	} else {
+		defer unlock()
+	}
+
+	usage, err := q.load()
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	cost := QuotaCosts[call]
+	if q.budget > 0 && usage.Total()+cost > q.budget {
+		return fmt.Errorf("%w (%d of %d units used today)", ErrQuotaBudgetExceeded, usage.Total(), q.budget)
+	}
+
+	usage.Units[call] += cost
+	usage.Calls[call]++
+	if err := q.save(usage); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return nil
+}
+
+// Usage returns today's usage
+func (q *QuotaTracker) Usage() (QuotaUsage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.load()
+}
+
+// NearBudget reports whether today's usage has reached percent of the
+// budget
+func (q *QuotaTracker) NearBudget(percent int) bool {
+	if q.budget <= 0 {
+		return false
+	}
+	usage, err := q.Usage()
+	if err != nil {
+		return false
+	}
+	return usage.Total()*100 >= q.budget*int64(percent)
+}
+
+// NextReset returns when the quota next resets, midnight Pacific time
+func (q *QuotaTracker) NextReset() time.Time {
+	now := q.now().In(pacific)
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, pacific)
+}
+
+func (q *QuotaTracker) today() string {
+	return q.now().In(pacific).Format(time.DateOnly)
+}
+
+// load reads the usage file, starting a fresh day when the stored one is
+// stale
+func (q *QuotaTracker) load() (QuotaUsage, error) {
+	usage := QuotaUsage{Day: q.today(), Units: map[string]int64{}, Calls: map[string]int64{}}
+
+	data, err := os.ReadFile(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return usage, nil
+	}
+	if err != nil {
+		return usage, fmt.Errorf("error reading quota usage: %w", err)
+	}
+
+	var stored QuotaUsage
+	if err := json.Unmarshal(data, &stored); err != nil {
+		// A corrupt file should not block API access
+		return usage, nil
+	}
+	if stored.Day != usage.Day {
+		return usage, nil
+	}
+	if stored.Units != nil {
+		usage.Units = stored.Units
+	}
+	if stored.Calls != nil {
+		usage.Calls = stored.Calls
+	}
+	return usage, nil
+}
+
+// save replaces the usage file through a temp file so readers never see
+// half of it
+func (q *QuotaTracker) save(usage QuotaUsage) error {
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding quota usage: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0o755); err != nil {
+		return fmt.Errorf("error creating quota directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), ".quota-*")
+	if err != nil {
+		return fmt.Errorf("error writing quota usage: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), q.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error writing quota usage: %w", err)
+	}
+	return nil
+}
+
+// lock takes the usage file's lock, a file next to it created exclusively.
+// A lock older than quotaLockStale is broken.
+func (q *QuotaTracker) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(q.path), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating quota directory: %w", err)
+	}
+	path := q.path + ".lock"
+	deadline := time.Now().Add(quotaLockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("error locking quota usage: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > quotaLockStale {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the quota usage lock %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
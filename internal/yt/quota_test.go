@@ -0,0 +1,92 @@
+package yt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQuotaSpendConcurrentTrackers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), quotaFileName)
+
+	// Separate trackers share nothing but the file, like separate processes
+	const trackers, calls = 8, 25
+	var wg sync.WaitGroup
+	for range trackers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q := NewQuotaTracker(path, 0)
+			for range calls {
+				if err := q.Spend(CallVideosList); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	usage, err := NewQuotaTracker(path, 0).Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := usage.Calls[CallVideosList]; got != trackers*calls {
+		t.Errorf("recorded %d calls, want %d", got, trackers*calls)
+	}
+	if _, err := os.Stat(path + ".lock"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestQuotaSpendBudget(t *testing.T) {
+	q := NewQuotaTracker(filepath.Join(t.TempDir(), quotaFileName), 150)
+	if err := q.Spend(CallSearchList); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Spend(CallSearchList); !errors.Is(err, ErrQuotaBudgetExceeded) {
+		t.Errorf("error = %v, want ErrQuotaBudgetExceeded", err)
+	}
+	if err := q.Spend(CallVideosList); err != nil {
+		t.Errorf("a call within budget was refused: %v", err)
+	}
+}
+
+func TestQuotaSpendUnwritable(t *testing.T) {
+	// The usage file's directory is a regular file, so nothing can be stored
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	q := NewQuotaTracker(filepath.Join(parent, quotaFileName), 100)
+	if err := q.Spend(CallVideosList); err != nil {
+		t.Errorf("Spend failed because usage could not be stored: %v", err)
+	}
+}
+
+func TestQuotaStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), quotaFileName)
+	lock := path + ".lock"
+	if err := os.WriteFile(lock, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * quotaLockStale)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	q := NewQuotaTracker(path, 0)
+	if err := q.Spend(CallVideosList); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := q.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Calls[CallVideosList] != 1 {
+		t.Errorf("calls = %d after breaking a stale lock, want 1", usage.Calls[CallVideosList])
+	}
+}
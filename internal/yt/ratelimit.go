@@ -0,0 +1,79 @@
+package yt
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Default client side request rates per API call, in requests per second
+var DefaultRateLimits = map[string]float64{
+	CallSearchList:        2,
+	CallVideosList:        10,
+	CallPlaylistItemsList: 10,
+}
+
+// RateLimiter spaces out API calls with a token bucket per call. Each bucket
+// holds up to one second worth of requests so short bursts go through
+// unthrottled.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter. rates override DefaultRateLimits per
+// call; a rate of zero or less leaves that call unlimited.
+func NewRateLimiter(rates map[string]float64) *RateLimiter {
+	r := &RateLimiter{buckets: map[string]*bucket{}, now: time.Now}
+	for call, rate := range DefaultRateLimits {
+		r.setRate(call, rate)
+	}
+	for call, rate := range rates {
+		r.setRate(call, rate)
+	}
+	return r
+}
+
+func (r *RateLimiter) setRate(call string, rate float64) {
+	if rate <= 0 {
+		delete(r.buckets, call)
+		return
+	}
+	burst := math.Max(1, math.Floor(rate))
+	r.buckets[call] = &bucket{rate: rate, burst: burst, tokens: burst, last: r.now()}
+}
+
+// Wait blocks until call may be made or ctx is done
+func (r *RateLimiter) Wait(ctx context.Context, call string) error {
+	return sleep(ctx, r.reserve(call))
+}
+
+// reserve takes a token, returning how long the caller has to wait for it.
+// Tokens may go negative so concurrent callers queue up in order.
+func (r *RateLimiter) reserve(call string) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.buckets[call]
+	if !ok {
+		return 0
+	}
+
+	now := r.now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
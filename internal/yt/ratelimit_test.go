@@ -0,0 +1,105 @@
+package yt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock tests move by hand
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func testLimiter(rates map[string]float64) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r := &RateLimiter{buckets: map[string]*bucket{}, now: clock.now}
+	for call, rate := range rates {
+		r.setRate(call, rate)
+	}
+	return r, clock
+}
+
+func TestRateLimiterBurstThenRate(t *testing.T) {
+	r, _ := testLimiter(map[string]float64{CallSearchList: 2})
+
+	// A burst of one second worth goes through, then callers queue up half
+	// a second apart
+	want := []time.Duration{0, 0, 500 * time.Millisecond, time.Second, 1500 * time.Millisecond}
+	for i, w := range want {
+		if got := r.reserve(CallSearchList); got != w {
+			t.Errorf("call %d waits %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	r, clock := testLimiter(map[string]float64{CallVideosList: 4})
+	for range 4 {
+		r.reserve(CallVideosList)
+	}
+	if got := r.reserve(CallVideosList); got != 250*time.Millisecond {
+		t.Fatalf("fifth call waits %v, want 250ms", got)
+	}
+
+	// The queued call takes the first token that comes back
+	clock.advance(500 * time.Millisecond)
+	if got := r.reserve(CallVideosList); got != 0 {
+		t.Errorf("after 500ms a call waits %v, want none", got)
+	}
+
+	// An idle bucket fills up to its burst and no further
+	clock.advance(time.Hour)
+	for i := range 4 {
+		if got := r.reserve(CallVideosList); got != 0 {
+			t.Errorf("call %d after an hour waits %v, want none", i, got)
+		}
+	}
+	if got := r.reserve(CallVideosList); got != 250*time.Millisecond {
+		t.Errorf("call past the burst waits %v, want 250ms", got)
+	}
+}
+
+func TestRateLimiterSlowRate(t *testing.T) {
+	// Rates under one per second still allow one call at once
+	r, _ := testLimiter(map[string]float64{CallSearchList: 0.5})
+	if got := r.reserve(CallSearchList); got != 0 {
+		t.Errorf("first call waits %v, want none", got)
+	}
+	if got := r.reserve(CallSearchList); got != 2*time.Second {
+		t.Errorf("second call waits %v, want 2s", got)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	r := NewRateLimiter(map[string]float64{CallSearchList: 0})
+	for range 100 {
+		if got := r.reserve(CallSearchList); got != 0 {
+			t.Fatalf("search with rate 0 waits %v, want none", got)
+		}
+		if got := r.reserve("channels.list"); got != 0 {
+			t.Fatalf("call without a rate waits %v, want none", got)
+		}
+	}
+	// Calls not overridden keep their default
+	for range int(DefaultRateLimits[CallVideosList]) {
+		r.reserve(CallVideosList)
+	}
+	if got := r.reserve(CallVideosList); got == 0 {
+		t.Error("videos.list is unlimited, want its default rate")
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	r, _ := testLimiter(map[string]float64{CallSearchList: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := r.Wait(ctx, CallSearchList); err != nil {
+		t.Fatalf("first Wait = %v", err)
+	}
+	cancel()
+	if err := r.Wait(ctx, CallSearchList); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,231 @@
+package yt
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RefKind is what a Ref points at
+type RefKind int
+
+const (
+	RefVideo RefKind = iota + 1
+	RefPlaylist
+	RefChannel
+)
+
+func (k RefKind) String() string {
+	switch k {
+	case RefVideo:
+		return "video"
+	case RefPlaylist:
+		return "playlist"
+	case RefChannel:
+		return "channel"
+	}
+	return "unknown"
+}
+
+// Ref is a normalized reference to a video, playlist or channel
+type Ref struct {
+	Kind RefKind
+	// VideoID is set for videos
+	VideoID string
+	// PlaylistID is set for playlists, and for videos opened from one
+	// through a list= parameter
+	PlaylistID string
+	// ChannelID or Handle (with its @) is set for channels
+	ChannelID string
+	Handle    string
+}
+
+// youtubeHosts are the hosts whose URLs ParseURL understands
+var youtubeHosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"music.youtube.com":        true,
+	"youtu.be":                 true,
+	"www.youtube-nocookie.com": true,
+}
+
+// playlistPrefixes start the IDs of regular playlists, albums, mixes and
+// the automatic uploads and likes playlists
+var playlistPrefixes = []string{"PL", "OLAK5uy_", "RD", "UU", "LL", "FL", "OL"}
+
+const videoIDLength = 11
+
+// ParseRef normalizes anything a user might paste: watch, youtu.be, music,
+// shorts, live and embed URLs, playlist URLs, channel URLs, @handles and
+// bare video, playlist or channel IDs
+func ParseRef(s string) (Ref, error) {
+	s = strings.TrimSpace(s)
+	if ref, err := ParseURL(s); err == nil {
+		return ref, nil
+	} else if looksLikeURL(s) {
+		return Ref{}, err
+	}
+
+	switch {
+	case strings.HasPrefix(s, "@") && len(s) > 1 && !strings.ContainsAny(s, " /?&="):
+		return Ref{Kind: RefChannel, Handle: s}, nil
+	case !isIDString(s):
+		return Ref{}, fmt.Errorf("%q is not a YouTube URL or ID", s)
+	case len(s) == videoIDLength:
+		return Ref{Kind: RefVideo, VideoID: s}, nil
+	case strings.HasPrefix(s, "UC") && len(s) > 2:
+		return Ref{Kind: RefChannel, ChannelID: s}, nil
+	case hasPlaylistPrefix(s):
+		return Ref{Kind: RefPlaylist, PlaylistID: s}, nil
+	}
+	return Ref{}, fmt.Errorf("%q is not a YouTube URL or ID", s)
+}
+
+// ParseURL is ParseRef restricted to URLs, so free text such as a search
+// query is never mistaken for an ID. The scheme may be left out.
+func ParseURL(s string) (Ref, error) {
+	s = strings.TrimSpace(s)
+	raw := s
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || !youtubeHosts[strings.ToLower(u.Hostname())] {
+		return Ref{}, fmt.Errorf("%q is not a YouTube URL", s)
+	}
+
+	query := u.Query()
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	list := query.Get("list")
+	if list != "" && !isIDString(list) {
+		return Ref{}, fmt.Errorf("invalid playlist ID %q in %q", list, s)
+	}
+
+	if strings.EqualFold(u.Hostname(), "youtu.be") {
+		return videoRef(parts[0], list, s)
+	}
+
+	switch {
+	case parts[0] == "watch" && query.Get("v") != "":
+		return videoRef(query.Get("v"), list, s)
+	case len(parts) >= 2 && (parts[0] == "shorts" || parts[0] == "live" || parts[0] == "embed" || parts[0] == "v"):
+		return videoRef(parts[1], list, s)
+	case list != "":
+		return Ref{Kind: RefPlaylist, PlaylistID: list}, nil
+	case len(parts) >= 2 && (parts[0] == "channel" || parts[0] == "browse") && strings.HasPrefix(parts[1], "UC") && isIDString(parts[1]):
+		return Ref{Kind: RefChannel, ChannelID: parts[1]}, nil
+	case strings.HasPrefix(parts[0], "@") && len(parts[0]) > 1:
+		return Ref{Kind: RefChannel, Handle: parts[0]}, nil
+	}
+	return Ref{}, fmt.Errorf("no video, playlist or channel in %q", s)
+}
+
+// videoRef is the Ref of a video found in the URL s
+func videoRef(id, list, s string) (Ref, error) {
+	if !isIDString(id) {
+		return Ref{}, fmt.Errorf("no video ID in %q", s)
+	}
+	return Ref{Kind: RefVideo, VideoID: id, PlaylistID: list}, nil
+}
+
+// ParseVideoID returns the video ID of a video URL or bare ID
+func ParseVideoID(s string) (string, error) {
+	ref, err := ParseRef(s)
+	if err != nil {
+		return "", err
+	}
+	if ref.Kind != RefVideo {
+		return "", fmt.Errorf("%q is a %s, not a video", s, ref.Kind)
+	}
+	return ref.VideoID, nil
+}
+
+// ParsePlaylistID returns the playlist ID of a playlist URL, a watch URL
+// with a list= parameter or a bare ID
+func ParsePlaylistID(s string) (string, error) {
+	ref, err := ParseRef(s)
+	if err != nil {
+		return "", err
+	}
+	if ref.PlaylistID == "" {
+		return "", fmt.Errorf("%q is a %s, not a playlist", s, ref.Kind)
+	}
+	return ref.PlaylistID, nil
+}
+
+// URL returns the canonical youtube.com URL of the reference
+func (r Ref) URL() string {
+	switch r.Kind {
+	case RefVideo:
+		return VideoURL(r.VideoID)
+	case RefPlaylist:
+		return PlaylistURL(r.PlaylistID)
+	case RefChannel:
+		return ChannelURL(r.ID())
+	}
+	return ""
+}
+
+// ID returns the ID, or the handle, the reference is about
+func (r Ref) ID() string {
+	switch r.Kind {
+	case RefVideo:
+		return r.VideoID
+	case RefPlaylist:
+		return r.PlaylistID
+	case RefChannel:
+		if r.Handle != "" {
+			return r.Handle
+		}
+		return r.ChannelID
+	}
+	return ""
+}
+
+// VideoURL returns the watch URL of a video ID
+func VideoURL(id string) string {
+	return "https://www.youtube.com/watch?v=" + url.QueryEscape(id)
+}
+
+// PlaylistURL returns the URL of a playlist ID
+func PlaylistURL(id string) string {
+	return "https://www.youtube.com/playlist?list=" + url.QueryEscape(id)
+}
+
+// ChannelURL returns the URL of a channel ID or @handle
+func ChannelURL(ref string) string {
+	if strings.HasPrefix(ref, "@") {
+		return "https://www.youtube.com/" + ref
+	}
+	return "https://www.youtube.com/channel/" + ref
+}
+
+// looksLikeURL reports whether s was meant as a URL rather than an ID
+func looksLikeURL(s string) bool {
+	return strings.Contains(s, "/") || strings.Contains(s, "://")
+}
+
+// isIDString reports whether s only uses the characters of YouTube IDs
+func isIDString(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func hasPlaylistPrefix(s string) bool {
+	for _, prefix := range playlistPrefixes {
+		if strings.HasPrefix(s, prefix) && len(s) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package yt
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testVideo    = "dQw4w9WgXcQ"
+	testList     = "PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG"
+	testAlbum    = "OLAK5uy_kZ4f1Ns4QnCsi1Wg4GRLAb0Fv4WeM7TTM"
+	testChannel  = "UCuAXFkgsw1L7xaCfnd5JJOw"
+	testHandle   = "@RickAstleyYT"
+	testListTail = "abcdefghijklmnop"
+)
+
+func TestParseRef(t *testing.T) {
+	video := Ref{Kind: RefVideo, VideoID: testVideo}
+	videoInList := Ref{Kind: RefVideo, VideoID: testVideo, PlaylistID: testList}
+	playlist := Ref{Kind: RefPlaylist, PlaylistID: testList}
+
+	tests := []struct {
+		in   string
+		want Ref
+	}{
+		// Watch URLs, with and without a scheme, host or list
+		{"https://www.youtube.com/watch?v=" + testVideo, video},
+		{"https://www.youtube.com/watch?v=" + testVideo + "&list=" + testList, videoInList},
+		{"https://www.youtube.com/watch?list=" + testList + "&v=" + testVideo + "&index=3", videoInList},
+		{"http://youtube.com/watch?v=" + testVideo, video},
+		{"youtube.com/watch?v=" + testVideo + "&t=42s", video},
+		{"m.youtube.com/watch?v=" + testVideo + "&list=" + testList, videoInList},
+		{"HTTPS://WWW.YOUTUBE.COM/watch?v=" + testVideo, video},
+		{"  https://www.youtube.com/watch?v=" + testVideo + "  ", video},
+
+		// Short links
+		{"https://youtu.be/" + testVideo, video},
+		{"youtu.be/" + testVideo + "?t=10", video},
+		{"https://youtu.be/" + testVideo + "?list=" + testList, videoInList},
+
+		// YouTube Music
+		{"https://music.youtube.com/watch?v=" + testVideo, video},
+		{"https://music.youtube.com/watch?v=" + testVideo + "&list=" + testList, videoInList},
+		{"https://music.youtube.com/playlist?list=" + testAlbum, Ref{Kind: RefPlaylist, PlaylistID: testAlbum}},
+
+		// Shorts, live, embed and old /v/ URLs
+		{"https://www.youtube.com/shorts/" + testVideo, video},
+		{"https://www.youtube.com/shorts/" + testVideo + "?list=" + testList, videoInList},
+		{"https://www.youtube.com/live/" + testVideo + "?si=abc", video},
+		{"https://www.youtube.com/live/" + testVideo + "?list=" + testList, videoInList},
+		{"https://www.youtube.com/embed/" + testVideo, video},
+		{"https://www.youtube-nocookie.com/embed/" + testVideo + "?list=" + testList, videoInList},
+		{"https://www.youtube.com/v/" + testVideo, video},
+		{"https://www.youtube.com/v/" + testVideo + "?list=" + testList, videoInList},
+
+		// Playlists
+		{"https://www.youtube.com/playlist?list=" + testList, playlist},
+		{"youtube.com/playlist?list=" + testList + "&si=x", playlist},
+
+		// Channels
+		{"https://www.youtube.com/channel/" + testChannel, Ref{Kind: RefChannel, ChannelID: testChannel}},
+		{"https://www.youtube.com/channel/" + testChannel + "/videos", Ref{Kind: RefChannel, ChannelID: testChannel}},
+		{"https://music.youtube.com/browse/" + testChannel, Ref{Kind: RefChannel, ChannelID: testChannel}},
+		{"https://www.youtube.com/" + testHandle, Ref{Kind: RefChannel, Handle: testHandle}},
+		{"youtube.com/" + testHandle + "/videos", Ref{Kind: RefChannel, Handle: testHandle}},
+
+		// Bare IDs and handles
+		{testVideo, video},
+		{" " + testVideo + "\n", video},
+		{testChannel, Ref{Kind: RefChannel, ChannelID: testChannel}},
+		{testHandle, Ref{Kind: RefChannel, Handle: testHandle}},
+		{testList, playlist},
+		{testAlbum, Ref{Kind: RefPlaylist, PlaylistID: testAlbum}},
+		// Eleven characters always read as a video
+		{"PLabcdefghi", Ref{Kind: RefVideo, VideoID: "PLabcdefghi"}},
+	}
+	for _, prefix := range playlistPrefixes {
+		id := prefix + testListTail
+		tests = append(tests, struct {
+			in   string
+			want Ref
+		}{id, Ref{Kind: RefPlaylist, PlaylistID: id}})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRef(tt.in)
+			if err != nil {
+				t.Fatalf("ParseRef(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRef(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRefErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		// Other hosts
+		{"https://example.com/watch?v=" + testVideo, "is not a YouTube URL"},
+		{"https://youtube.com.example.com/watch?v=" + testVideo, "is not a YouTube URL"},
+		{"https://notyoutube.com/watch?v=" + testVideo, "is not a YouTube URL"},
+		{"vimeo.com/12345", "is not a YouTube URL"},
+		{"ftp://youtu.be:badport/" + testVideo, "is not a YouTube URL"},
+
+		// YouTube URLs without anything to open
+		{"https://www.youtube.com/", "no video, playlist or channel"},
+		{"https://www.youtube.com/watch", "no video, playlist or channel"},
+		{"https://www.youtube.com/results?search_query=lofi", "no video, playlist or channel"},
+		{"https://www.youtube.com/channel/not-a-channel", "no video, playlist or channel"},
+		{"https://www.youtube.com/@", "no video, playlist or channel"},
+		{"https://youtu.be/", "no video ID"},
+		{"https://www.youtube.com/watch?v=bad%20id", "no video ID"},
+		{"https://www.youtube.com/shorts/bad%3Cid%3E", "no video ID"},
+		{"https://www.youtube.com/playlist?list=PL%26x%3Dy", "invalid playlist ID"},
+		{"https://www.youtube.com/watch?v=" + testVideo + "&list=PL%20x", "invalid playlist ID"},
+
+		// Junk that is neither a URL nor an ID
+		{"", "is not a YouTube URL or ID"},
+		{"   ", "is not a YouTube URL or ID"},
+		{"lofi hip hop", "is not a YouTube URL or ID"},
+		{"@", "is not a YouTube URL or ID"},
+		{"@two words", "is not a YouTube URL or ID"},
+		{"short", "is not a YouTube URL or ID"},
+		{"UC", "is not a YouTube URL or ID"},
+		{"LL", "is not a YouTube URL or ID"},
+		{"dQw4w9WgXc!", "is not a YouTube URL or ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRef(tt.in)
+			if err == nil {
+				t.Fatalf("ParseRef(%q) = %+v, want an error", tt.in, got)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseRef(%q) error = %q, want it to mention %q", tt.in, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseURLRejectsBareIDs(t *testing.T) {
+	for _, in := range []string{testVideo, testList, testChannel, testHandle, "lofi hip hop"} {
+		if got, err := ParseURL(in); err == nil {
+			t.Errorf("ParseURL(%q) = %+v, want an error", in, got)
+		}
+	}
+}
+
+func TestParseVideoAndPlaylistID(t *testing.T) {
+	if id, err := ParseVideoID("https://youtu.be/" + testVideo); err != nil || id != testVideo {
+		t.Errorf("ParseVideoID = %q, %v; want %q", id, err, testVideo)
+	}
+	if _, err := ParseVideoID(testList); err == nil {
+		t.Error("ParseVideoID accepted a playlist")
+	}
+	if id, err := ParsePlaylistID("https://www.youtube.com/watch?v=" + testVideo + "&list=" + testList); err != nil || id != testList {
+		t.Errorf("ParsePlaylistID = %q, %v; want %q", id, err, testList)
+	}
+	if _, err := ParsePlaylistID(testVideo); err == nil {
+		t.Error("ParsePlaylistID accepted a video")
+	}
+}
+
+func TestRefURLRoundTrip(t *testing.T) {
+	for _, in := range []string{testVideo, testList, testChannel, testHandle} {
+		ref, err := ParseRef(in)
+		if err != nil {
+			t.Fatalf("ParseRef(%q): %v", in, err)
+		}
+		back, err := ParseURL(ref.URL())
+		if err != nil || back != ref {
+			t.Errorf("ParseURL(%q) = %+v, %v; want %+v", ref.URL(), back, err, ref)
+		}
+		if ref.ID() != in {
+			t.Errorf("ID() = %q, want %q", ref.ID(), in)
+		}
+	}
+}
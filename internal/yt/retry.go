@@ -0,0 +1,94 @@
+package yt
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// RetryPolicy controls how failed API calls are repeated
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the wait before the first retry, doubling each time
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between retries
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy retries three times, waiting about 0.5s, 1s and 2s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+// backoff returns the wait before retry number attempt (0 based): the
+// exponential delay with the upper half randomised so clients that failed
+// together do not retry together
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << attempt
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// Do makes an API call through the client: it waits for the rate limiter,
+// records the call with the quota tracker, retries transient failures and
+// classifies the final error. Pass the Do method of a youtube call that has
+// been given ctx with its Context method; ctx also cuts waits short.
+func Do[T any](ctx context.Context, c *Client, call string, do func(...googleapi.CallOption) (*T, error)) (*T, error) {
+	wait := c.sleep
+	if wait == nil {
+		wait = sleep
+	}
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx, call); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.Spend(call); err != nil {
+			return nil, err
+		}
+
+		response, err := do()
+		if err == nil || googleapi.IsNotModified(err) {
+			// A 304 is handled by the cache and must keep its type
+			return response, err
+		}
+		if ctx.Err() != nil {
+			// The failure is the cancellation, not something to retry
+			return nil, ctx.Err()
+		}
+		if attempt >= c.retry.MaxRetries || !isRetryable(err) {
+			return nil, classifyError(err)
+		}
+		if err := wait(ctx, c.retry.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for d, returning early with the context error when ctx is
+// done first
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,224 @@
+package yt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// apiError builds the error a youtube call returns for code, with reasons
+// as its error items
+func apiError(code int, reasons ...string) *googleapi.Error {
+	err := &googleapi.Error{Code: code, Message: http.StatusText(code)}
+	for _, reason := range reasons {
+		err.Errors = append(err.Errors, googleapi.ErrorItem{Reason: reason})
+	}
+	return err
+}
+
+// fakeCall fails with errs in turn, then succeeds, counting its calls
+type fakeCall struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeCall) do(...googleapi.CallOption) (*string, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	ok := "ok"
+	return &ok, nil
+}
+
+// testClient returns a client that records the waits between retries
+// instead of sleeping
+func testClient(waits *[]time.Duration) *Client {
+	return &Client{
+		retry: DefaultRetryPolicy,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			*waits = append(*waits, d)
+			return ctx.Err()
+		},
+	}
+}
+
+func TestDoRetries(t *testing.T) {
+	timeout := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("i/o timeout")}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "success", wantCalls: 1},
+		{name: "500 then success", errs: []error{apiError(500)}, wantCalls: 2},
+		{name: "502 and 503 then success", errs: []error{apiError(502), apiError(503)}, wantCalls: 3},
+		{name: "429 then success", errs: []error{apiError(429)}, wantCalls: 2},
+		{name: "403 rate limit then success", errs: []error{apiError(403, "rateLimitExceeded")}, wantCalls: 2},
+		{name: "403 user rate limit then success", errs: []error{apiError(403, "userRateLimitExceeded")}, wantCalls: 2},
+		{name: "network error then success", errs: []error{timeout}, wantCalls: 2},
+		{name: "unexpected EOF then success", errs: []error{fmt.Errorf("read body: %w", io.ErrUnexpectedEOF)}, wantCalls: 2},
+		{
+			name:      "500 until retries run out",
+			errs:      []error{apiError(500), apiError(500), apiError(500), apiError(500), apiError(500)},
+			wantCalls: 4,
+			wantErr:   apiError(500),
+		},
+		{name: "400 is not retried", errs: []error{apiError(400)}, wantCalls: 1, wantErr: apiError(400)},
+		{name: "404 is not retried", errs: []error{apiError(404)}, wantCalls: 1, wantErr: ErrNotFound},
+		{name: "403 quota is not retried", errs: []error{apiError(403, "quotaExceeded")}, wantCalls: 1, wantErr: ErrQuotaExceeded},
+		{name: "403 forbidden is not retried", errs: []error{apiError(403, "forbidden")}, wantCalls: 1, wantErr: apiError(403)},
+		{name: "other errors are not retried", errs: []error{io.EOF}, wantCalls: 1, wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var waits []time.Duration
+			c := testClient(&waits)
+			call := &fakeCall{errs: tt.errs}
+
+			got, err := Do(context.Background(), c, CallVideosList, call.do)
+			if call.calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", call.calls, tt.wantCalls)
+			}
+			if len(waits) != tt.wantCalls-1 {
+				t.Errorf("waited %d times, want %d", len(waits), tt.wantCalls-1)
+			}
+			if tt.wantErr == nil {
+				if err != nil || got == nil || *got != "ok" {
+					t.Fatalf("Do = %v, %v; want ok", got, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Do = %v, want an error", *got)
+			}
+			var want *googleapi.Error
+			if errors.As(tt.wantErr, &want) {
+				var apiErr *googleapi.Error
+				if !errors.As(err, &apiErr) || apiErr.Code != want.Code {
+					t.Errorf("Do error = %v, want the API error %d", err, want.Code)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDoNotModified(t *testing.T) {
+	var waits []time.Duration
+	call := &fakeCall{errs: []error{apiError(http.StatusNotModified)}}
+	_, err := Do(context.Background(), testClient(&waits), CallVideosList, call.do)
+	// The cache needs the 304 as it came
+	if !googleapi.IsNotModified(err) || call.calls != 1 {
+		t.Errorf("Do = %v after %d calls, want a 304 after 1", err, call.calls)
+	}
+}
+
+func TestDoBackoff(t *testing.T) {
+	var waits []time.Duration
+	c := testClient(&waits)
+	c.retry = RetryPolicy{MaxRetries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 500 * time.Millisecond}
+	call := &fakeCall{errs: []error{apiError(500), apiError(500), apiError(500), apiError(500), apiError(500), apiError(500)}}
+
+	if _, err := Do(context.Background(), c, CallVideosList, call.do); err == nil {
+		t.Fatal("Do succeeded, want the last 500")
+	}
+	// Each wait is in the upper half of the doubled delay, capped at
+	// MaxDelay
+	ceilings := []time.Duration{100, 200, 400, 500, 500}
+	if len(waits) != len(ceilings) {
+		t.Fatalf("waited %v, want %d waits", waits, len(ceilings))
+	}
+	for i, ceiling := range ceilings {
+		ceiling *= time.Millisecond
+		if waits[i] < ceiling/2 || waits[i] > ceiling {
+			t.Errorf("wait %d = %v, want between %v and %v", i, waits[i], ceiling/2, ceiling)
+		}
+	}
+}
+
+func TestDoNoRetries(t *testing.T) {
+	var waits []time.Duration
+	c := testClient(&waits)
+	c.SetRetryPolicy(RetryPolicy{})
+	call := &fakeCall{errs: []error{apiError(503)}}
+	if _, err := Do(context.Background(), c, CallVideosList, call.do); err == nil || call.calls != 1 {
+		t.Errorf("Do = %v after %d calls, want the 503 after 1", err, call.calls)
+	}
+}
+
+func TestDoCancellation(t *testing.T) {
+	t.Run("during the wait", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		c := &Client{
+			retry: DefaultRetryPolicy,
+			sleep: func(ctx context.Context, d time.Duration) error {
+				cancel()
+				return ctx.Err()
+			},
+		}
+		call := &fakeCall{errs: []error{apiError(500), apiError(500)}}
+		_, err := Do(ctx, c, CallVideosList, call.do)
+		if !errors.Is(err, context.Canceled) || call.calls != 1 {
+			t.Errorf("Do = %v after %d calls, want context.Canceled after 1", err, call.calls)
+		}
+	})
+
+	t.Run("during the call", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var waits []time.Duration
+		calls := 0
+		_, err := Do(ctx, testClient(&waits), CallVideosList, func(...googleapi.CallOption) (*string, error) {
+			calls++
+			cancel()
+			// What a transport returns when its request is cancelled
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: context.Canceled}
+		})
+		if !errors.Is(err, context.Canceled) || calls != 1 || len(waits) != 0 {
+			t.Errorf("Do = %v after %d calls and %d waits, want context.Canceled after 1 call", err, calls, len(waits))
+		}
+	})
+
+	t.Run("before the call", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		limiter := NewRateLimiter(map[string]float64{CallSearchList: 1})
+		limiter.reserve(CallSearchList) // the next search has to wait
+		var waits []time.Duration
+		c := testClient(&waits)
+		c.SetRateLimiter(limiter)
+		call := &fakeCall{}
+		if _, err := Do(ctx, c, CallSearchList, call.do); !errors.Is(err, context.Canceled) || call.calls != 0 {
+			t.Errorf("Do = %v after %d calls, want context.Canceled before any", err, call.calls)
+		}
+	})
+}
+
+func TestSleep(t *testing.T) {
+	if err := sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("sleep = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("sleep on a cancelled context = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("sleep on a cancelled context waited")
+	}
+	if err := sleep(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("sleep(0) on a cancelled context = %v, want context.Canceled", err)
+	}
+}
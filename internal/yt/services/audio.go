@@ -2,29 +2,62 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hajimehoshi/oto/v2"
 )
 
 const (
-	defaultSampleRate = 48000
-	defaultChannels   = 2
-	defaultBufferSize = "64k"
-	defaultLogLevel   = "warning"
+	defaultSampleRate  = 48000
+	defaultChannels    = 2
+	defaultBufferSize  = "64k"
+	defaultLogLevel    = "warning"
+	defaultAudioFormat = "bestaudio[ext=m4a]/bestaudio[ext=webm]/bestaudio"
+
+	// bytesPerSample is the size of one sample of the s16le output
+	// requested from FFmpeg, oto.FormatSignedInt16LE
+	bytesPerSample = 2
 )
 
+// AudioOptions configures decoding and playback
+type AudioOptions struct {
+	SampleRate int
+	Channels   int
+	BufferSize string
+	Format     string // yt-dlp format selector
+	YtDlpPath  string
+}
+
+// DefaultAudioOptions returns the built-in playback settings
+func DefaultAudioOptions() AudioOptions {
+	return AudioOptions{
+		SampleRate: defaultSampleRate,
+		Channels:   defaultChannels,
+		BufferSize: defaultBufferSize,
+		Format:     defaultAudioFormat,
+		YtDlpPath:  defaultYtDlpPath,
+	}
+}
+
 // AudioService handles audio playback operations
 type AudioService struct {
 	mu              sync.Mutex
+	options         AudioOptions
+	resolver        StreamResolver
 	context         *oto.Context
 	player          oto.Player
+	decoded         *countingReader
 	isPlaying       bool
 	isPaused        bool
+	isLive          bool
 	currentSong     string
 	cancelFunc      context.CancelFunc
 	cmd             *exec.Cmd
@@ -35,7 +68,31 @@ type AudioService struct {
 }
 
 func NewAudioService() *AudioService {
+	return NewAudioServiceWithOptions(DefaultAudioOptions())
+}
+
+// NewAudioServiceWithOptions creates an audio service with custom playback
+// settings. Zero fields fall back to the defaults.
+func NewAudioServiceWithOptions(options AudioOptions) *AudioService {
+	defaults := DefaultAudioOptions()
+	if options.SampleRate <= 0 {
+		options.SampleRate = defaults.SampleRate
+	}
+	if options.Channels <= 0 {
+		options.Channels = defaults.Channels
+	}
+	if options.BufferSize == "" {
+		options.BufferSize = defaults.BufferSize
+	}
+	if options.Format == "" {
+		options.Format = defaults.Format
+	}
+	if options.YtDlpPath == "" {
+		options.YtDlpPath = defaults.YtDlpPath
+	}
+
 	return &AudioService{
+		options:      options,
 		streamDone:   make(chan bool, 1),
 		songComplete: make(chan bool, 1),
 	}
@@ -48,14 +105,29 @@ func (s *AudioService) SetOnComplete(callback func()) {
 	s.onComplete = callback
 }
 
+// SetStreamResolver makes GetStreamURL ask resolver before falling back to
+// yt-dlp. Passing nil restores plain yt-dlp resolution.
+func (s *AudioService) SetStreamResolver(resolver StreamResolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resolver = resolver
+}
+
 // GetSongCompleteChannel returns the channel that signals when a song completes
 func (s *AudioService) GetSongCompleteChannel() <-chan bool {
 	return s.songComplete
 }
 
+// PlayStream resolves and plays url, replacing whatever is playing
 func (s *AudioService) PlayStream(url string) error {
+	return s.PlayStreamContext(context.Background(), url)
+}
+
+// PlayStreamContext is PlayStream with a context that ends playback when
+// done. Resolving the stream is aborted with context.Canceled when playback
+// is stopped or replaced meanwhile.
+func (s *AudioService) PlayStreamContext(ctx context.Context, url string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
 	// stop any previous playback first
 	s.stopInternal()
@@ -63,34 +135,45 @@ func (s *AudioService) PlayStream(url string) error {
 	// Reset manually stopped flag for new song
 	s.manuallyStopped = false
 
-	if s.context == nil {
-		audioContext, ready, err := oto.NewContext(defaultSampleRate, defaultChannels, defaultChannels)
-		if err != nil {
-			return fmt.Errorf("failed to create audio context: %w", err)
-		}
-		<-ready
-		s.context = audioContext
+	if err := s.ensureContext(); err != nil {
+		s.mu.Unlock()
+		return err
 	}
 
-	streamURL, err := s.GetStreamURL(url)
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancelFunc = cancel
+	resolver := s.resolver
+	s.mu.Unlock()
+
+	// Resolving can take seconds, so Stop and other calls are not held
+	// up meanwhile
+	streamURL, err := s.resolveStream(ctx, resolver, url)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ctx.Err() != nil {
+		// Stopped or replaced, which already cancelled ctx
+		return ctx.Err()
+	}
 	if err != nil {
+		cancel()
+		s.cancelFunc = nil
 		return fmt.Errorf("error getting stream url: %w", err)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	s.cancelFunc = cancel
-
-	// Use better FFmpeg options for streaming
+	// Use better FFmpeg options for streaming. Live streams only come with
+	// combined formats, so any video is dropped before decoding.
 	s.cmd = exec.CommandContext(ctx, "ffmpeg",
 		"-reconnect", "1",
 		"-reconnect_streamed", "1",
 		"-reconnect_delay_max", "5",
 		"-i", streamURL,
+		"-vn",
 		"-f", "s16le",
-		"-ar", fmt.Sprintf("%d", defaultSampleRate),
-		"-ac", fmt.Sprintf("%d", defaultChannels),
+		"-ar", fmt.Sprintf("%d", s.options.SampleRate),
+		"-ac", fmt.Sprintf("%d", s.options.Channels),
 		"-acodec", "pcm_s16le",
-		"-bufsize", defaultBufferSize,
+		"-bufsize", s.options.BufferSize,
 		"-loglevel", defaultLogLevel,
 		"pipe:1",
 	)
@@ -101,13 +184,15 @@ func (s *AudioService) PlayStream(url string) error {
 	}
 
 	if err := s.cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start FFmpeg: %w", err)
+		return fmt.Errorf("failed to start FFmpeg: %w (run 'gplay doctor' to check dependencies)", err)
 	}
 
-	// Create a buffered reader to help with streaming
-	s.player = s.context.NewPlayer(stdout)
+	// Count the decoded bytes handed to the player to tell the position
+	s.decoded = &countingReader{r: stdout}
+	s.player = s.context.NewPlayer(s.decoded)
 	s.isPlaying = true
 	s.isPaused = false
+	s.isLive = isLiveStream(streamURL)
 	s.currentSong = url
 
 	s.player.Play()
@@ -118,6 +203,29 @@ func (s *AudioService) PlayStream(url string) error {
 	return nil
 }
 
+// ensureContext creates the audio context on first use. Oto allows a single
+// context per process, so it is kept for the lifetime of the service.
+func (s *AudioService) ensureContext() error {
+	if s.context != nil {
+		return nil
+	}
+
+	audioContext, ready, err := oto.NewContext(s.options.SampleRate, s.options.Channels, oto.FormatSignedInt16LE)
+	if err != nil {
+		return fmt.Errorf("failed to create audio context: %w", err)
+	}
+	<-ready
+	s.context = audioContext
+	return nil
+}
+
+// ProbeDevice opens the audio device without playing anything
+func (s *AudioService) ProbeDevice() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ensureContext()
+}
+
 func (s *AudioService) monitorStream(songUrl string) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -155,12 +263,16 @@ func (s *AudioService) monitorStream(songUrl string) {
 
 	// Only reset state if we're still the current song
 	if s.currentSong == songUrl {
+		live := s.isLive
 		s.isPlaying = false
 		s.isPaused = false
+		s.isLive = false
 		s.currentSong = ""
 
-		// Signal song completion if it finished naturally (not manually stopped)
-		if !s.manuallyStopped {
+		// Signal song completion if it finished naturally (not manually
+		// stopped). A live stream ending is the broadcast going off air or
+		// the connection dropping, not a song coming to its end.
+		if !s.manuallyStopped && !live {
 			select {
 			case s.songComplete <- true:
 			default:
@@ -176,17 +288,45 @@ func (s *AudioService) monitorStream(songUrl string) {
 }
 
 // GetStreamURL retrieves the direct stream URL for a YouTube video
-func (s *AudioService) GetStreamURL(url string) (string, error) {
-	// Use better format selection to avoid issues
-	cmd := exec.Command("yt-dlp",
+func (s *AudioService) GetStreamURL(ctx context.Context, url string) (string, error) {
+	s.mu.Lock()
+	resolver := s.resolver
+	s.mu.Unlock()
+	return s.resolveStream(ctx, resolver, url)
+}
+
+// resolveStream asks resolver for the stream URL, falling back to yt-dlp.
+// yt-dlp is killed when ctx is done.
+func (s *AudioService) resolveStream(ctx context.Context, resolver StreamResolver, url string) (string, error) {
+	if resolver != nil {
+		streamURL, err := resolver.StreamURL(ctx, url)
+		if err == nil {
+			return streamURL, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		log.Printf("Warning: stream resolver failed, using yt-dlp: %v", err)
+	}
+
+	// Use better format selection to avoid issues. Live streams have no
+	// audio-only formats, hence the fallback to the best combined one.
+	cmd := exec.CommandContext(ctx, s.options.YtDlpPath,
 		"--get-url",
-		"-f", "bestaudio[ext=m4a]/bestaudio[ext=webm]/bestaudio",
+		"-f", s.options.Format+"/best",
 		"--no-playlist",
 		url)
 
 	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
 	if err != nil {
-		return "", fmt.Errorf("error getting stream url: %v", err)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("yt-dlp failed: %s (run 'gplay doctor' to check dependencies)", lastLine(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("error running yt-dlp: %w (run 'gplay doctor' to check dependencies)", err)
 	}
 
 	streamURL := strings.TrimSpace(string(output))
@@ -197,6 +337,19 @@ func (s *AudioService) GetStreamURL(url string) (string, error) {
 	return streamURL, nil
 }
 
+// isLiveStream reports whether a stream URL points at an HLS or DASH
+// manifest, which is how YouTube serves live streams
+func isLiveStream(streamURL string) bool {
+	return strings.Contains(streamURL, ".m3u8") || strings.Contains(streamURL, "/manifest/")
+}
+
+// lastLine returns the last non-empty line of command output, which is
+// where yt-dlp puts its ERROR message
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
 func (s *AudioService) Stop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -247,9 +400,11 @@ func (s *AudioService) stopInternal() {
 		s.player.Close()
 		s.player = nil
 	}
+	s.decoded = nil
 
 	s.isPlaying = false
 	s.isPaused = false
+	s.isLive = false
 	s.currentSong = ""
 }
 
@@ -265,6 +420,39 @@ func (s *AudioService) IsPaused() bool {
 	return s.isPaused
 }
 
+// IsLive reports whether the current stream is a live broadcast, which has
+// no duration and never completes on its own
+func (s *AudioService) IsLive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isLive
+}
+
+// Position returns how far into the current song playback is: the audio
+// decoded so far minus what is still waiting in the player buffer
+func (s *AudioService) Position() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.player == nil || s.decoded == nil {
+		return 0
+	}
+	played := s.decoded.n.Load() - int64(s.player.UnplayedBufferSize())
+	bytesPerSecond := int64(s.options.SampleRate * s.options.Channels * bytesPerSample)
+	return time.Duration(max(played, 0)) * time.Second / time.Duration(bytesPerSecond)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
 // GetCurrentSong returns the URL of the currently loaded song
 func (s *AudioService) GetCurrentSong() string {
 	s.mu.Lock()
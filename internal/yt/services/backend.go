@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/filter"
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const (
+	// BackendAuto uses the Data API when a key is configured and falls back
+	// to yt-dlp when there is no key or the quota runs out
+	BackendAuto = "auto"
+	// BackendAPI only uses the YouTube Data API
+	BackendAPI = "api"
+	// BackendYtDlp only uses yt-dlp and needs no API key
+	BackendYtDlp = "ytdlp"
+	// BackendInvidious uses the configured Invidious instances
+	BackendInvidious = "invidious"
+	// BackendPiped uses the configured Piped API instances
+	BackendPiped = "piped"
+)
+
+// Backends lists the valid backend names
+var Backends = []string{BackendAuto, BackendAPI, BackendYtDlp, BackendInvidious, BackendPiped}
+
+// BackendOptions selects and configures a backend
+type BackendOptions struct {
+	Name   string
+	APIKey string
+	// Authorized, when set, makes the API backend call the Data API as the
+	// logged in user instead of with APIKey
+	Authorized *http.Client
+	// LoginID identifies the login Authorized acts for; it keeps that
+	// account's cached responses apart from everyone else's
+	LoginID   string
+	YtDlpPath string
+	// Instances are the base URLs tried in order by the Invidious and
+	// Piped backends
+	Instances []string
+	// Quota, when set, accounts and limits Data API usage
+	Quota *yt.QuotaTracker
+	// Cache, when set, stores Data API responses on disk
+	Cache *yt.Cache
+	// Retry controls how transient Data API failures are retried; the zero
+	// value disables retries
+	Retry yt.RetryPolicy
+	// RateLimit, when set, spaces out Data API calls
+	RateLimit *yt.RateLimiter
+	// Exclude, when set, drops matching videos from search results and
+	// mixes
+	Exclude *filter.Rules
+	// ExcludeLists also applies Exclude to playlists and channels, which
+	// the user picked as a whole
+	ExcludeLists bool
+	// Region is the ISO 3166-1 code of the user's region, used to mark
+	// videos blocked there
+	Region string
+}
+
+// Backend bundles the search, playlist and channel services of one
+// implementation
+type Backend struct {
+	Name     string
+	Search   SearchService
+	Playlist PlaylistService
+	Channel  ChannelService
+	// Streams is set when the backend can resolve audio streams itself
+	Streams StreamResolver
+	// Mix lists the mixes radio plays from
+	Mix MixService
+	// Library is set when an account is logged in
+	Library LibraryService
+	// Editor writes to the user's playlists and is set with Library
+	Editor PlaylistEditor
+}
+
+// NewBackend creates the services for the requested backend
+func NewBackend(opts BackendOptions, maxResults int64) (*Backend, error) {
+	backend, err := newBackend(opts, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	// The Data API cannot list mixes, so they always come from yt-dlp
+	backend.Mix = NewYtDlpMixService(opts.YtDlpPath)
+	// Whatever searches, only the Data API knows the user's library
+	if opts.Authorized != nil {
+		client, err := newAPIClient(opts)
+		if err != nil {
+			return nil, err
+		}
+		backend.Library = NewLibraryService(client)
+		backend.Editor = NewPlaylistEditor(client)
+	}
+	if opts.Exclude == nil {
+		return backend, nil
+	}
+	backend.Search = &excludingSearchService{SearchService: backend.Search, rules: opts.Exclude}
+	backend.Mix = &excludingMixService{MixService: backend.Mix, rules: opts.Exclude}
+	if opts.ExcludeLists {
+		backend.Playlist = &excludingPlaylistService{PlaylistService: backend.Playlist, rules: opts.Exclude}
+		backend.Channel = &excludingChannelService{ChannelService: backend.Channel, rules: opts.Exclude}
+	}
+	return backend, nil
+}
+
+func newBackend(opts BackendOptions, maxResults int64) (*Backend, error) {
+	switch opts.Name {
+	case BackendAPI:
+		client, err := newAPIClient(opts)
+		if err != nil {
+			return nil, err
+		}
+		return &Backend{
+			Name:     BackendAPI,
+			Search:   NewSearchService(client, maxResults),
+			Playlist: NewPlaylistService(client),
+			Channel:  NewChannelService(client),
+		}, nil
+
+	case BackendYtDlp:
+		return &Backend{
+			Name:     BackendYtDlp,
+			Search:   NewYtDlpSearchService(opts.YtDlpPath, maxResults),
+			Playlist: NewYtDlpPlaylistService(opts.YtDlpPath),
+			Channel:  NewYtDlpChannelService(opts.YtDlpPath),
+		}, nil
+
+	case BackendInvidious:
+		service, err := NewInvidiousService(opts.Instances, nil, maxResults)
+		if err != nil {
+			return nil, err
+		}
+		return &Backend{Name: BackendInvidious, Search: service, Playlist: service, Channel: service, Streams: service}, nil
+
+	case BackendPiped:
+		service, err := NewPipedService(opts.Instances, nil, maxResults)
+		if err != nil {
+			return nil, err
+		}
+		return &Backend{Name: BackendPiped, Search: service, Playlist: service, Channel: service, Streams: service}, nil
+
+	case BackendAuto, "":
+		fallback, _ := newBackend(BackendOptions{Name: BackendYtDlp, YtDlpPath: opts.YtDlpPath}, maxResults)
+		if opts.APIKey == "" && opts.Authorized == nil {
+			return fallback, nil
+		}
+		apiOpts := opts
+		apiOpts.Name = BackendAPI
+		primary, err := newBackend(apiOpts, maxResults)
+		if err != nil {
+			return fallback, nil
+		}
+		return &Backend{
+			Name:     BackendAuto,
+			Search:   &fallbackSearchService{primary: primary.Search, fallback: fallback.Search},
+			Playlist: &fallbackPlaylistService{primary: primary.Playlist, fallback: fallback.Playlist},
+			Channel:  &fallbackChannelService{primary: primary.Channel, fallback: fallback.Channel},
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown backend %q (valid: %s)", opts.Name, strings.Join(Backends, ", "))
+	}
+}
+
+// newAPIClient creates a Data API client, authorized as the user when
+// logged in
+func newAPIClient(opts BackendOptions) (*yt.Client, error) {
+	var client *yt.Client
+	var err error
+	if opts.Authorized != nil {
+		client, err = yt.NewAuthorizedClient(opts.Authorized)
+	} else {
+		client, err = yt.NewClientWithKey(opts.APIKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	client.SetQuotaTracker(opts.Quota)
+	// Responses to an authorized client may be private, so they are only
+	// cached under the login they belong to
+	switch {
+	case opts.Authorized == nil:
+		client.SetCache(opts.Cache)
+	case opts.LoginID != "":
+		client.SetCache(opts.Cache)
+		client.SetCacheScope(opts.LoginID)
+	}
+	client.SetRetryPolicy(opts.Retry)
+	client.SetRateLimiter(opts.RateLimit)
+	client.SetRegion(opts.Region)
+	return client, nil
+}
+
+// fallbackSearchService uses yt-dlp when the API quota is exhausted
+type fallbackSearchService struct {
+	primary  SearchService
+	fallback SearchService
+}
+
+func (f *fallbackSearchService) Search(query string) (*yt.SearchResponse, error) {
+	return f.SearchContext(context.Background(), query)
+}
+
+func (f *fallbackSearchService) SearchWithConfig(query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return f.SearchWithConfigContext(context.Background(), query, config)
+}
+
+func (f *fallbackSearchService) SearchContext(ctx context.Context, query string) (*yt.SearchResponse, error) {
+	response, err := f.primary.SearchContext(ctx, query)
+	if isQuotaError(err) {
+		log.Printf("Warning: YouTube API quota exceeded, searching with yt-dlp")
+		return f.fallback.SearchContext(ctx, query)
+	}
+	return response, err
+}
+
+func (f *fallbackSearchService) SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	response, err := f.primary.SearchWithConfigContext(ctx, query, config)
+	// API page tokens mean nothing to yt-dlp, and starting it from the top
+	// would repeat the results already shown, so later pages fail instead
+	if isQuotaError(err) && config.PageToken == "" {
+		log.Printf("Warning: YouTube API quota exceeded, searching with yt-dlp")
+		return f.fallback.SearchWithConfigContext(ctx, query, config)
+	}
+	return response, err
+}
+
+// fallbackPlaylistService uses yt-dlp when the API quota is exhausted
+type fallbackPlaylistService struct {
+	primary  PlaylistService
+	fallback PlaylistService
+}
+
+func (f *fallbackPlaylistService) GetPlaylistItems(playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	return f.GetPlaylistItemsContext(context.Background(), playlistID, maxResults)
+}
+
+func (f *fallbackPlaylistService) GetPlaylistItemsContext(ctx context.Context, playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	results, err := f.primary.GetPlaylistItemsContext(ctx, playlistID, maxResults)
+	if isQuotaError(err) {
+		log.Printf("Warning: YouTube API quota exceeded, loading playlist with yt-dlp")
+		return f.fallback.GetPlaylistItemsContext(ctx, playlistID, maxResults)
+	}
+	return results, err
+}
+
+// isQuotaError reports whether err is the API refusing a request because
+// the daily quota is used up, or gplay refusing it because the configured
+// budget is
+func isQuotaError(err error) bool {
+	return errors.Is(err, yt.ErrQuotaExceeded) || errors.Is(err, yt.ErrQuotaBudgetExceeded)
+}
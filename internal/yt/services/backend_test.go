@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alanpramil7/gplay/internal/filter"
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+func TestNewBackendExcludeScope(t *testing.T) {
+	rules, err := filter.NewRules(true, false, 0, 0, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend, err := NewBackend(BackendOptions{Name: BackendYtDlp, Exclude: rules}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := backend.Search.(*excludingSearchService); !ok {
+		t.Error("search is not filtered")
+	}
+	if _, ok := backend.Mix.(*excludingMixService); !ok {
+		t.Error("mixes are not filtered")
+	}
+	if _, ok := backend.Playlist.(*excludingPlaylistService); ok {
+		t.Error("playlists are filtered without ExcludeLists")
+	}
+	if _, ok := backend.Channel.(*excludingChannelService); ok {
+		t.Error("channels are filtered without ExcludeLists")
+	}
+
+	backend, err = NewBackend(BackendOptions{Name: BackendYtDlp, Exclude: rules, ExcludeLists: true}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := backend.Playlist.(*excludingPlaylistService); !ok {
+		t.Error("playlists are not filtered with ExcludeLists")
+	}
+	if _, ok := backend.Channel.(*excludingChannelService); !ok {
+		t.Error("channels are not filtered with ExcludeLists")
+	}
+}
+
+// fakeSearch answers searches with response or err and records the queries
+// and configs it was called with
+type fakeSearch struct {
+	response *yt.SearchResponse
+	err      error
+	queries  []string
+	configs  []yt.SearchConfig
+}
+
+func (f *fakeSearch) Search(query string) (*yt.SearchResponse, error) {
+	return f.SearchContext(context.Background(), query)
+}
+
+func (f *fakeSearch) SearchWithConfig(query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return f.SearchWithConfigContext(context.Background(), query, config)
+}
+
+func (f *fakeSearch) SearchContext(ctx context.Context, query string) (*yt.SearchResponse, error) {
+	return f.SearchWithConfigContext(ctx, query, &yt.SearchConfig{})
+}
+
+func (f *fakeSearch) SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	f.queries = append(f.queries, query)
+	f.configs = append(f.configs, *config)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.response, nil
+}
+
+func TestFallbackSearch(t *testing.T) {
+	apiPage := &yt.SearchResponse{Videos: []yt.Video{{ID: "api"}}, NextPageToken: "CAUQAA"}
+	ytdlpPage := &yt.SearchResponse{Videos: []yt.Video{{ID: "ytdlp"}}, NextPageToken: "ytdlp:5"}
+
+	tests := []struct {
+		name         string
+		apiErr       error
+		pageToken    string
+		want         string
+		wantErr      error
+		wantFallback bool
+	}{
+		{name: "api", want: "api"},
+		{name: "quota on the first page", apiErr: yt.ErrQuotaExceeded, want: "ytdlp", wantFallback: true},
+		{name: "budget on the first page", apiErr: yt.ErrQuotaBudgetExceeded, want: "ytdlp", wantFallback: true},
+		// yt-dlp would start over and repeat what is already shown
+		{name: "quota on a later page", apiErr: yt.ErrQuotaExceeded, pageToken: "CAUQAA", wantErr: yt.ErrQuotaExceeded},
+		{name: "other errors", apiErr: yt.ErrKeyInvalid, wantErr: yt.ErrKeyInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeSearch{response: apiPage, err: tt.apiErr}
+			fallback := &fakeSearch{response: ytdlpPage}
+			f := &fallbackSearchService{primary: primary, fallback: fallback}
+
+			got, err := f.SearchWithConfigContext(context.Background(), "lofi", &yt.SearchConfig{PageToken: tt.pageToken})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("search = %v, %v; want %v", got, err, tt.wantErr)
+				}
+			} else if err != nil || got.Videos[0].ID != tt.want {
+				t.Errorf("search = %v, %v; want the %s page", got, err, tt.want)
+			}
+			if called := len(fallback.configs) > 0; called != tt.wantFallback {
+				t.Errorf("fallback called = %v, want %v", called, tt.wantFallback)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+	"google.golang.org/api/youtube/v3"
+)
+
+// ChannelService interface for YouTube channel operations
+type ChannelService interface {
+	// Channel looks up a channel by ID, @handle or channel URL
+	Channel(ctx context.Context, ref string) (*yt.Channel, error)
+	// Uploads lists the newest uploads of a channel, at most limit of them
+	Uploads(ctx context.Context, channel *yt.Channel, limit int) ([]yt.Video, error)
+	// Playlists lists the public playlists of a channel
+	Playlists(ctx context.Context, channel *yt.Channel) ([]yt.Playlist, error)
+	// SearchChannel searches the videos of a channel
+	SearchChannel(ctx context.Context, channel *yt.Channel, query string, config *yt.SearchConfig) (*yt.SearchResponse, error)
+}
+
+type channelService struct {
+	client   *yt.Client
+	search   *searchService
+	playlist *playlistService
+}
+
+// NewChannelService creates a new channel service instance
+func NewChannelService(client *yt.Client) ChannelService {
+	return &channelService{
+		client:   client,
+		search:   NewSearchService(client, maxSearchPageSize).(*searchService),
+		playlist: NewPlaylistService(client).(*playlistService),
+	}
+}
+
+// Channel looks up a channel by ID, @handle or channel URL
+func (c *channelService) Channel(ctx context.Context, ref string) (*yt.Channel, error) {
+	id, handle, err := parseChannelRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	call := c.client.Service().Channels.List([]string{"snippet", "contentDetails", "statistics"}).
+		Context(ctx)
+	if id != "" {
+		call = call.Id(id)
+	} else {
+		call = call.ForHandle(handle)
+	}
+
+	response, err := yt.Fetch(c.client, yt.CallChannelsList, []string{id, handle}, func(etag string) (*youtube.ChannelListResponse, error) {
+		if etag != "" {
+			call = call.IfNoneMatch(etag)
+		}
+		return yt.Do(ctx, c.client, yt.CallChannelsList, call.Do)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching channel: %w", err)
+	}
+	if len(response.Items) == 0 {
+		return nil, fmt.Errorf("channel %s: %w", ref, yt.ErrNotFound)
+	}
+
+	return apiChannel(response.Items[0]), nil
+}
+
+// apiChannel maps a Channels.List item
+func apiChannel(item *youtube.Channel) *yt.Channel {
+	channel := &yt.Channel{
+		ID:  item.Id,
+		URL: yt.ChannelURL(item.Id),
+	}
+	if item.Snippet != nil {
+		channel.Title = item.Snippet.Title
+		channel.Handle = item.Snippet.CustomUrl
+		channel.Description = item.Snippet.Description
+		channel.ThumbnailURL = getBestThumbnail(item.Snippet.Thumbnails)
+	}
+	if item.Statistics != nil {
+		channel.SubscriberCount = item.Statistics.SubscriberCount
+		channel.VideoCount = item.Statistics.VideoCount
+	}
+	if item.ContentDetails != nil && item.ContentDetails.RelatedPlaylists != nil {
+		channel.UploadsPlaylistID = item.ContentDetails.RelatedPlaylists.Uploads
+	}
+	return channel
+}
+
+// Uploads lists the newest uploads through the channel's uploads playlist
+func (c *channelService) Uploads(ctx context.Context, channel *yt.Channel, limit int) ([]yt.Video, error) {
+	uploads := channel.UploadsPlaylistID
+	if uploads == "" {
+		// Every channel's uploads playlist is its ID with UC swapped for UU
+		uploads = "UU" + strings.TrimPrefix(channel.ID, "UC")
+	}
+	return c.playlist.items(ctx, uploads, min(int64(limit), MaxPlaylistPageSize), limit)
+}
+
+// Playlists lists the public playlists of a channel
+func (c *channelService) Playlists(ctx context.Context, channel *yt.Channel) ([]yt.Playlist, error) {
+	service := c.client.Service()
+
+	playlists := []yt.Playlist{}
+	nextPageToken := ""
+
+	for {
+		call := service.Playlists.List([]string{"snippet", "contentDetails"}).
+			ChannelId(channel.ID).
+			MaxResults(MaxPlaylistPageSize).
+			PageToken(nextPageToken).
+			Context(ctx)
+
+		cacheKey := []string{channel.ID, nextPageToken}
+		response, err := yt.Fetch(c.client, yt.CallPlaylistsList, cacheKey, func(etag string) (*youtube.PlaylistListResponse, error) {
+			if etag != "" {
+				call = call.IfNoneMatch(etag)
+			}
+			return yt.Do(ctx, c.client, yt.CallPlaylistsList, call.Do)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching playlists: %w", err)
+		}
+
+		for _, item := range response.Items {
+			playlists = append(playlists, apiPlaylist(item))
+		}
+
+		if response.NextPageToken == "" {
+			break
+		}
+		nextPageToken = response.NextPageToken
+	}
+
+	return playlists, nil
+}
+
+// apiPlaylist maps a Playlists.List item
+func apiPlaylist(item *youtube.Playlist) yt.Playlist {
+	playlist := yt.Playlist{ID: item.Id, URL: yt.PlaylistURL(item.Id)}
+	if item.Snippet != nil {
+		playlist.Title = item.Snippet.Title
+		playlist.Description = item.Snippet.Description
+		playlist.ChannelTitle = item.Snippet.ChannelTitle
+		playlist.ChannelID = item.Snippet.ChannelId
+		playlist.ThumbnailURL = getBestThumbnail(item.Snippet.Thumbnails)
+	}
+	if item.ContentDetails != nil {
+		playlist.ItemCount = item.ContentDetails.ItemCount
+	}
+	return playlist
+}
+
+// SearchChannel searches the videos of a channel
+func (c *channelService) SearchChannel(ctx context.Context, channel *yt.Channel, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	channelConfig := *config
+	channelConfig.ChannelID = channel.ID
+	return c.search.SearchWithConfigContext(ctx, query, &channelConfig)
+}
+
+// fallbackChannelService uses yt-dlp when the API quota is exhausted
+type fallbackChannelService struct {
+	primary  ChannelService
+	fallback ChannelService
+}
+
+func (f *fallbackChannelService) Channel(ctx context.Context, ref string) (*yt.Channel, error) {
+	channel, err := f.primary.Channel(ctx, ref)
+	if isQuotaError(err) {
+		log.Printf("Warning: YouTube API quota exceeded, loading channel with yt-dlp")
+		return f.fallback.Channel(ctx, ref)
+	}
+	return channel, err
+}
+
+func (f *fallbackChannelService) Uploads(ctx context.Context, channel *yt.Channel, limit int) ([]yt.Video, error) {
+	videos, err := f.primary.Uploads(ctx, channel, limit)
+	if isQuotaError(err) {
+		log.Printf("Warning: YouTube API quota exceeded, loading channel with yt-dlp")
+		return f.fallback.Uploads(ctx, channel, limit)
+	}
+	return videos, err
+}
+
+func (f *fallbackChannelService) Playlists(ctx context.Context, channel *yt.Channel) ([]yt.Playlist, error) {
+	playlists, err := f.primary.Playlists(ctx, channel)
+	if isQuotaError(err) {
+		log.Printf("Warning: YouTube API quota exceeded, loading channel with yt-dlp")
+		return f.fallback.Playlists(ctx, channel)
+	}
+	return playlists, err
+}
+
+func (f *fallbackChannelService) SearchChannel(ctx context.Context, channel *yt.Channel, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	response, err := f.primary.SearchChannel(ctx, channel, query, config)
+	// As with searches, later pages would start yt-dlp over from the top
+	if isQuotaError(err) && config.PageToken == "" {
+		log.Printf("Warning: YouTube API quota exceeded, searching channel with yt-dlp")
+		return f.fallback.SearchChannel(ctx, channel, query, config)
+	}
+	return response, err
+}
+
+// parseChannelRef splits a channel reference into a channel ID or a handle
+func parseChannelRef(ref string) (id, handle string, err error) {
+	parsed, err := yt.ParseRef(ref)
+	if err != nil {
+		return "", "", err
+	}
+	if parsed.Kind != yt.RefChannel {
+		return "", "", fmt.Errorf("%q is a %s, not a channel", ref, parsed.Kind)
+	}
+	return parsed.ChannelID, parsed.Handle, nil
+}
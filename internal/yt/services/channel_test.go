@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const (
+	testChannelID = "UCuAXFkgsw1L7xaCfnd5JJOw"
+	testUploads   = "UUuAXFkgsw1L7xaCfnd5JJOw"
+)
+
+// handleChannels serves Channels.List for one channel, known by its ID and
+// @band
+func handleChannels(api *fakeDataAPI) {
+	api.handle("channels", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		items := []map[string]any{}
+		if q.Get("id") == testChannelID || q.Get("forHandle") == "@band" {
+			items = append(items, map[string]any{
+				"id":             testChannelID,
+				"snippet":        map[string]any{"title": "Band", "customUrl": "@band"},
+				"statistics":     map[string]any{"subscriberCount": "1200", "videoCount": "40"},
+				"contentDetails": map[string]any{"relatedPlaylists": map[string]any{"uploads": testUploads}},
+			})
+		}
+		writeAPIJSON(w, map[string]any{"items": items})
+	})
+}
+
+// handlePlaylistItems serves total items of any playlist in pages of
+// maxResults
+func handlePlaylistItems(api *fakeDataAPI, total int) {
+	api.handle("playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		size, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		items, next := fakePage(r, total, size, func(i int) map[string]any {
+			id := fmt.Sprintf("upload%05d", i)
+			return map[string]any{
+				"id":             "item" + id,
+				"snippet":        map[string]any{"title": "Upload " + id, "channelTitle": "Band"},
+				"contentDetails": map[string]any{"videoId": id},
+				"status":         map[string]any{"privacyStatus": "public"},
+			}
+		})
+		writeAPIJSON(w, map[string]any{"items": items, "nextPageToken": next})
+	})
+}
+
+func TestChannelLookup(t *testing.T) {
+	tests := []struct {
+		ref        string
+		wantQuery  string
+		wantErr    error
+		wantNoCall bool
+	}{
+		{ref: testChannelID, wantQuery: "id=" + testChannelID},
+		{ref: "@band", wantQuery: "forHandle=@band"},
+		{ref: "https://www.youtube.com/channel/" + testChannelID + "/videos", wantQuery: "id=" + testChannelID},
+		{ref: "https://www.youtube.com/@band", wantQuery: "forHandle=@band"},
+		{ref: "@nobody", wantQuery: "forHandle=@nobody", wantErr: yt.ErrNotFound},
+		{ref: "dQw4w9WgXcQ", wantNoCall: true},
+		{ref: "lofi hip hop", wantNoCall: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			api := newFakeAPI(t)
+			handleChannels(api)
+			channel, err := NewChannelService(api.client(t, fakeAPIToken)).Channel(context.Background(), tt.ref)
+
+			calls := api.queries("/channels")
+			if tt.wantNoCall {
+				if err == nil || len(calls) != 0 {
+					t.Errorf("Channel = %+v, %v after %d calls; want an error before any", channel, err, len(calls))
+				}
+				return
+			}
+			if len(calls) != 1 {
+				t.Fatalf("made %d calls, want 1", len(calls))
+			}
+			name, value, _ := strings.Cut(tt.wantQuery, "=")
+			if calls[0].Get(name) != value {
+				t.Errorf("query = %v, want %s", calls[0], tt.wantQuery)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Channel = %+v, %v; want %v", channel, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := yt.Channel{
+				ID:                testChannelID,
+				Title:             "Band",
+				Handle:            "@band",
+				SubscriberCount:   1200,
+				VideoCount:        40,
+				UploadsPlaylistID: testUploads,
+				URL:               yt.ChannelURL(testChannelID),
+			}
+			if *channel != want {
+				t.Errorf("Channel = %+v, want %+v", *channel, want)
+			}
+		})
+	}
+}
+
+func TestChannelUploads(t *testing.T) {
+	tests := []struct {
+		name         string
+		channel      yt.Channel
+		limit        int
+		wantPlaylist string
+		wantSizes    string
+		want         int
+	}{
+		{
+			name:         "uploads playlist",
+			channel:      yt.Channel{ID: testChannelID, UploadsPlaylistID: "UUfromTheAPI"},
+			limit:        3,
+			wantPlaylist: "UUfromTheAPI",
+			wantSizes:    "3",
+			want:         3,
+		},
+		{
+			name:         "derived from the channel ID",
+			channel:      yt.Channel{ID: testChannelID},
+			limit:        3,
+			wantPlaylist: testUploads,
+			wantSizes:    "3",
+			want:         3,
+		},
+		{
+			name:         "more than a page",
+			channel:      yt.Channel{ID: testChannelID},
+			limit:        70,
+			wantPlaylist: testUploads,
+			wantSizes:    "50,50",
+			want:         70,
+		},
+		{
+			name:         "fewer uploads than the limit",
+			channel:      yt.Channel{ID: testChannelID},
+			limit:        200,
+			wantPlaylist: testUploads,
+			wantSizes:    "50,50",
+			want:         80,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			handlePlaylistItems(api, 80)
+			handleVideos(api, fakeVideo)
+
+			videos, err := NewChannelService(api.client(t, fakeAPIToken)).Uploads(context.Background(), &tt.channel, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(videos) != tt.want {
+				t.Fatalf("got %d uploads, want %d", len(videos), tt.want)
+			}
+			for i, v := range videos {
+				if v.ID != fmt.Sprintf("upload%05d", i) || v.Duration != "PT3M" {
+					t.Errorf("upload %d = %+v", i, v)
+					break
+				}
+			}
+
+			var sizes []string
+			for _, q := range api.queries("/playlistItems") {
+				if q.Get("playlistId") != tt.wantPlaylist {
+					t.Errorf("listed playlist %q, want %q", q.Get("playlistId"), tt.wantPlaylist)
+				}
+				sizes = append(sizes, q.Get("maxResults"))
+			}
+			if got := strings.Join(sizes, ","); got != tt.wantSizes {
+				t.Errorf("page sizes = %q, want %q", got, tt.wantSizes)
+			}
+		})
+	}
+}
+
+// quotaChannelBackend returns the auto backend's channel service over an
+// API that is out of quota, with the fake yt-dlp as the fallback
+func quotaChannelBackend(t *testing.T) (ChannelService, *fakeDataAPI) {
+	api := newFakeAPI(t)
+	for _, endpoint := range []string{"channels", "playlistItems", "playlists", "search"} {
+		api.handle(endpoint, func(w http.ResponseWriter, r *http.Request) {
+			writeAPIError(w, http.StatusForbidden, "quotaExceeded")
+		})
+	}
+	return &fallbackChannelService{
+		primary:  NewChannelService(api.client(t, fakeAPIToken)),
+		fallback: NewYtDlpChannelService(""),
+	}, api
+}
+
+func TestChannelQuotaFallback(t *testing.T) {
+	channel := &yt.Channel{ID: testChannelID, Title: "Band"}
+
+	t.Run("lookup", func(t *testing.T) {
+		service, api := quotaChannelBackend(t)
+		args := fakeYtDlp(t, `{"id":"`+testChannelID+`","channel":"Band","channel_id":"`+testChannelID+`","uploader_id":"@band","title":"Band - Videos"}`, "", 0)
+
+		got, err := service.Channel(context.Background(), "@band")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != testChannelID || got.Title != "Band" || got.Handle != "@band" {
+			t.Errorf("Channel = %+v", got)
+		}
+		if !hasArgs(args(), "https://www.youtube.com/@band/videos") {
+			t.Errorf("yt-dlp args = %q, want the handle's videos tab", args())
+		}
+		if len(api.queries("/channels")) != 1 {
+			t.Error("the API was not tried first")
+		}
+	})
+
+	t.Run("uploads", func(t *testing.T) {
+		service, _ := quotaChannelBackend(t)
+		args := fakeYtDlp(t, `{"id":"aaaaaaaaaaa","title":"One"}`+"\n"+`{"id":"bbbbbbbbbbb","title":"Two"}`+"\n", "", 0)
+
+		videos, err := service.Uploads(context.Background(), channel, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(videos) != 2 || videos[0].ChannelID != testChannelID || videos[1].ChannelTitle != "Band" {
+			t.Errorf("Uploads = %+v", videos)
+		}
+		if !hasArgs(args(), "--playlist-end", "2") || !hasArgs(args(), yt.ChannelURL(testChannelID)+"/videos") {
+			t.Errorf("yt-dlp args = %q", args())
+		}
+	})
+
+	t.Run("playlists", func(t *testing.T) {
+		service, _ := quotaChannelBackend(t)
+		fakeYtDlp(t, `{"id":"PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG","title":"Hits","playlist_count":12}`+"\n", "", 0)
+
+		playlists, err := service.Playlists(context.Background(), channel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(playlists) != 1 || playlists[0].Title != "Hits" || playlists[0].ItemCount != 12 || playlists[0].ChannelID != testChannelID {
+			t.Errorf("Playlists = %+v", playlists)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		service, _ := quotaChannelBackend(t)
+		args := fakeYtDlp(t, `{"id":"aaaaaaaaaaa","title":"Live"}`+"\n", "", 0)
+
+		response, err := service.SearchChannel(context.Background(), channel, "live", &yt.SearchConfig{MaxResults: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Videos) != 1 || response.Videos[0].ID != "aaaaaaaaaaa" {
+			t.Errorf("SearchChannel = %+v", response.Videos)
+		}
+		if !hasArgs(args(), yt.ChannelURL(testChannelID)+"/search?query=live") {
+			t.Errorf("yt-dlp args = %q", args())
+		}
+	})
+
+	t.Run("later search pages", func(t *testing.T) {
+		service, _ := quotaChannelBackend(t)
+		fakeYtDlp(t, `{"id":"aaaaaaaaaaa"}`+"\n", "", 0)
+
+		// yt-dlp would start over and repeat the first page
+		_, err := service.SearchChannel(context.Background(), channel, "live", &yt.SearchConfig{MaxResults: 5, PageToken: "CAUQAA"})
+		if !errors.Is(err, yt.ErrQuotaExceeded) {
+			t.Errorf("SearchChannel = %v, want the quota error", err)
+		}
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		api := newFakeAPI(t)
+		service := &fallbackChannelService{
+			primary:  NewChannelService(api.client(t, "Bearer expired")),
+			fallback: NewYtDlpChannelService(""),
+		}
+		fakeYtDlp(t, "", "ERROR: should not run", 1)
+		if _, err := service.Channel(context.Background(), testChannelID); err == nil || isQuotaError(err) {
+			t.Errorf("Channel = %v, want the API error", err)
+		}
+	})
+}
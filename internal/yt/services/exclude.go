@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+
+	"github.com/alanpramil7/gplay/internal/filter"
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// excludingSearchService drops excluded videos from search results. A page
+// may come back shorter than requested, or even empty with a next page.
+type excludingSearchService struct {
+	SearchService
+	rules *filter.Rules
+}
+
+func (s *excludingSearchService) Search(query string) (*yt.SearchResponse, error) {
+	return s.SearchContext(context.Background(), query)
+}
+
+func (s *excludingSearchService) SearchWithConfig(query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(context.Background(), query, config)
+}
+
+func (s *excludingSearchService) SearchContext(ctx context.Context, query string) (*yt.SearchResponse, error) {
+	return s.exclude(s.SearchService.SearchContext(ctx, query))
+}
+
+func (s *excludingSearchService) SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return s.exclude(s.SearchService.SearchWithConfigContext(ctx, query, config))
+}
+
+func (s *excludingSearchService) exclude(response *yt.SearchResponse, err error) (*yt.SearchResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	response.Videos = s.rules.Apply(response.Videos)
+	return response, nil
+}
+
+// excludingPlaylistService drops excluded videos from playlists
+type excludingPlaylistService struct {
+	PlaylistService
+	rules *filter.Rules
+}
+
+func (s *excludingPlaylistService) GetPlaylistItems(playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	return s.GetPlaylistItemsContext(context.Background(), playlistID, maxResults)
+}
+
+func (s *excludingPlaylistService) GetPlaylistItemsContext(ctx context.Context, playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	results, err := s.PlaylistService.GetPlaylistItemsContext(ctx, playlistID, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	return s.rules.Apply(results), nil
+}
+
+// excludingChannelService drops excluded videos from channel uploads and
+// channel searches
+type excludingChannelService struct {
+	ChannelService
+	rules *filter.Rules
+}
+
+func (s *excludingChannelService) Uploads(ctx context.Context, channel *yt.Channel, limit int) ([]yt.Video, error) {
+	videos, err := s.ChannelService.Uploads(ctx, channel, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.rules.Apply(videos), nil
+}
+
+func (s *excludingChannelService) SearchChannel(ctx context.Context, channel *yt.Channel, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	response, err := s.ChannelService.SearchChannel(ctx, channel, query, config)
+	if err != nil {
+		return nil, err
+	}
+	response.Videos = s.rules.Apply(response.Videos)
+	return response, nil
+}
+
+// excludingMixService drops excluded videos from mixes
+type excludingMixService struct {
+	MixService
+	rules *filter.Rules
+}
+
+func (s *excludingMixService) Mix(ctx context.Context, videoID string, limit int) ([]yt.Video, error) {
+	videos, err := s.MixService.Mix(ctx, videoID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.rules.Apply(videos), nil
+}
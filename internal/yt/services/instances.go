@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const defaultInstanceTimeout = 15 * time.Second
+
+// StreamResolver turns a watch URL into a direct audio stream URL. Backends
+// that can do this let AudioService skip yt-dlp.
+type StreamResolver interface {
+	StreamURL(ctx context.Context, videoURL string) (string, error)
+}
+
+// instancePool sends requests to a list of interchangeable API instances,
+// moving on to the next one when an instance is down
+type instancePool struct {
+	mu        sync.Mutex
+	instances []string
+	current   int
+	client    *http.Client
+}
+
+func newInstancePool(instances []string, client *http.Client) (*instancePool, error) {
+	cleaned := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		if instance = strings.TrimRight(strings.TrimSpace(instance), "/"); instance != "" {
+			cleaned = append(cleaned, instance)
+		}
+	}
+	if len(cleaned) == 0 {
+		return nil, fmt.Errorf("no instances configured")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultInstanceTimeout}
+	}
+	return &instancePool{instances: cleaned, client: client}, nil
+}
+
+// getJSON decodes the response of GET path from the first instance that
+// answers. The instance that worked is tried first next time.
+func (p *instancePool) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	p.mu.Lock()
+	start := p.current
+	p.mu.Unlock()
+
+	var errs []error
+	for i := range p.instances {
+		index := (start + i) % len(p.instances)
+		instance := p.instances[index]
+
+		err := p.get(ctx, instance, path, query, out)
+		if ctx.Err() != nil {
+			// Other instances would be cancelled just the same
+			return ctx.Err()
+		}
+		if err == nil {
+			p.mu.Lock()
+			p.current = index
+			p.mu.Unlock()
+			return nil
+		}
+		// A 404 is not worth retrying on another instance
+		if errors.Is(err, yt.ErrNotFound) {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", instance, err))
+	}
+
+	return fmt.Errorf("all instances failed: %w", errors.Join(errs...))
+}
+
+func (p *instancePool) get(ctx context.Context, instance, path string, query url.Values, out any) error {
+	target := instance + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return yt.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+	"github.com/alanpramil7/gplay/internal/yt/services/invidioustest"
+)
+
+// instanceService is what the Invidious and Piped services have in common
+type instanceService interface {
+	SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error)
+	GetPlaylistItemsContext(ctx context.Context, playlistID string, maxResults int64) ([]yt.SearchResult, error)
+	StreamResolver
+	ChannelService
+}
+
+var instanceBackends = []struct {
+	name string
+	new  func(instances []string) (instanceService, error)
+}{
+	{BackendInvidious, func(instances []string) (instanceService, error) {
+		return NewInvidiousService(instances, nil, 50)
+	}},
+	{BackendPiped, func(instances []string) (instanceService, error) {
+		return NewPipedService(instances, nil, 50)
+	}},
+}
+
+func videoIDs(videos []yt.Video) []string {
+	ids := make([]string, 0, len(videos))
+	for _, v := range videos {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+func TestInstanceSearchPaging(t *testing.T) {
+	for _, backend := range instanceBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			srv := invidioustest.NewServer()
+			defer srv.Close()
+			s, err := backend.new([]string{srv.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			config := DefaultSearchConfig(50)
+			first, err := s.SearchWithConfigContext(ctx, "song", config)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			if got := strings.Join(videoIDs(first.Videos), ","); got != "vid00000001,vid00000002" {
+				t.Errorf("first page = %s", got)
+			}
+			if first.NextPageToken == "" {
+				t.Fatal("first page has no next page token")
+			}
+			v := first.Videos[0]
+			if v.Title != "First Song" || v.ChannelID != "UCtest" || v.Length.Seconds() != 253 || v.Duration != "PT4M13S" {
+				t.Errorf("first video = %+v", v)
+			}
+
+			next := *config
+			next.PageToken = first.NextPageToken
+			second, err := s.SearchWithConfigContext(ctx, "song", &next)
+			if err != nil {
+				t.Fatalf("second page: %v", err)
+			}
+			if got := strings.Join(videoIDs(second.Videos), ","); got != "vid00000003" {
+				t.Errorf("second page = %s", got)
+			}
+		})
+	}
+}
+
+func TestInstancePlaylistItems(t *testing.T) {
+	for _, backend := range instanceBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			srv := invidioustest.NewServer()
+			defer srv.Close()
+			s, err := backend.new([]string{srv.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items, err := s.GetPlaylistItemsContext(context.Background(), invidioustest.PlaylistID, 50)
+			if err != nil {
+				t.Fatalf("playlist: %v", err)
+			}
+			if got := strings.Join(videoIDs(items), ","); got != "vid00000001,vid00000002,vid00000003" {
+				t.Errorf("items = %s", got)
+			}
+
+			_, err = s.GetPlaylistItemsContext(context.Background(), "PLmissing", 50)
+			if !errors.Is(err, yt.ErrNotFound) {
+				t.Errorf("missing playlist error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestInstanceStreamURL(t *testing.T) {
+	for _, backend := range instanceBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			srv := invidioustest.NewServer()
+			defer srv.Close()
+			s, err := backend.new([]string{srv.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.StreamURL(context.Background(), yt.VideoURL("vid00000002"))
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			if want := srv.AudioURL("vid00000002"); got != want {
+				t.Errorf("stream = %s, want the highest bitrate %s", got, want)
+			}
+
+			if _, err := s.StreamURL(context.Background(), "not a video"); err == nil {
+				t.Error("expected an error for an invalid URL")
+			}
+		})
+	}
+}
+
+func TestInstanceChannel(t *testing.T) {
+	for _, backend := range instanceBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			srv := invidioustest.NewServer()
+			defer srv.Close()
+			s, err := backend.new([]string{srv.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+
+			channel, err := s.Channel(ctx, "@testchannel")
+			if err != nil {
+				t.Fatalf("channel: %v", err)
+			}
+			if channel.ID != "UCtest" || channel.Title != "Test Channel" || channel.Handle != "@testchannel" {
+				t.Errorf("channel = %+v", channel)
+			}
+
+			uploads, err := s.Uploads(ctx, channel, 0)
+			if err != nil {
+				t.Fatalf("uploads: %v", err)
+			}
+			if got := strings.Join(videoIDs(uploads), ","); got != "vid00000001,vid00000002" {
+				t.Errorf("uploads = %s", got)
+			}
+			limited, err := s.Uploads(ctx, channel, 1)
+			if err != nil {
+				t.Fatalf("uploads: %v", err)
+			}
+			if len(limited) != 1 {
+				t.Errorf("uploads with limit 1 = %d videos", len(limited))
+			}
+
+			playlists, err := s.Playlists(ctx, channel)
+			if err != nil {
+				t.Fatalf("playlists: %v", err)
+			}
+			if len(playlists) != 1 || playlists[0].ID != invidioustest.PlaylistID || playlists[0].ItemCount != 3 {
+				t.Errorf("playlists = %+v", playlists)
+			}
+
+			if _, err := s.Channel(ctx, "@nobody"); !errors.Is(err, yt.ErrNotFound) {
+				t.Errorf("unknown channel error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestInstanceFailover(t *testing.T) {
+	for _, backend := range instanceBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			down := invidioustest.NewServer()
+			defer down.Close()
+			up := invidioustest.NewServer()
+			defer up.Close()
+			down.SetDown(true)
+
+			s, err := backend.new([]string{down.URL, up.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+
+			if _, err := s.StreamURL(ctx, yt.VideoURL("vid00000001")); err != nil {
+				t.Fatalf("stream with the first instance down: %v", err)
+			}
+			if down.Requests() != 1 || up.Requests() != 1 {
+				t.Errorf("requests = %d down, %d up; want 1 and 1", down.Requests(), up.Requests())
+			}
+
+			// The instance that answered is tried first from now on
+			got, err := s.StreamURL(ctx, yt.VideoURL("vid00000001"))
+			if err != nil {
+				t.Fatalf("second stream: %v", err)
+			}
+			if want := up.AudioURL("vid00000001"); got != want {
+				t.Errorf("stream = %s, want %s", got, want)
+			}
+			if down.Requests() != 1 {
+				t.Errorf("down instance got %d requests, want it skipped", down.Requests())
+			}
+		})
+	}
+}
+
+func TestInstanceFailoverNotFound(t *testing.T) {
+	for _, backend := range instanceBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			first := invidioustest.NewServer()
+			defer first.Close()
+			second := invidioustest.NewServer()
+			defer second.Close()
+
+			s, err := backend.new([]string{first.URL, second.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = s.StreamURL(context.Background(), yt.VideoURL("vid0missing"))
+			if !errors.Is(err, yt.ErrNotFound) {
+				t.Errorf("error = %v, want ErrNotFound", err)
+			}
+			if second.Requests() != 0 {
+				t.Errorf("a 404 was retried on the next instance")
+			}
+		})
+	}
+}
+
+func TestInstanceAllDown(t *testing.T) {
+	for _, backend := range instanceBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			a := invidioustest.NewServer()
+			defer a.Close()
+			b := invidioustest.NewServer()
+			defer b.Close()
+			a.SetDown(true)
+			b.SetDown(true)
+
+			s, err := backend.new([]string{a.URL, b.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = s.SearchWithConfigContext(context.Background(), "song", DefaultSearchConfig(50))
+			if err == nil || !strings.Contains(err.Error(), "all instances failed") {
+				t.Fatalf("error = %v, want all instances failed", err)
+			}
+			if !strings.Contains(err.Error(), a.URL) || !strings.Contains(err.Error(), b.URL) {
+				t.Errorf("error does not name both instances: %v", err)
+			}
+		})
+	}
+}
+
+func TestInstanceCancelled(t *testing.T) {
+	srv := invidioustest.NewServer()
+	defer srv.Close()
+	backup := invidioustest.NewServer()
+	defer backup.Close()
+
+	s, err := NewInvidiousService([]string{srv.URL, backup.URL}, nil, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.StreamURL(ctx, yt.VideoURL("vid00000001")); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if backup.Requests() != 0 {
+		t.Error("a cancelled request moved on to the next instance")
+	}
+}
+
+func TestNewInstancePool(t *testing.T) {
+	if _, err := newInstancePool([]string{" ", ""}, nil); err == nil {
+		t.Error("expected an error without instances")
+	}
+
+	pool, err := newInstancePool([]string{" https://a.example/ ", "", "https://b.example"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pool.instances, ","); got != "https://a.example,https://b.example" {
+		t.Errorf("instances = %s", got)
+	}
+	if pool.client == nil || pool.client == http.DefaultClient {
+		t.Error("expected a default client with a timeout")
+	}
+}
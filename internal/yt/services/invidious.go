@@ -0,0 +1,479 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// invidiousSortOrders maps Data API orders onto Invidious sort_by values
+var invidiousSortOrders = map[string]string{
+	"relevance": "relevance",
+	"date":      "upload_date",
+	"viewCount": "view_count",
+	"rating":    "rating",
+}
+
+// invidiousDateRange picks the narrowest Invidious upload date range that
+// still covers everything published after since; the exact bound is applied
+// to the results afterwards
+func invidiousDateRange(since time.Time) string {
+	if since.IsZero() {
+		return ""
+	}
+	age := time.Since(since)
+	switch {
+	case age <= time.Hour:
+		return "hour"
+	case age <= 7*24*time.Hour:
+		return "week"
+	case age <= 31*24*time.Hour:
+		return "month"
+	case age <= 365*24*time.Hour:
+		return "year"
+	}
+	return ""
+}
+
+type invidiousThumbnail struct {
+	Quality string `json:"quality"`
+	URL     string `json:"url"`
+	Width   int    `json:"width"`
+}
+
+type invidiousVideo struct {
+	Type            string               `json:"type"`
+	VideoID         string               `json:"videoId"`
+	Title           string               `json:"title"`
+	Description     string               `json:"description"`
+	Author          string               `json:"author"`
+	AuthorID        string               `json:"authorId"`
+	Published       int64                `json:"published"`
+	LengthSeconds   int64                `json:"lengthSeconds"`
+	ViewCount       uint64               `json:"viewCount"`
+	LikeCount       uint64               `json:"likeCount"`
+	VideoThumbnails []invidiousThumbnail `json:"videoThumbnails"`
+	LiveNow         bool                 `json:"liveNow"`
+	IsUpcoming      bool                 `json:"isUpcoming"`
+	Genre           string               `json:"genre"` // only on /videos/:id
+}
+
+type invidiousPlaylist struct {
+	Title      string           `json:"title"`
+	VideoCount int              `json:"videoCount"`
+	Videos     []invidiousVideo `json:"videos"`
+}
+
+type invidiousChannel struct {
+	Author           string               `json:"author"`
+	AuthorID         string               `json:"authorId"`
+	AuthorURL        string               `json:"authorUrl"`
+	AuthorThumbnails []invidiousThumbnail `json:"authorThumbnails"`
+	SubCount         uint64               `json:"subCount"`
+	Description      string               `json:"description"`
+}
+
+type invidiousChannelVideos struct {
+	Videos       []invidiousVideo `json:"videos"`
+	Continuation string           `json:"continuation"`
+}
+
+type invidiousChannelPlaylist struct {
+	Title             string `json:"title"`
+	PlaylistID        string `json:"playlistId"`
+	Author            string `json:"author"`
+	AuthorID          string `json:"authorId"`
+	VideoCount        int64  `json:"videoCount"`
+	PlaylistThumbnail string `json:"playlistThumbnail"`
+}
+
+type invidiousChannelPlaylists struct {
+	Playlists    []invidiousChannelPlaylist `json:"playlists"`
+	Continuation string                     `json:"continuation"`
+}
+
+type invidiousFormat struct {
+	URL     string `json:"url"`
+	Type    string `json:"type"`
+	Bitrate string `json:"bitrate"`
+}
+
+type invidiousVideoDetails struct {
+	AdaptiveFormats []invidiousFormat `json:"adaptiveFormats"`
+}
+
+// InvidiousService implements SearchService, PlaylistService,
+// ChannelService and StreamResolver on top of the Invidious REST API
+type InvidiousService struct {
+	pool   *instancePool
+	config *yt.SearchConfig
+}
+
+// NewInvidiousService creates a service that talks to the given Invidious
+// instances, failing over in order. A nil client uses a default one.
+func NewInvidiousService(instances []string, client *http.Client, maxResults int64) (*InvidiousService, error) {
+	pool, err := newInstancePool(instances, client)
+	if err != nil {
+		return nil, fmt.Errorf("invidious: %w", err)
+	}
+	return &InvidiousService{pool: pool, config: DefaultSearchConfig(maxResults)}, nil
+}
+
+// Search performs an Invidious search with default configuration
+func (s *InvidiousService) Search(query string) (*yt.SearchResponse, error) {
+	return s.SearchContext(context.Background(), query)
+}
+
+// SearchWithConfig performs an Invidious search with custom configuration
+func (s *InvidiousService) SearchWithConfig(query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(context.Background(), query, config)
+}
+
+// SearchContext performs an Invidious search with default configuration
+func (s *InvidiousService) SearchContext(ctx context.Context, query string) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(ctx, query, s.config)
+}
+
+// SearchWithConfigContext performs an Invidious search. Page tokens are page
+// numbers; Invidious returns a fixed number of results per page, so
+// MaxResults only trims the page.
+func (s *InvidiousService) SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	if config.ChannelID != "" {
+		return s.SearchChannel(ctx, &yt.Channel{ID: config.ChannelID}, query, config)
+	}
+
+	page := 1
+	if config.PageToken != "" {
+		p, err := strconv.Atoi(config.PageToken)
+		if err != nil || p < 1 {
+			return nil, fmt.Errorf("invalid page token %q", config.PageToken)
+		}
+		page = p
+	}
+
+	params := url.Values{
+		"q":    {query},
+		"type": {"video"},
+		"page": {strconv.Itoa(page)},
+	}
+	if sortBy, ok := invidiousSortOrders[config.Order]; ok {
+		params.Set("sort_by", sortBy)
+	}
+	if config.VideoDuration == "short" || config.VideoDuration == "long" {
+		params.Set("duration", config.VideoDuration)
+	}
+	if date := invidiousDateRange(config.PublishedAfter); date != "" {
+		params.Set("date", date)
+	}
+	if config.RegionCode != "" {
+		params.Set("region", config.RegionCode)
+	}
+	applied := []string{"region"}
+	var features []string
+	if config.EventType == "live" {
+		features = append(features, "live")
+		applied = append(applied, "event")
+	}
+	if config.VideoCaption == "closedCaption" {
+		features = append(features, "subtitles")
+		applied = append(applied, "captions")
+	}
+	if config.VideoDefinition == "high" {
+		features = append(features, "hd")
+		applied = append(applied, "definition")
+	}
+	if len(features) > 0 {
+		params.Set("features", strings.Join(features, ","))
+	}
+	warnIgnoredFilters(BackendInvidious, config, applied...)
+
+	var items []invidiousVideo
+	if err := s.pool.getJSON(ctx, "/api/v1/search", params, &items); err != nil {
+		return nil, fmt.Errorf("error executing search: %w", err)
+	}
+
+	results := make([]yt.SearchResult, 0, len(items))
+	for _, item := range items {
+		if item.Type != "" && item.Type != "video" {
+			continue
+		}
+		video := item.toVideo()
+		if !config.PublishedWithin(video) {
+			continue
+		}
+		results = append(results, video)
+		if config.MaxResults > 0 && int64(len(results)) == config.MaxResults {
+			break
+		}
+	}
+
+	response := &yt.SearchResponse{
+		Videos:       results,
+		TotalResults: int64(len(results)),
+		Query:        query,
+	}
+	if len(items) > 0 {
+		response.NextPageToken = strconv.Itoa(page + 1)
+	}
+	return response, nil
+}
+
+// GetPlaylistItems retrieves all videos in a playlist, following pages
+func (s *InvidiousService) GetPlaylistItems(playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	return s.GetPlaylistItemsContext(context.Background(), playlistID, maxResults)
+}
+
+// GetPlaylistItemsContext is GetPlaylistItems with a context
+func (s *InvidiousService) GetPlaylistItemsContext(ctx context.Context, playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	results := []yt.SearchResult{}
+	seen := make(map[string]bool)
+
+	for page := 1; ; page++ {
+		var playlist invidiousPlaylist
+		path := "/api/v1/playlists/" + url.PathEscape(playlistID)
+		if err := s.pool.getJSON(ctx, path, url.Values{"page": {strconv.Itoa(page)}}, &playlist); err != nil {
+			return nil, fmt.Errorf("error fetching playlist items: %w", err)
+		}
+
+		added := 0
+		for _, video := range playlist.Videos {
+			// Pages overlap on some instances
+			if seen[video.VideoID] {
+				continue
+			}
+			seen[video.VideoID] = true
+			results = append(results, video.toVideo())
+			added++
+		}
+
+		if added == 0 || (playlist.VideoCount > 0 && len(results) >= playlist.VideoCount) {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// StreamURL resolves the best audio-only format of a video
+func (s *InvidiousService) StreamURL(ctx context.Context, videoURL string) (string, error) {
+	id, err := yt.ParseVideoID(videoURL)
+	if err != nil {
+		return "", err
+	}
+
+	var details invidiousVideoDetails
+	if err := s.pool.getJSON(ctx, "/api/v1/videos/"+url.PathEscape(id), nil, &details); err != nil {
+		return "", fmt.Errorf("error resolving stream: %w", err)
+	}
+
+	best, bestBitrate := "", -1
+	for _, format := range details.AdaptiveFormats {
+		if !strings.HasPrefix(format.Type, "audio/") {
+			continue
+		}
+		bitrate, _ := strconv.Atoi(format.Bitrate)
+		if bitrate > bestBitrate {
+			best, bestBitrate = format.URL, bitrate
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no audio stream found for %s", id)
+	}
+	return best, nil
+}
+
+// Channel looks up a channel by ID, @handle or channel URL. Handles are
+// resolved through the instance's resolveurl endpoint.
+func (s *InvidiousService) Channel(ctx context.Context, ref string) (*yt.Channel, error) {
+	id, handle, err := parseChannelRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		var resolved struct {
+			UCID string `json:"ucid"`
+		}
+		params := url.Values{"url": {yt.ChannelURL(handle)}}
+		if err := s.pool.getJSON(ctx, "/api/v1/resolveurl", params, &resolved); err != nil {
+			return nil, fmt.Errorf("error resolving %s: %w", handle, err)
+		}
+		id = resolved.UCID
+	}
+
+	var channel invidiousChannel
+	if err := s.pool.getJSON(ctx, "/api/v1/channels/"+url.PathEscape(id), nil, &channel); err != nil {
+		return nil, fmt.Errorf("error fetching channel: %w", err)
+	}
+
+	thumbnail, width := "", -1
+	for _, t := range channel.AuthorThumbnails {
+		if t.Width > width {
+			thumbnail, width = t.URL, t.Width
+		}
+	}
+	return &yt.Channel{
+		ID:              channel.AuthorID,
+		Title:           channel.Author,
+		Handle:          handle,
+		Description:     channel.Description,
+		SubscriberCount: channel.SubCount,
+		ThumbnailURL:    thumbnail,
+		URL:             yt.ChannelURL(channel.AuthorID),
+	}, nil
+}
+
+// Uploads lists the newest uploads, following continuations
+func (s *InvidiousService) Uploads(ctx context.Context, channel *yt.Channel, limit int) ([]yt.Video, error) {
+	results := []yt.Video{}
+	continuation := ""
+
+	for limit <= 0 || len(results) < limit {
+		params := url.Values{}
+		if continuation != "" {
+			params.Set("continuation", continuation)
+		}
+		var page invidiousChannelVideos
+		if err := s.pool.getJSON(ctx, "/api/v1/channels/"+url.PathEscape(channel.ID)+"/videos", params, &page); err != nil {
+			return nil, fmt.Errorf("error fetching uploads: %w", err)
+		}
+		for _, video := range page.Videos {
+			results = append(results, video.toVideo())
+		}
+		if page.Continuation == "" || len(page.Videos) == 0 {
+			break
+		}
+		continuation = page.Continuation
+	}
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Playlists lists the public playlists of a channel
+func (s *InvidiousService) Playlists(ctx context.Context, channel *yt.Channel) ([]yt.Playlist, error) {
+	playlists := []yt.Playlist{}
+	continuation := ""
+
+	for {
+		params := url.Values{}
+		if continuation != "" {
+			params.Set("continuation", continuation)
+		}
+		var page invidiousChannelPlaylists
+		if err := s.pool.getJSON(ctx, "/api/v1/channels/"+url.PathEscape(channel.ID)+"/playlists", params, &page); err != nil {
+			return nil, fmt.Errorf("error fetching playlists: %w", err)
+		}
+		for _, p := range page.Playlists {
+			playlists = append(playlists, yt.Playlist{
+				ID:           p.PlaylistID,
+				Title:        p.Title,
+				ChannelTitle: p.Author,
+				ChannelID:    p.AuthorID,
+				ItemCount:    p.VideoCount,
+				ThumbnailURL: p.PlaylistThumbnail,
+				URL:          yt.PlaylistURL(p.PlaylistID),
+			})
+		}
+		if page.Continuation == "" || len(page.Playlists) == 0 {
+			break
+		}
+		continuation = page.Continuation
+	}
+
+	return playlists, nil
+}
+
+// SearchChannel searches the videos of a channel. Page tokens are page
+// numbers as in SearchWithConfigContext.
+func (s *InvidiousService) SearchChannel(ctx context.Context, channel *yt.Channel, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	page := 1
+	if config.PageToken != "" {
+		p, err := strconv.Atoi(config.PageToken)
+		if err != nil || p < 1 {
+			return nil, fmt.Errorf("invalid page token %q", config.PageToken)
+		}
+		page = p
+	}
+
+	warnIgnoredFilters(BackendInvidious, config)
+
+	var items []invidiousVideo
+	params := url.Values{"q": {query}, "page": {strconv.Itoa(page)}}
+	if err := s.pool.getJSON(ctx, "/api/v1/channels/"+url.PathEscape(channel.ID)+"/search", params, &items); err != nil {
+		return nil, fmt.Errorf("error executing search: %w", err)
+	}
+
+	results := make([]yt.Video, 0, len(items))
+	for _, item := range items {
+		if item.Type != "" && item.Type != "video" {
+			continue
+		}
+		video := item.toVideo()
+		if !config.PublishedWithin(video) {
+			continue
+		}
+		results = append(results, video)
+		if config.MaxResults > 0 && int64(len(results)) == config.MaxResults {
+			break
+		}
+	}
+
+	response := &yt.SearchResponse{
+		Videos:       results,
+		TotalResults: int64(len(results)),
+		Query:        query,
+	}
+	if len(items) > 0 {
+		response.NextPageToken = strconv.Itoa(page + 1)
+	}
+	return response, nil
+}
+
+func (v invidiousVideo) toVideo() yt.Video {
+	var publishedAt time.Time
+	if v.Published > 0 {
+		publishedAt = time.Unix(v.Published, 0).UTC()
+	}
+
+	thumbnail, width := "", -1
+	for _, t := range v.VideoThumbnails {
+		if t.Width > width {
+			thumbnail, width = t.URL, t.Width
+		}
+	}
+
+	length := secondsDuration(float64(v.LengthSeconds))
+
+	var live string
+	if v.LiveNow {
+		live = "live"
+	} else if v.IsUpcoming {
+		live = "upcoming"
+	}
+
+	return yt.Video{
+		ID:            v.VideoID,
+		Title:         v.Title,
+		Description:   v.Description,
+		ChannelTitle:  v.Author,
+		ChannelID:     v.AuthorID,
+		PublishedAt:   publishedAt,
+		Duration:      yt.FormatISODuration(length),
+		Length:        length,
+		ViewCount:     v.ViewCount,
+		LikeCount:     v.LikeCount,
+		ThumbnailURL:  thumbnail,
+		URL:           fmt.Sprintf("https://www.youtube.com/watch?v=%s", v.VideoID),
+		LiveBroadcast: live,
+		CategoryID:    yt.CategoryID(v.Genre),
+		Availability:  yt.TitleAvailability(v.Title),
+	}
+}
@@ -0,0 +1,423 @@
+// Package invidioustest provides an in-process fake of the Invidious and
+// Piped REST APIs for tests of the instance backed services.
+package invidioustest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// PlaylistID is the ID of the playlist served by the fake
+const PlaylistID = "PLinvidioustest"
+
+// pageSize is the number of items per search or playlist page
+const pageSize = 2
+
+// Video is a canned video served by the fake
+type Video struct {
+	ID        string
+	Title     string
+	Author    string
+	AuthorID  string
+	Seconds   int64
+	Views     int64
+	Published int64 // unix seconds
+}
+
+// DefaultVideos are served when NewServer is given none
+var DefaultVideos = []Video{
+	{ID: "vid00000001", Title: "First Song", Author: "Test Channel", AuthorID: "UCtest", Seconds: 253, Views: 1000, Published: 1700000000},
+	{ID: "vid00000002", Title: "Second Song", Author: "Test Channel", AuthorID: "UCtest", Seconds: 185, Views: 2000, Published: 1700000100},
+	{ID: "vid00000003", Title: "Third Song", Author: "Other Channel", AuthorID: "UCother", Seconds: 3600, Views: 3000, Published: 1700000200},
+}
+
+// Server is a fake Invidious and Piped instance. Invidious endpoints live
+// under /api/v1, Piped endpoints at the root, mirroring the real services.
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	videos []Video
+	down   bool
+
+	requests atomic.Int64
+}
+
+// NewServer starts a fake instance serving videos, or DefaultVideos when
+// none are given. Callers must Close it.
+func NewServer(videos ...Video) *Server {
+	if len(videos) == 0 {
+		videos = DefaultVideos
+	}
+	s := &Server{videos: videos}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/search", s.invidiousSearch)
+	mux.HandleFunc("/api/v1/playlists/", s.invidiousPlaylist)
+	mux.HandleFunc("/api/v1/videos/", s.invidiousVideo)
+	mux.HandleFunc("/api/v1/resolveurl", s.invidiousResolve)
+	mux.HandleFunc("/api/v1/channels/", s.invidiousChannel)
+	mux.HandleFunc("/search", s.pipedSearch)
+	mux.HandleFunc("/nextpage/search", s.pipedSearch)
+	mux.HandleFunc("/playlists/", s.pipedPlaylist)
+	mux.HandleFunc("/nextpage/playlists/", s.pipedPlaylist)
+	mux.HandleFunc("/streams/", s.pipedStreams)
+	mux.HandleFunc("/channel/", s.pipedChannel)
+	mux.HandleFunc("/@/", s.pipedChannel)
+	mux.HandleFunc("/nextpage/channel/", s.pipedChannel)
+	mux.HandleFunc("/channels/tabs", s.pipedChannelTab)
+	mux.HandleFunc("/audio/", s.audio)
+
+	s.Server = httptest.NewServer(s.track(mux))
+	return s
+}
+
+// SetDown makes every request fail with 503 to exercise failover
+func (s *Server) SetDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+// Requests returns how many requests the server has received
+func (s *Server) Requests() int64 {
+	return s.requests.Load()
+}
+
+// AudioURL is the stream URL the fake resolves for a video
+func (s *Server) AudioURL(id string) string {
+	return s.URL + "/audio/" + id
+}
+
+func (s *Server) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		s.mu.Lock()
+		down := s.down
+		s.mu.Unlock()
+		if down {
+			http.Error(w, "instance down", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matching returns the videos whose title contains the query
+func (s *Server) matching(query string) []Video {
+	var out []Video
+	for _, v := range s.videos {
+		if strings.Contains(strings.ToLower(v.Title), strings.ToLower(query)) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (s *Server) find(id string) (Video, bool) {
+	for _, v := range s.videos {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return Video{}, false
+}
+
+// channel returns the videos of a channel matched by ID or handle
+func (s *Server) channel(ref string) []Video {
+	var out []Video
+	for _, v := range s.videos {
+		if v.AuthorID == ref || Handle(v) == ref {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Handle is the @handle the fake gives the channel of v
+func Handle(v Video) string {
+	return "@" + strings.ToLower(strings.ReplaceAll(v.Author, " ", ""))
+}
+
+// page returns the slice of videos for a 1 based page number
+func page(videos []Video, number int) []Video {
+	start := (number - 1) * pageSize
+	if number < 1 || start >= len(videos) {
+		return nil
+	}
+	return videos[start:min(start+pageSize, len(videos))]
+}
+
+func pageNumber(r *http.Request, name string) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+func (s *Server) invidiousSearch(w http.ResponseWriter, r *http.Request) {
+	videos := page(s.matching(r.URL.Query().Get("q")), pageNumber(r, "page"))
+	items := make([]map[string]any, 0, len(videos))
+	for _, v := range videos {
+		items = append(items, invidiousVideo(v))
+	}
+	writeJSON(w, items)
+}
+
+func (s *Server) invidiousPlaylist(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimPrefix(r.URL.Path, "/api/v1/playlists/") != PlaylistID {
+		http.NotFound(w, r)
+		return
+	}
+	videos := page(s.videos, pageNumber(r, "page"))
+	items := make([]map[string]any, 0, len(videos))
+	for _, v := range videos {
+		items = append(items, invidiousVideo(v))
+	}
+	writeJSON(w, map[string]any{
+		"title":      "Test Playlist",
+		"videoCount": len(s.videos),
+		"videos":     items,
+	})
+}
+
+func (s *Server) invidiousVideo(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.find(strings.TrimPrefix(r.URL.Path, "/api/v1/videos/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	body := invidiousVideo(v)
+	body["adaptiveFormats"] = []map[string]any{
+		{"url": s.URL + "/video/" + v.ID, "type": `video/mp4; codecs="avc1"`, "bitrate": "900000"},
+		{"url": s.URL + "/low/" + v.ID, "type": `audio/webm; codecs="opus"`, "bitrate": "64000"},
+		{"url": s.AudioURL(v.ID), "type": `audio/webm; codecs="opus"`, "bitrate": "160000"},
+	}
+	writeJSON(w, body)
+}
+
+func (s *Server) invidiousResolve(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("url")
+	handle := target[strings.LastIndex(target, "/")+1:]
+	videos := s.channel(handle)
+	if len(videos) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]any{"ucid": videos[0].AuthorID, "pageType": "WEB_PAGE_TYPE_CHANNEL"})
+}
+
+func (s *Server) invidiousChannel(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/channels/"), "/")
+	videos := s.channel(parts[0])
+	if len(videos) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	tab := ""
+	if len(parts) > 1 {
+		tab = parts[1]
+	}
+	switch tab {
+	case "":
+		writeJSON(w, map[string]any{
+			"author":      videos[0].Author,
+			"authorId":    videos[0].AuthorID,
+			"description": "Channel of " + videos[0].Author,
+			"subCount":    int64(len(videos)) * 100,
+			"authorThumbnails": []map[string]any{
+				{"url": "https://yt3.ggpht.com/" + videos[0].AuthorID, "width": 176},
+			},
+		})
+	case "videos":
+		number := 1
+		if token := r.URL.Query().Get("continuation"); token != "" {
+			number, _ = strconv.Atoi(token)
+		}
+		items := []map[string]any{}
+		for _, v := range page(videos, number) {
+			items = append(items, invidiousVideo(v))
+		}
+		writeJSON(w, map[string]any{"videos": items, "continuation": nextPage(videos, number)})
+	case "playlists":
+		writeJSON(w, map[string]any{"playlists": []map[string]any{{
+			"title":      "Test Playlist",
+			"playlistId": PlaylistID,
+			"author":     videos[0].Author,
+			"authorId":   videos[0].AuthorID,
+			"videoCount": len(s.videos),
+		}}})
+	case "search":
+		var matches []Video
+		for _, v := range videos {
+			if strings.Contains(strings.ToLower(v.Title), strings.ToLower(r.URL.Query().Get("q"))) {
+				matches = append(matches, v)
+			}
+		}
+		items := []map[string]any{}
+		for _, v := range page(matches, pageNumber(r, "page")) {
+			items = append(items, invidiousVideo(v))
+		}
+		writeJSON(w, items)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) pipedChannel(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/nextpage")
+	ref := path[strings.LastIndex(path, "/")+1:]
+	if strings.HasPrefix(path, "/@/") {
+		ref = "@" + ref
+	}
+	videos := s.channel(ref)
+	if len(videos) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	number := 1
+	if token := r.URL.Query().Get("nextpage"); token != "" {
+		number, _ = strconv.Atoi(token)
+	}
+	items := []map[string]any{}
+	for _, v := range page(videos, number) {
+		items = append(items, pipedItem(v))
+	}
+	writeJSON(w, map[string]any{
+		"id":              videos[0].AuthorID,
+		"name":            videos[0].Author,
+		"description":     "Channel of " + videos[0].Author,
+		"subscriberCount": len(videos) * 100,
+		"relatedStreams":  items,
+		"nextpage":        nextPage(videos, number),
+		"tabs":            []map[string]any{{"name": "playlists", "data": videos[0].AuthorID}},
+	})
+}
+
+func (s *Server) pipedChannelTab(w http.ResponseWriter, r *http.Request) {
+	videos := s.channel(r.URL.Query().Get("data"))
+	if len(videos) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]any{"content": []map[string]any{{
+		"url":          "/playlist?list=" + PlaylistID,
+		"type":         "playlist",
+		"name":         "Test Playlist",
+		"uploaderName": videos[0].Author,
+		"videos":       len(s.videos),
+	}}})
+}
+
+func (s *Server) pipedSearch(w http.ResponseWriter, r *http.Request) {
+	number := 1
+	if token := r.URL.Query().Get("nextpage"); token != "" {
+		number, _ = strconv.Atoi(token)
+	}
+	matches := s.matching(r.URL.Query().Get("q"))
+	videos := page(matches, number)
+
+	items := make([]map[string]any, 0, len(videos))
+	for _, v := range videos {
+		items = append(items, pipedItem(v))
+	}
+	writeJSON(w, map[string]any{"items": items, "nextpage": nextPage(matches, number)})
+}
+
+func (s *Server) pipedPlaylist(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/nextpage"), "/playlists/")
+	if id != PlaylistID {
+		http.NotFound(w, r)
+		return
+	}
+	number := 1
+	if token := r.URL.Query().Get("nextpage"); token != "" {
+		number, _ = strconv.Atoi(token)
+	}
+	videos := page(s.videos, number)
+
+	items := make([]map[string]any, 0, len(videos))
+	for _, v := range videos {
+		items = append(items, pipedItem(v))
+	}
+	writeJSON(w, map[string]any{
+		"name":           "Test Playlist",
+		"relatedStreams": items,
+		"nextpage":       nextPage(s.videos, number),
+	})
+}
+
+func (s *Server) pipedStreams(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.find(strings.TrimPrefix(r.URL.Path, "/streams/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"title":    v.Title,
+		"uploader": v.Author,
+		"duration": v.Seconds,
+		"audioStreams": []map[string]any{
+			{"url": s.URL + "/low/" + v.ID, "bitrate": 64000},
+			{"url": s.AudioURL(v.ID), "bitrate": 160000},
+		},
+	})
+}
+
+// audio serves a few bytes standing in for an audio stream
+func (s *Server) audio(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "audio/webm")
+	fmt.Fprint(w, "fake audio for "+strings.TrimPrefix(r.URL.Path, "/audio/"))
+}
+
+func nextPage(videos []Video, number int) string {
+	if number*pageSize >= len(videos) {
+		return ""
+	}
+	return strconv.Itoa(number + 1)
+}
+
+func invidiousVideo(v Video) map[string]any {
+	return map[string]any{
+		"type":          "video",
+		"videoId":       v.ID,
+		"title":         v.Title,
+		"author":        v.Author,
+		"authorId":      v.AuthorID,
+		"published":     v.Published,
+		"lengthSeconds": v.Seconds,
+		"viewCount":     v.Views,
+		"videoThumbnails": []map[string]any{
+			{"quality": "default", "url": "https://i.ytimg.com/vi/" + v.ID + "/default.jpg", "width": 120},
+			{"quality": "high", "url": "https://i.ytimg.com/vi/" + v.ID + "/hqdefault.jpg", "width": 480},
+		},
+	}
+}
+
+func pipedItem(v Video) map[string]any {
+	return map[string]any{
+		"url":          "/watch?v=" + v.ID,
+		"type":         "stream",
+		"title":        v.Title,
+		"thumbnail":    "https://i.ytimg.com/vi/" + v.ID + "/hqdefault.jpg",
+		"uploaderName": v.Author,
+		"uploaderUrl":  "/channel/" + v.AuthorID,
+		"uploaded":     v.Published * 1000,
+		"duration":     v.Seconds,
+		"views":        v.Views,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+	"google.golang.org/api/youtube/v3"
+)
+
+// ErrNoLibrary is returned for library views when no account is logged in
+var ErrNoLibrary = errors.New("log in with 'gplay auth login' to use your own playlists and likes")
+
+// LibraryService reads the library of the logged in user. Only
+// the API backend with an OAuth login provides one.
+type LibraryService interface {
+	// MyChannel returns the channel of the logged in user
+	MyChannel(ctx context.Context) (*yt.Channel, error)
+	// MyPlaylists lists the user's playlists, private ones included
+	MyPlaylists(ctx context.Context) ([]yt.Playlist, error)
+	// Liked lists the most recently liked videos, at most limit of them
+	Liked(ctx context.Context, limit int) ([]yt.Video, error)
+}
+
+type libraryService struct {
+	client *yt.Client
+}
+
+// NewLibraryService creates a library service. The client must be
+// authorized as the user, see yt.NewAuthorizedClient.
+func NewLibraryService(client *yt.Client) LibraryService {
+	return &libraryService{client: client}
+}
+
+// MyChannel returns the channel of the logged in user
+func (l *libraryService) MyChannel(ctx context.Context) (*yt.Channel, error) {
+	call := l.client.Service().Channels.List([]string{"snippet", "contentDetails", "statistics"}).
+		Mine(true).
+		Context(ctx)
+
+	// Answers depend on who is logged in, so none of these are cached
+	response, err := yt.Do(ctx, l.client, yt.CallChannelsList, call.Do)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching your channel: %w", err)
+	}
+	if len(response.Items) == 0 {
+		return nil, fmt.Errorf("your account has no YouTube channel: %w", yt.ErrNotFound)
+	}
+	return apiChannel(response.Items[0]), nil
+}
+
+// MyPlaylists lists the user's playlists, private ones included
+func (l *libraryService) MyPlaylists(ctx context.Context) ([]yt.Playlist, error) {
+	playlists := []yt.Playlist{}
+	nextPageToken := ""
+
+	for {
+		call := l.client.Service().Playlists.List([]string{"snippet", "contentDetails"}).
+			Mine(true).
+			MaxResults(MaxPlaylistPageSize).
+			PageToken(nextPageToken).
+			Context(ctx)
+
+		response, err := yt.Do(ctx, l.client, yt.CallPlaylistsList, call.Do)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching your playlists: %w", err)
+		}
+		for _, item := range response.Items {
+			playlists = append(playlists, apiPlaylist(item))
+		}
+
+		if response.NextPageToken == "" {
+			break
+		}
+		nextPageToken = response.NextPageToken
+	}
+	return playlists, nil
+}
+
+// Liked lists the most recently liked videos, at most limit of them
+func (l *libraryService) Liked(ctx context.Context, limit int) ([]yt.Video, error) {
+	videos := []yt.Video{}
+	nextPageToken := ""
+
+	for len(videos) < limit {
+		call := l.client.Service().Videos.List([]string{"snippet", "contentDetails", "statistics", "status"}).
+			MyRating("like").
+			MaxResults(min(int64(limit-len(videos)), maxVideoIDsPerCall)).
+			PageToken(nextPageToken).
+			Context(ctx)
+
+		response, err := yt.Do(ctx, l.client, yt.CallVideosList, call.Do)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching liked videos: %w", err)
+		}
+		for _, item := range response.Items {
+			videos = append(videos, apiVideo(item, l.client.Region()))
+		}
+
+		if response.NextPageToken == "" {
+			break
+		}
+		nextPageToken = response.NextPageToken
+	}
+	return videos, nil
+}
+
+// apiVideo maps a Videos.List item that carries its snippet, content
+// details, statistics and status
+func apiVideo(item *youtube.Video, region string) yt.Video {
+	video := yt.Video{ID: item.Id, URL: yt.VideoURL(item.Id), Availability: videoAvailability(item, region)}
+	if item.Snippet != nil {
+		video.Title = item.Snippet.Title
+		video.Description = item.Snippet.Description
+		video.ChannelTitle = item.Snippet.ChannelTitle
+		video.ChannelID = item.Snippet.ChannelId
+		video.PublishedAt, _ = time.Parse(time.RFC3339, item.Snippet.PublishedAt)
+		video.ThumbnailURL = getBestThumbnail(item.Snippet.Thumbnails)
+		video.LiveBroadcast = liveBroadcast(item.Snippet.LiveBroadcastContent)
+		video.CategoryID = item.Snippet.CategoryId
+	}
+	if item.ContentDetails != nil {
+		video.Duration = item.ContentDetails.Duration
+		video.Length, _ = yt.ParseISODuration(item.ContentDetails.Duration)
+	}
+	if item.Statistics != nil {
+		video.ViewCount = item.Statistics.ViewCount
+		video.LikeCount = item.Statistics.LikeCount
+	}
+	return video
+}
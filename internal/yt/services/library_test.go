@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// fakeDataAPI serves Data API calls for one logged in user, each endpoint
+// from its own handler, and records the requests it gets
+type fakeDataAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	requests []fakeRequest
+}
+
+// fakeRequest is a request the fake served
+type fakeRequest struct {
+	method string
+	url    *url.URL
+	body   string
+}
+
+const fakeAPIToken = "Bearer user-token"
+
+// newFakeAPI returns a fake without endpoints; handle adds them
+func newFakeAPI(t *testing.T) *fakeDataAPI {
+	f := &fakeDataAPI{handlers: map[string]http.HandlerFunc{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != fakeAPIToken {
+			writeAPIError(w, http.StatusUnauthorized, "authError")
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		f.mu.Lock()
+		f.requests = append(f.requests, fakeRequest{method: r.Method, url: r.URL, body: string(body)})
+		handler := f.handlers[strings.TrimPrefix(r.URL.Path, "/youtube/v3/")]
+		f.mu.Unlock()
+		if handler == nil {
+			writeAPIError(w, http.StatusNotFound, "notFound")
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// handle serves endpoint, e.g. "videos", with handler
+func (f *fakeDataAPI) handle(endpoint string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[endpoint] = handler
+}
+
+// newFakeDataAPI serves the calls of the library service. Lists come in
+// pages of two.
+func newFakeDataAPI(t *testing.T, playlists, liked int) *fakeDataAPI {
+	f := newFakeAPI(t)
+	f.handle("channels", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIJSON(w, map[string]any{"items": []map[string]any{{
+			"id":      "UCme",
+			"snippet": map[string]any{"title": "Me", "customUrl": "@me"},
+		}}})
+	})
+	f.handle("playlists", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("mine") != "true" {
+			http.Error(w, "mine not set", http.StatusBadRequest)
+			return
+		}
+		items, next := fakePage(r, playlists, 2, func(i int) map[string]any {
+			return map[string]any{
+				"id":             fmt.Sprintf("PLmine%d", i),
+				"snippet":        map[string]any{"title": fmt.Sprintf("Playlist %d", i)},
+				"contentDetails": map[string]any{"itemCount": i},
+			}
+		})
+		writeAPIJSON(w, map[string]any{"items": items, "nextPageToken": next})
+	})
+	f.handle("videos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("myRating") != "like" {
+			http.Error(w, "myRating not set", http.StatusBadRequest)
+			return
+		}
+		size, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		items, next := fakePage(r, liked, min(size, 2), func(i int) map[string]any {
+			return map[string]any{
+				"id":             fmt.Sprintf("liked%05d", i),
+				"snippet":        map[string]any{"title": fmt.Sprintf("Liked %d", i)},
+				"contentDetails": map[string]any{"duration": "PT3M"},
+				"status":         map[string]any{"privacyStatus": "public", "embeddable": true},
+			}
+		})
+		writeAPIJSON(w, map[string]any{"items": items, "nextPageToken": next})
+	})
+	return f
+}
+
+// fakePage returns page pageToken of total items and the token of the next
+// page. Tokens are item offsets.
+func fakePage(r *http.Request, total, size int, item func(int) map[string]any) ([]map[string]any, string) {
+	start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+	items := []map[string]any{}
+	for i := start; i < min(start+size, total); i++ {
+		items = append(items, item(i))
+	}
+	next := ""
+	if start+size < total {
+		next = strconv.Itoa(start + size)
+	}
+	return items, next
+}
+
+func writeAPIJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError answers like the Data API refusing a call for reason
+func writeAPIError(w http.ResponseWriter, code int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{
+		"code":    code,
+		"message": reason,
+		"errors":  []map[string]any{{"reason": reason}},
+	}})
+}
+
+// client returns a Data API client sending authorization with every
+// request, which goes to the fake
+func (f *fakeDataAPI) client(t *testing.T, authorization string) *yt.Client {
+	t.Helper()
+	target, _ := url.Parse(f.URL)
+	httpClient := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r = r.Clone(r.Context())
+		r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+		r.Header.Set("Authorization", authorization)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	client, err := yt.NewAuthorizedClient(httpClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetRetryPolicy(yt.RetryPolicy{})
+	return client
+}
+
+// served returns the requests made to paths ending in path
+func (f *fakeDataAPI) served(path string) []fakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []fakeRequest
+	for _, r := range f.requests {
+		if strings.HasSuffix(r.url.Path, path) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (f *fakeDataAPI) queries(path string) []url.Values {
+	var out []url.Values
+	for _, r := range f.served(path) {
+		out = append(out, r.url.Query())
+	}
+	return out
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestLibraryMyChannel(t *testing.T) {
+	api := newFakeDataAPI(t, 0, 0)
+	channel, err := NewLibraryService(api.client(t, fakeAPIToken)).MyChannel(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channel.ID != "UCme" || channel.Title != "Me" || channel.Handle != "@me" {
+		t.Errorf("channel = %+v", channel)
+	}
+}
+
+func TestLibraryMyPlaylistsPaging(t *testing.T) {
+	api := newFakeDataAPI(t, 5, 0)
+	playlists, err := NewLibraryService(api.client(t, fakeAPIToken)).MyPlaylists(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 5 {
+		t.Fatalf("got %d playlists, want 5", len(playlists))
+	}
+	for i, p := range playlists {
+		if p.ID != fmt.Sprintf("PLmine%d", i) || p.ItemCount != int64(i) {
+			t.Errorf("playlist %d = %+v", i, p)
+		}
+	}
+
+	var tokens []string
+	for _, q := range api.queries("/playlists") {
+		tokens = append(tokens, q.Get("pageToken"))
+	}
+	if got := strings.Join(tokens, ","); got != ",2,4" {
+		t.Errorf("page tokens = %q, want every page once", got)
+	}
+}
+
+func TestLibraryLikedPaging(t *testing.T) {
+	tests := []struct {
+		name       string
+		liked      int
+		limit      int
+		want       int
+		maxResults string
+	}{
+		{name: "limit inside the list", liked: 5, limit: 3, want: 3, maxResults: "3,1"},
+		{name: "list shorter than the limit", liked: 3, limit: 10, want: 3, maxResults: "10,8"},
+		{name: "nothing liked", liked: 0, limit: 10, want: 0, maxResults: "10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeDataAPI(t, 0, tt.liked)
+			videos, err := NewLibraryService(api.client(t, fakeAPIToken)).Liked(context.Background(), tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(videos) != tt.want {
+				t.Fatalf("got %d videos, want %d", len(videos), tt.want)
+			}
+			for i, v := range videos {
+				if v.ID != fmt.Sprintf("liked%05d", i) || v.Length.Minutes() != 3 {
+					t.Errorf("video %d = %+v", i, v)
+				}
+			}
+
+			var sizes []string
+			for _, q := range api.queries("/videos") {
+				sizes = append(sizes, q.Get("maxResults"))
+			}
+			if got := strings.Join(sizes, ","); got != tt.maxResults {
+				t.Errorf("maxResults per call = %q, want %q", got, tt.maxResults)
+			}
+		})
+	}
+}
+
+func TestLibraryUnauthorized(t *testing.T) {
+	api := newFakeDataAPI(t, 1, 1)
+	_, err := NewLibraryService(api.client(t, "Bearer expired")).MyPlaylists(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "error fetching your playlists") {
+		t.Errorf("error = %v, want the refusal reported", err)
+	}
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+type pipedItem struct {
+	URL              string `json:"url"` // /watch?v=ID
+	Type             string `json:"type"`
+	Title            string `json:"title"`
+	Thumbnail        string `json:"thumbnail"`
+	UploaderName     string `json:"uploaderName"`
+	UploaderURL      string `json:"uploaderUrl"` // /channel/UC...
+	Uploaded         int64  `json:"uploaded"`    // milliseconds
+	ShortDescription string `json:"shortDescription"`
+	Duration         int64  `json:"duration"` // seconds, -1 for live
+	Views            int64  `json:"views"`
+	IsShort          bool   `json:"isShort"`
+}
+
+type pipedPage struct {
+	Items          []pipedItem `json:"items"`
+	RelatedStreams []pipedItem `json:"relatedStreams"`
+	NextPage       string      `json:"nextpage"`
+}
+
+type pipedChannel struct {
+	ID              string      `json:"id"`
+	Name            string      `json:"name"`
+	AvatarURL       string      `json:"avatarUrl"`
+	Description     string      `json:"description"`
+	SubscriberCount int64       `json:"subscriberCount"`
+	RelatedStreams  []pipedItem `json:"relatedStreams"`
+	NextPage        string      `json:"nextpage"`
+	Tabs            []struct {
+		Name string `json:"name"`
+		Data string `json:"data"`
+	} `json:"tabs"`
+}
+
+type pipedPlaylistItem struct {
+	URL          string `json:"url"` // /playlist?list=ID
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	Thumbnail    string `json:"thumbnail"`
+	UploaderName string `json:"uploaderName"`
+	Videos       int64  `json:"videos"`
+}
+
+type pipedTab struct {
+	Content  []pipedPlaylistItem `json:"content"`
+	NextPage string              `json:"nextpage"`
+}
+
+type pipedAudioStream struct {
+	URL     string `json:"url"`
+	Bitrate int    `json:"bitrate"`
+}
+
+type pipedStreams struct {
+	AudioStreams []pipedAudioStream `json:"audioStreams"`
+}
+
+// PipedService implements SearchService, PlaylistService, ChannelService
+// and StreamResolver on top of the Piped REST API
+type PipedService struct {
+	pool   *instancePool
+	config *yt.SearchConfig
+}
+
+// NewPipedService creates a service that talks to the given Piped API
+// instances, failing over in order. A nil client uses a default one.
+func NewPipedService(instances []string, client *http.Client, maxResults int64) (*PipedService, error) {
+	pool, err := newInstancePool(instances, client)
+	if err != nil {
+		return nil, fmt.Errorf("piped: %w", err)
+	}
+	return &PipedService{pool: pool, config: DefaultSearchConfig(maxResults)}, nil
+}
+
+// Search performs a Piped search with default configuration
+func (s *PipedService) Search(query string) (*yt.SearchResponse, error) {
+	return s.SearchContext(context.Background(), query)
+}
+
+// SearchWithConfig performs a Piped search with custom configuration
+func (s *PipedService) SearchWithConfig(query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(context.Background(), query, config)
+}
+
+// SearchContext performs a Piped search with default configuration
+func (s *PipedService) SearchContext(ctx context.Context, query string) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(ctx, query, s.config)
+}
+
+// SearchWithConfigContext performs a Piped search. Page tokens are Piped's
+// opaque nextpage values. Piped has no ordering or duration filters.
+func (s *PipedService) SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	if config.ChannelID != "" {
+		return s.SearchChannel(ctx, &yt.Channel{ID: config.ChannelID}, query, config)
+	}
+
+	params := url.Values{"q": {query}, "filter": {"videos"}}
+	path := "/search"
+	if config.PageToken != "" {
+		path = "/nextpage/search"
+		params.Set("nextpage", config.PageToken)
+	}
+
+	var page pipedPage
+	if err := s.pool.getJSON(ctx, path, params, &page); err != nil {
+		return nil, fmt.Errorf("error executing search: %w", err)
+	}
+
+	warnIgnoredFilters(BackendPiped, config)
+
+	results := make([]yt.SearchResult, 0, len(page.Items))
+	for _, item := range page.Items {
+		if item.Type != "" && item.Type != "stream" {
+			continue
+		}
+		video := item.toVideo()
+		if !config.PublishedWithin(video) {
+			continue
+		}
+		results = append(results, video)
+		if config.MaxResults > 0 && int64(len(results)) == config.MaxResults {
+			break
+		}
+	}
+
+	return &yt.SearchResponse{
+		Videos:        results,
+		TotalResults:  int64(len(results)),
+		Query:         query,
+		NextPageToken: page.NextPage,
+	}, nil
+}
+
+// GetPlaylistItems retrieves all videos in a playlist, following pages
+func (s *PipedService) GetPlaylistItems(playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	return s.GetPlaylistItemsContext(context.Background(), playlistID, maxResults)
+}
+
+// GetPlaylistItemsContext is GetPlaylistItems with a context
+func (s *PipedService) GetPlaylistItemsContext(ctx context.Context, playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	results := []yt.SearchResult{}
+
+	var page pipedPage
+	if err := s.pool.getJSON(ctx, "/playlists/"+url.PathEscape(playlistID), nil, &page); err != nil {
+		return nil, fmt.Errorf("error fetching playlist items: %w", err)
+	}
+
+	for {
+		for _, item := range page.RelatedStreams {
+			results = append(results, item.toVideo())
+		}
+		if page.NextPage == "" {
+			break
+		}
+
+		nextPage := page.NextPage
+		page = pipedPage{}
+		params := url.Values{"nextpage": {nextPage}}
+		if err := s.pool.getJSON(ctx, "/nextpage/playlists/"+url.PathEscape(playlistID), params, &page); err != nil {
+			return nil, fmt.Errorf("error fetching playlist items: %w", err)
+		}
+	}
+
+	return results, nil
+}
+
+// StreamURL resolves the highest bitrate audio stream of a video
+func (s *PipedService) StreamURL(ctx context.Context, videoURL string) (string, error) {
+	id, err := yt.ParseVideoID(videoURL)
+	if err != nil {
+		return "", err
+	}
+
+	var streams pipedStreams
+	if err := s.pool.getJSON(ctx, "/streams/"+url.PathEscape(id), nil, &streams); err != nil {
+		return "", fmt.Errorf("error resolving stream: %w", err)
+	}
+
+	best, bestBitrate := "", -1
+	for _, stream := range streams.AudioStreams {
+		if stream.Bitrate > bestBitrate {
+			best, bestBitrate = stream.URL, stream.Bitrate
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no audio stream found for %s", id)
+	}
+	return best, nil
+}
+
+// Channel looks up a channel by ID, @handle or channel URL
+func (s *PipedService) Channel(ctx context.Context, ref string) (*yt.Channel, error) {
+	channel, err := s.channelPage(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	_, handle, _ := parseChannelRef(ref)
+	var subscribers uint64
+	if channel.SubscriberCount > 0 {
+		subscribers = uint64(channel.SubscriberCount)
+	}
+	return &yt.Channel{
+		ID:              channel.ID,
+		Title:           channel.Name,
+		Handle:          handle,
+		Description:     channel.Description,
+		SubscriberCount: subscribers,
+		ThumbnailURL:    channel.AvatarURL,
+		URL:             yt.ChannelURL(channel.ID),
+	}, nil
+}
+
+// channelPage fetches the first page of a channel, resolving handles
+func (s *PipedService) channelPage(ctx context.Context, ref string) (*pipedChannel, error) {
+	id, handle, err := parseChannelRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	path := "/channel/" + url.PathEscape(id)
+	if id == "" {
+		path = "/@/" + url.PathEscape(strings.TrimPrefix(handle, "@"))
+	}
+
+	var channel pipedChannel
+	if err := s.pool.getJSON(ctx, path, nil, &channel); err != nil {
+		return nil, fmt.Errorf("error fetching channel: %w", err)
+	}
+	return &channel, nil
+}
+
+// Uploads lists the newest uploads, following nextpage tokens
+func (s *PipedService) Uploads(ctx context.Context, channel *yt.Channel, limit int) ([]yt.Video, error) {
+	page, err := s.channelPage(ctx, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []yt.Video{}
+	items, nextPage := page.RelatedStreams, page.NextPage
+	for {
+		for _, item := range items {
+			results = append(results, item.toVideo())
+		}
+		if nextPage == "" || (limit > 0 && len(results) >= limit) {
+			break
+		}
+
+		var more pipedPage
+		params := url.Values{"nextpage": {nextPage}}
+		if err := s.pool.getJSON(ctx, "/nextpage/channel/"+url.PathEscape(channel.ID), params, &more); err != nil {
+			return nil, fmt.Errorf("error fetching uploads: %w", err)
+		}
+		items, nextPage = more.RelatedStreams, more.NextPage
+	}
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Playlists lists the channel's playlists tab
+func (s *PipedService) Playlists(ctx context.Context, channel *yt.Channel) ([]yt.Playlist, error) {
+	page, err := s.channelPage(ctx, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	playlists := []yt.Playlist{}
+	for _, tab := range page.Tabs {
+		if tab.Name != "playlists" {
+			continue
+		}
+
+		params := url.Values{"data": {tab.Data}}
+		for {
+			var content pipedTab
+			if err := s.pool.getJSON(ctx, "/channels/tabs", params, &content); err != nil {
+				return nil, fmt.Errorf("error fetching playlists: %w", err)
+			}
+			for _, item := range content.Content {
+				if item.Type != "playlist" {
+					continue
+				}
+				id := ""
+				if u, err := url.Parse(item.URL); err == nil {
+					id = u.Query().Get("list")
+				}
+				playlists = append(playlists, yt.Playlist{
+					ID:           id,
+					Title:        item.Name,
+					ChannelTitle: item.UploaderName,
+					ChannelID:    channel.ID,
+					ItemCount:    item.Videos,
+					ThumbnailURL: item.Thumbnail,
+					URL:          yt.PlaylistURL(id),
+				})
+			}
+			if content.NextPage == "" {
+				break
+			}
+			params.Set("nextpage", content.NextPage)
+		}
+	}
+	return playlists, nil
+}
+
+// SearchChannel is not available, Piped cannot restrict searches to a
+// channel
+func (s *PipedService) SearchChannel(ctx context.Context, channel *yt.Channel, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return nil, fmt.Errorf("searching within a channel is not supported by the piped backend")
+}
+
+func (i pipedItem) toVideo() yt.Video {
+	id := ""
+	if u, err := url.Parse(i.URL); err == nil {
+		id = u.Query().Get("v")
+	}
+
+	var publishedAt time.Time
+	if i.Uploaded > 0 {
+		publishedAt = time.UnixMilli(i.Uploaded).UTC()
+	}
+
+	var views uint64
+	if i.Views > 0 {
+		views = uint64(i.Views)
+	}
+
+	length := secondsDuration(float64(i.Duration))
+
+	var live string
+	if i.Duration < 0 {
+		live = "live"
+	}
+
+	return yt.Video{
+		ID:            id,
+		Title:         i.Title,
+		Description:   i.ShortDescription,
+		ChannelTitle:  i.UploaderName,
+		ChannelID:     strings.TrimPrefix(i.UploaderURL, "/channel/"),
+		PublishedAt:   publishedAt,
+		Duration:      yt.FormatISODuration(length),
+		Length:        length,
+		ViewCount:     views,
+		ThumbnailURL:  i.Thumbnail,
+		URL:           fmt.Sprintf("https://www.youtube.com/watch?v=%s", id),
+		LiveBroadcast: live,
+		Short:         i.IsShort,
+		Availability:  yt.TitleAvailability(i.Title),
+	}
+}
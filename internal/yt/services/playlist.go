@@ -1,9 +1,9 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/alanpramil7/gplay/internal/yt"
@@ -13,106 +13,121 @@ import (
 // PlaylistService interface for YouTube playlist operations
 type PlaylistService interface {
 	GetPlaylistItems(playlistID string, maxResults int64) ([]yt.SearchResult, error)
+	GetPlaylistItemsContext(ctx context.Context, playlistID string, maxResults int64) ([]yt.SearchResult, error)
 }
 
+// MaxPlaylistPageSize is the largest page PlaylistItems.List returns;
+// callers pass it as the page size since every page is fetched regardless
+const MaxPlaylistPageSize = int64(50)
+
+// playlistItemParts are the parts requested from PlaylistItems.List
+var playlistItemParts = []string{"id", "snippet", "contentDetails", "status"}
+
 type playlistService struct {
-	client *yt.Client
+	client  *yt.Client
+	details *videoDetailsFetcher
 }
 
 // NewPlaylistService creates a new playlist service instance
 func NewPlaylistService(client *yt.Client) PlaylistService {
 	return &playlistService{
-		client: client,
+		client:  client,
+		details: newVideoDetailsFetcher(client),
 	}
 }
 
-// GetPlaylistItems retrieves all videos (songs) from a playlist
+// GetPlaylistItems retrieves all videos (songs) from a playlist, requesting
+// maxResults items per page up to the API maximum of 50
 func (p *playlistService) GetPlaylistItems(playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	return p.GetPlaylistItemsContext(context.Background(), playlistID, maxResults)
+}
+
+// GetPlaylistItemsContext is GetPlaylistItems with a context
+func (p *playlistService) GetPlaylistItemsContext(ctx context.Context, playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	return p.items(ctx, playlistID, maxResults, 0)
+}
+
+// items follows the pages of a playlist until limit videos have been
+// collected, or to the end when limit is 0
+func (p *playlistService) items(ctx context.Context, playlistID string, maxResults int64, limit int) ([]yt.SearchResult, error) {
 	service := p.client.Service()
+	if maxResults <= 0 || maxResults > MaxPlaylistPageSize {
+		maxResults = MaxPlaylistPageSize
+	}
 
 	results := []yt.SearchResult{}
 	nextPageToken := ""
 
 	for {
-		call := service.PlaylistItems.List([]string{"id", "snippet", "contentDetails"}).
+		call := service.PlaylistItems.List(playlistItemParts).
 			PlaylistId(playlistID).
 			MaxResults(maxResults).
-			PageToken(nextPageToken)
-
-		response, err := call.Do()
+			PageToken(nextPageToken).
+			Context(ctx)
+
+		// The parts are part of the key so entries cached before a part was
+		// added are not served without it
+		cacheKey := []any{playlistItemParts, playlistID, maxResults, nextPageToken}
+		response, err := yt.Fetch(p.client, yt.CallPlaylistItemsList, cacheKey, func(etag string) (*youtube.PlaylistItemListResponse, error) {
+			if etag != "" {
+				call = call.IfNoneMatch(etag)
+			}
+			return yt.Do(ctx, p.client, yt.CallPlaylistItemsList, call.Do)
+		})
 		if err != nil {
 			return nil, fmt.Errorf("error fetching playlist items: %w", err)
 		}
 
-		videoIDs := make([]string, 0, len(response.Items))
-
-		// First pass: collect video IDs and snippet info
+		// First pass: collect snippet info. Deleted and private videos are
+		// kept, marked, so they can be shown for what they are.
 		for _, item := range response.Items {
-			if item.Snippet != nil && item.ContentDetails != nil {
-				videoIDs = append(videoIDs, item.ContentDetails.VideoId)
-
-				publishedAt, _ := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
-
-				result := yt.SearchResult{
-					ID:           item.ContentDetails.VideoId,
-					Title:        item.Snippet.Title,
-					Description:  item.Snippet.Description,
-					ChannelTitle: item.Snippet.ChannelTitle,
-					ChannelID:    item.Snippet.ChannelId,
-					PublishedAt:  publishedAt,
-					ThumbnailURL: getBestThumbnail(item.Snippet.Thumbnails),
-					URL:          fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.ContentDetails.VideoId),
-				}
-				results = append(results, result)
+			videoID := itemVideoID(item)
+			if videoID == "" {
+				continue
 			}
-		}
-
-		// Second pass: get extra details
-		if len(videoIDs) > 0 {
-			details, err := p.getVideoDetails(service, videoIDs)
-			if err != nil {
-				log.Printf("Warning: failed to get video details: %v", err)
-			} else {
-				for i, result := range results {
-					if detail, exists := details[result.ID]; exists {
-						results[i].Duration = detail.Duration
-						results[i].ViewCount = detail.ViewCount
-						results[i].LikeCount = detail.LikeCount
-					}
-				}
+			result := yt.SearchResult{
+				ID:           videoID,
+				URL:          fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID),
+				Availability: itemAvailability(item),
 			}
+			if item.Snippet != nil {
+				result.Title = item.Snippet.Title
+				result.Description = item.Snippet.Description
+				result.ChannelTitle = item.Snippet.ChannelTitle
+				result.ChannelID = item.Snippet.ChannelId
+				result.PublishedAt, _ = time.Parse(time.RFC3339, item.Snippet.PublishedAt)
+				result.ThumbnailURL = getBestThumbnail(item.Snippet.Thumbnails)
+			}
+			results = append(results, result)
 		}
 
 		// Handle pagination
+		if limit > 0 && len(results) >= limit {
+			results = results[:limit]
+			break
+		}
 		if response.NextPageToken == "" {
 			break
 		}
 		nextPageToken = response.NextPageToken
 	}
 
-	return results, nil
-}
-
-// getVideoDetails retrieves detailed information for a list of video IDs
-func (p *playlistService) getVideoDetails(service *youtube.Service, videoIDs []string) (map[string]VideoDetails, error) {
-	call := service.Videos.List([]string{"statistics", "contentDetails"}).
-		Id(strings.Join(videoIDs, ","))
-
-	response, err := call.Do()
-	if err != nil {
-		return nil, fmt.Errorf("error getting video details: %w", err)
+	videoIDs := []string{}
+	for _, result := range results {
+		if result.Playable() {
+			videoIDs = append(videoIDs, result.ID)
+		}
 	}
 
-	details := make(map[string]VideoDetails)
-	for _, video := range response.Items {
-		if video.Statistics != nil && video.ContentDetails != nil {
-			details[video.Id] = VideoDetails{
-				Duration:  video.ContentDetails.Duration,
-				ViewCount: video.Statistics.ViewCount,
-				LikeCount: video.Statistics.LikeCount,
-			}
+	// Second pass: get extra details for every page at once so the lookups
+	// can be batched
+	if len(videoIDs) > 0 {
+		details, err := p.details.fetch(ctx, videoIDs)
+		if err != nil {
+			log.Printf("Warning: failed to get video details: %v", err)
 		}
+		applyVideoDetails(results, details)
 	}
 
-	return details, nil
+	return results, nil
 }
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/yt"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+// Privacy settings accepted by CreatePlaylist
+const (
+	PrivacyPrivate  = "private"
+	PrivacyUnlisted = "unlisted"
+	PrivacyPublic   = "public"
+)
+
+// Privacies lists the valid privacy settings
+var Privacies = []string{PrivacyPrivate, PrivacyUnlisted, PrivacyPublic}
+
+// PlaylistEditor creates and edits the logged in user's playlists. It sits
+// beside PlaylistService, which stays read-only so every backend can
+// implement it; only the Data API with an OAuth login can write.
+type PlaylistEditor interface {
+	CreatePlaylist(ctx context.Context, title, description, privacy string) (*yt.Playlist, error)
+	RenamePlaylist(ctx context.Context, playlistID, title string) error
+	DeletePlaylist(ctx context.Context, playlistID string) error
+	// Items lists every entry of a playlist, uncached, with the entry IDs
+	// the item edits take
+	Items(ctx context.Context, playlistID string) ([]yt.PlaylistItem, error)
+	// InsertItem adds a video at position, or at the end when position is
+	// negative
+	InsertItem(ctx context.Context, playlistID, videoID string, position int) (*yt.PlaylistItem, error)
+	RemoveItem(ctx context.Context, itemID string) error
+	MoveItem(ctx context.Context, playlistID string, item yt.PlaylistItem, position int) error
+}
+
+type playlistEditor struct {
+	client *yt.Client
+}
+
+// NewPlaylistEditor creates a playlist editor. The client must be
+// authorized as the user, see yt.NewAuthorizedClient.
+func NewPlaylistEditor(client *yt.Client) PlaylistEditor {
+	return &playlistEditor{client: client}
+}
+
+// ParsePrivacy checks a privacy setting, defaulting to private
+func ParsePrivacy(privacy string) (string, error) {
+	privacy = strings.ToLower(strings.TrimSpace(privacy))
+	if privacy == "" {
+		return PrivacyPrivate, nil
+	}
+	for _, p := range Privacies {
+		if privacy == p {
+			return privacy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid privacy %q (valid: %s)", privacy, strings.Join(Privacies, ", "))
+}
+
+// CreatePlaylist creates an empty playlist
+func (e *playlistEditor) CreatePlaylist(ctx context.Context, title, description, privacy string) (*yt.Playlist, error) {
+	privacy, err := ParsePrivacy(privacy)
+	if err != nil {
+		return nil, err
+	}
+	playlist := &youtube.Playlist{
+		Snippet: &youtube.PlaylistSnippet{Title: title, Description: description},
+		Status:  &youtube.PlaylistStatus{PrivacyStatus: privacy},
+	}
+	call := e.client.Service().Playlists.Insert([]string{"snippet", "status"}, playlist).Context(ctx)
+
+	created, err := yt.Do(ctx, e.client, yt.CallPlaylistsInsert, call.Do)
+	if err != nil {
+		return nil, fmt.Errorf("error creating playlist: %w", err)
+	}
+	result := apiPlaylist(created)
+	return &result, nil
+}
+
+// RenamePlaylist changes a playlist's title. Updates replace the whole
+// snippet, so the current one is read first to keep the description.
+func (e *playlistEditor) RenamePlaylist(ctx context.Context, playlistID, title string) error {
+	list := e.client.Service().Playlists.List([]string{"snippet"}).Id(playlistID).Context(ctx)
+	response, err := yt.Do(ctx, e.client, yt.CallPlaylistsList, list.Do)
+	if err != nil {
+		return fmt.Errorf("error fetching playlist: %w", err)
+	}
+	if len(response.Items) == 0 || response.Items[0].Snippet == nil {
+		return fmt.Errorf("playlist %s: %w", playlistID, yt.ErrNotFound)
+	}
+
+	snippet := response.Items[0].Snippet
+	playlist := &youtube.Playlist{
+		Id:      playlistID,
+		Snippet: &youtube.PlaylistSnippet{Title: title, Description: snippet.Description, DefaultLanguage: snippet.DefaultLanguage},
+	}
+	call := e.client.Service().Playlists.Update([]string{"snippet"}, playlist).Context(ctx)
+	if _, err := yt.Do(ctx, e.client, yt.CallPlaylistsUpdate, call.Do); err != nil {
+		return fmt.Errorf("error renaming playlist: %w", err)
+	}
+	return nil
+}
+
+// DeletePlaylist deletes a playlist
+func (e *playlistEditor) DeletePlaylist(ctx context.Context, playlistID string) error {
+	call := e.client.Service().Playlists.Delete(playlistID).Context(ctx)
+	if err := doDelete(ctx, e.client, yt.CallPlaylistsDelete, call.Do); err != nil {
+		return fmt.Errorf("error deleting playlist: %w", err)
+	}
+	return nil
+}
+
+// Items lists every entry of a playlist, uncached, with the entry IDs the
+// item edits take
+func (e *playlistEditor) Items(ctx context.Context, playlistID string) ([]yt.PlaylistItem, error) {
+	items := []yt.PlaylistItem{}
+	nextPageToken := ""
+
+	for {
+		call := e.client.Service().PlaylistItems.List([]string{"id", "snippet", "status"}).
+			PlaylistId(playlistID).
+			MaxResults(MaxPlaylistPageSize).
+			PageToken(nextPageToken).
+			Context(ctx)
+
+		// A cached page would be stale after the first edit
+		response, err := yt.Do(ctx, e.client, yt.CallPlaylistItemsList, call.Do)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching playlist items: %w", err)
+		}
+		for _, item := range response.Items {
+			items = append(items, apiPlaylistItem(item))
+		}
+
+		if response.NextPageToken == "" {
+			break
+		}
+		nextPageToken = response.NextPageToken
+	}
+	return items, nil
+}
+
+// InsertItem adds a video at position, or at the end when position is
+// negative
+func (e *playlistEditor) InsertItem(ctx context.Context, playlistID, videoID string, position int) (*yt.PlaylistItem, error) {
+	item := &youtube.PlaylistItem{
+		Snippet: &youtube.PlaylistItemSnippet{
+			PlaylistId: playlistID,
+			ResourceId: &youtube.ResourceId{Kind: "youtube#video", VideoId: videoID},
+		},
+	}
+	if position >= 0 {
+		item.Snippet.Position = int64(position)
+		// Position 0 is the zero value and would otherwise be left out
+		item.Snippet.ForceSendFields = []string{"Position"}
+	}
+	call := e.client.Service().PlaylistItems.Insert([]string{"snippet"}, item).Context(ctx)
+
+	inserted, err := yt.Do(ctx, e.client, yt.CallPlaylistItemsInsert, call.Do)
+	if err != nil {
+		return nil, fmt.Errorf("error adding to playlist: %w", err)
+	}
+	result := apiPlaylistItem(inserted)
+	return &result, nil
+}
+
+// RemoveItem removes one entry from its playlist
+func (e *playlistEditor) RemoveItem(ctx context.Context, itemID string) error {
+	call := e.client.Service().PlaylistItems.Delete(itemID).Context(ctx)
+	if err := doDelete(ctx, e.client, yt.CallPlaylistItemsDelete, call.Do); err != nil {
+		return fmt.Errorf("error removing from playlist: %w", err)
+	}
+	return nil
+}
+
+// MoveItem moves an entry to position within its playlist
+func (e *playlistEditor) MoveItem(ctx context.Context, playlistID string, item yt.PlaylistItem, position int) error {
+	update := &youtube.PlaylistItem{
+		Id: item.ID,
+		Snippet: &youtube.PlaylistItemSnippet{
+			PlaylistId:      playlistID,
+			ResourceId:      &youtube.ResourceId{Kind: "youtube#video", VideoId: item.VideoID},
+			Position:        int64(position),
+			ForceSendFields: []string{"Position"},
+		},
+	}
+	call := e.client.Service().PlaylistItems.Update([]string{"snippet"}, update).Context(ctx)
+	if _, err := yt.Do(ctx, e.client, yt.CallPlaylistItemsUpdate, call.Do); err != nil {
+		return fmt.Errorf("error moving playlist item: %w", err)
+	}
+	return nil
+}
+
+// ApplyChanges makes the edits of a library.Plan in order, calling done
+// after each one. It stops at the first failure; the edits made so far
+// stay.
+func ApplyChanges(ctx context.Context, editor PlaylistEditor, playlistID string, changes []library.Change, done func(library.Change)) error {
+	for _, change := range changes {
+		var err error
+		switch change.Kind {
+		case library.ChangeAdd:
+			_, err = editor.InsertItem(ctx, playlistID, change.VideoID, change.Position)
+		case library.ChangeRemove:
+			err = editor.RemoveItem(ctx, change.Item.ID)
+		case library.ChangeMove:
+			err = editor.MoveItem(ctx, playlistID, change.Item, change.Position)
+		default:
+			err = fmt.Errorf("unknown change %q", change.Kind)
+		}
+		if err != nil {
+			return err
+		}
+		if done != nil {
+			done(change)
+		}
+	}
+	return nil
+}
+
+// doDelete runs a delete call, which has no response body, through yt.Do
+func doDelete(ctx context.Context, client *yt.Client, call string, do func(...googleapi.CallOption) error) error {
+	_, err := yt.Do(ctx, client, call, func(opts ...googleapi.CallOption) (*struct{}, error) {
+		return &struct{}{}, do(opts...)
+	})
+	return err
+}
+
+func apiPlaylistItem(item *youtube.PlaylistItem) yt.PlaylistItem {
+	result := yt.PlaylistItem{ID: item.Id, Availability: itemAvailability(item)}
+	if item.Snippet != nil {
+		result.Title = item.Snippet.Title
+		result.Position = int(item.Snippet.Position)
+	}
+	result.VideoID = itemVideoID(item)
+	return result
+}
+
+// itemAvailability tells whether a playlist entry's video is gone. The
+// API keeps such entries with a placeholder title instead of the video's.
+func itemAvailability(item *youtube.PlaylistItem) yt.Availability {
+	if item.Snippet == nil {
+		return yt.AvailabilityDeleted
+	}
+	if availability := yt.TitleAvailability(item.Snippet.Title); availability != yt.Available {
+		return availability
+	}
+	if item.Status != nil && item.Status.PrivacyStatus == "private" {
+		return yt.AvailabilityPrivate
+	}
+	return yt.Available
+}
+
+// itemVideoID returns the video of a playlist entry, which deleted
+// entries only carry in their content details
+func itemVideoID(item *youtube.PlaylistItem) string {
+	if item.ContentDetails != nil && item.ContentDetails.VideoId != "" {
+		return item.ContentDetails.VideoId
+	}
+	if item.Snippet != nil && item.Snippet.ResourceId != nil {
+		return item.Snippet.ResourceId.VideoId
+	}
+	return ""
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alanpramil7/gplay/internal/library"
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// fakePlaylist is one playlist held by the fake API, edited the way
+// YouTube edits it. Every edit is logged as e.g. "insert d at 2".
+type fakePlaylist struct {
+	title, description string
+	items              []fakeEntry
+	edits              []string
+	nextID             int
+	// failInsert makes inserting this video fail
+	failInsert string
+}
+
+type fakeEntry struct {
+	id, videoID string
+}
+
+// newFakePlaylist serves PLedit holding videoIDs, in entries item-<video>
+func newFakePlaylist(t *testing.T, videoIDs ...string) (*fakePlaylist, *fakeDataAPI) {
+	p := &fakePlaylist{title: "Old", description: "Kept"}
+	for _, id := range videoIDs {
+		p.items = append(p.items, fakeEntry{id: "item-" + id, videoID: id})
+	}
+
+	api := newFakeAPI(t)
+	api.handle("playlists", p.servePlaylists)
+	api.handle("playlistItems", p.serveItems)
+	return p, api
+}
+
+func (p *fakePlaylist) videoIDs() []string {
+	var ids []string
+	for _, e := range p.items {
+		ids = append(ids, e.videoID)
+	}
+	return ids
+}
+
+func (p *fakePlaylist) indexOf(itemID string) int {
+	return slices.IndexFunc(p.items, func(e fakeEntry) bool { return e.id == itemID })
+}
+
+func (p *fakePlaylist) servePlaylists(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Id      string
+		Snippet struct{ Title, Description string }
+		Status  struct{ PrivacyStatus string }
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("id") != "PLedit" {
+			writeAPIJSON(w, map[string]any{"items": []any{}})
+			return
+		}
+		writeAPIJSON(w, map[string]any{"items": []map[string]any{{
+			"id":      "PLedit",
+			"snippet": map[string]any{"title": p.title, "description": p.description},
+		}}})
+	case http.MethodPost:
+		p.edits = append(p.edits, fmt.Sprintf("create %s %s", body.Snippet.Title, body.Status.PrivacyStatus))
+		writeAPIJSON(w, map[string]any{
+			"id":      "PLnew",
+			"snippet": map[string]any{"title": body.Snippet.Title, "description": body.Snippet.Description},
+		})
+	case http.MethodPut:
+		p.title, p.description = body.Snippet.Title, body.Snippet.Description
+		p.edits = append(p.edits, "rename "+body.Id+" to "+body.Snippet.Title)
+		writeAPIJSON(w, map[string]any{"id": body.Id})
+	case http.MethodDelete:
+		p.edits = append(p.edits, "delete "+r.URL.Query().Get("id"))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (p *fakePlaylist) serveItems(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Id      string
+		Snippet struct {
+			PlaylistId string
+			Position   *int
+			ResourceId struct{ VideoId string }
+		}
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	switch r.Method {
+	case http.MethodGet:
+		items, next := fakePage(r, len(p.items), 2, func(i int) map[string]any {
+			return map[string]any{
+				"id": p.items[i].id,
+				"snippet": map[string]any{
+					"title":      "Video " + p.items[i].videoID,
+					"position":   i,
+					"resourceId": map[string]any{"videoId": p.items[i].videoID},
+				},
+			}
+		})
+		writeAPIJSON(w, map[string]any{"items": items, "nextPageToken": next})
+
+	case http.MethodPost:
+		videoID := body.Snippet.ResourceId.VideoId
+		if videoID == p.failInsert {
+			writeAPIError(w, http.StatusForbidden, "playlistContainsMaximumNumberOfVideos")
+			return
+		}
+		p.nextID++
+		entry := fakeEntry{id: fmt.Sprintf("new-%d", p.nextID), videoID: videoID}
+		at := len(p.items)
+		if body.Snippet.Position != nil {
+			at = *body.Snippet.Position
+			p.edits = append(p.edits, fmt.Sprintf("insert %s at %d", videoID, at))
+		} else {
+			p.edits = append(p.edits, "insert "+videoID+" at the end")
+		}
+		p.items = slices.Insert(p.items, at, entry)
+		writeAPIJSON(w, map[string]any{"id": entry.id, "snippet": map[string]any{"position": at, "resourceId": map[string]any{"videoId": videoID}}})
+
+	case http.MethodPut:
+		from := p.indexOf(body.Id)
+		if from < 0 || body.Snippet.Position == nil || body.Snippet.PlaylistId != "PLedit" {
+			writeAPIError(w, http.StatusBadRequest, "invalidPlaylistItem")
+			return
+		}
+		entry := p.items[from]
+		p.items = slices.Insert(slices.Delete(p.items, from, from+1), *body.Snippet.Position, entry)
+		p.edits = append(p.edits, fmt.Sprintf("move %s to %d", body.Id, *body.Snippet.Position))
+		writeAPIJSON(w, map[string]any{"id": body.Id})
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		at := p.indexOf(id)
+		if at < 0 {
+			writeAPIError(w, http.StatusNotFound, "playlistItemNotFound")
+			return
+		}
+		p.items = slices.Delete(p.items, at, at+1)
+		p.edits = append(p.edits, "delete "+id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestPlaylistEditorPlaylists(t *testing.T) {
+	playlist, api := newFakePlaylist(t)
+	editor := NewPlaylistEditor(api.client(t, fakeAPIToken))
+	ctx := context.Background()
+
+	created, err := editor.CreatePlaylist(ctx, "Road trip", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != "PLnew" || created.Title != "Road trip" {
+		t.Errorf("CreatePlaylist = %+v", created)
+	}
+	if _, err := editor.CreatePlaylist(ctx, "Road trip", "", "secret"); err == nil {
+		t.Error("CreatePlaylist accepted an unknown privacy")
+	}
+
+	if err := editor.RenamePlaylist(ctx, "PLedit", "New"); err != nil {
+		t.Fatal(err)
+	}
+	if playlist.title != "New" || playlist.description != "Kept" {
+		t.Errorf("after renaming the playlist is %q, %q; want the description kept", playlist.title, playlist.description)
+	}
+	if err := editor.RenamePlaylist(ctx, "PLmissing", "New"); !errors.Is(err, yt.ErrNotFound) {
+		t.Errorf("RenamePlaylist of a missing playlist = %v, want ErrNotFound", err)
+	}
+
+	if err := editor.DeletePlaylist(ctx, "PLedit"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"create Road trip private", "rename PLedit to New", "delete PLedit"}
+	if !slices.Equal(playlist.edits, want) {
+		t.Errorf("edits = %q, want %q", playlist.edits, want)
+	}
+}
+
+func TestPlaylistEditorItems(t *testing.T) {
+	_, api := newFakePlaylist(t, "a", "b", "c", "d", "e")
+	items, err := NewPlaylistEditor(api.client(t, fakeAPIToken)).Items(context.Background(), "PLedit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for i, item := range items {
+		got = append(got, item.VideoID)
+		if item.ID != "item-"+item.VideoID || item.Position != i {
+			t.Errorf("item %d = %+v", i, item)
+		}
+	}
+	if strings.Join(got, "") != "abcde" {
+		t.Errorf("items = %q, want every page", got)
+	}
+	if n := len(api.queries("/playlistItems")); n != 3 {
+		t.Errorf("listed %d pages, want 3", n)
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		remote []string
+		local  []string
+		edits  []string
+	}{
+		{
+			name:   "remove, move and add",
+			remote: []string{"a", "b", "c"},
+			local:  []string{"c", "a", "d"},
+			edits:  []string{"delete item-b", "move item-c to 0", "insert d at 2"},
+		},
+		{
+			name:   "add at the front",
+			remote: []string{"a", "b"},
+			local:  []string{"z", "a", "b"},
+			edits:  []string{"insert z at 0"},
+		},
+		{
+			name:   "reverse",
+			remote: []string{"a", "b", "c"},
+			local:  []string{"c", "b", "a"},
+			edits:  []string{"move item-c to 0", "move item-b to 1"},
+		},
+		{
+			name:   "empty the playlist",
+			remote: []string{"a", "b"},
+			edits:  []string{"delete item-a", "delete item-b"},
+		},
+		{
+			name:   "nothing to do",
+			remote: []string{"a", "b"},
+			local:  []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist, api := newFakePlaylist(t, tt.remote...)
+			editor := NewPlaylistEditor(api.client(t, fakeAPIToken))
+			ctx := context.Background()
+
+			remote, err := editor.Items(ctx, "PLedit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var local []yt.Video
+			for _, id := range tt.local {
+				local = append(local, yt.Video{ID: id})
+			}
+			changes := library.Plan(local, remote)
+
+			var done []library.Change
+			if err := ApplyChanges(ctx, editor, "PLedit", changes, func(c library.Change) { done = append(done, c) }); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(playlist.edits, tt.edits) {
+				t.Errorf("edits = %q, want %q", playlist.edits, tt.edits)
+			}
+			if got := playlist.videoIDs(); !slices.Equal(got, tt.local) {
+				t.Errorf("playlist = %q, want %q", got, tt.local)
+			}
+			if len(done) != len(changes) {
+				t.Errorf("done called %d times for %d changes", len(done), len(changes))
+			}
+		})
+	}
+}
+
+func TestApplyChangesStopsAtTheFirstFailure(t *testing.T) {
+	playlist, api := newFakePlaylist(t, "a", "b")
+	playlist.failInsert = "y"
+	editor := NewPlaylistEditor(api.client(t, fakeAPIToken))
+
+	changes := []library.Change{
+		{Kind: library.ChangeRemove, VideoID: "a", Item: yt.PlaylistItem{ID: "item-a", VideoID: "a"}},
+		{Kind: library.ChangeAdd, VideoID: "x", Position: 0},
+		{Kind: library.ChangeAdd, VideoID: "y", Position: 1},
+		{Kind: library.ChangeAdd, VideoID: "z", Position: 2},
+	}
+	var done int
+	err := ApplyChanges(context.Background(), editor, "PLedit", changes, func(library.Change) { done++ })
+	if err == nil || !strings.Contains(err.Error(), "error adding to playlist") {
+		t.Fatalf("ApplyChanges = %v, want the failed insert", err)
+	}
+	// The edits before the failure stay
+	want := []string{"delete item-a", "insert x at 0"}
+	if !slices.Equal(playlist.edits, want) || done != 2 {
+		t.Errorf("edits = %q with done called %d times, want %q", playlist.edits, done, want)
+	}
+	if got := playlist.videoIDs(); !slices.Equal(got, []string{"x", "b"}) {
+		t.Errorf("playlist = %q", got)
+	}
+}
+
+func TestInsertItemAtTheEnd(t *testing.T) {
+	playlist, api := newFakePlaylist(t, "a")
+	item, err := NewPlaylistEditor(api.client(t, fakeAPIToken)).InsertItem(context.Background(), "PLedit", "b", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.VideoID != "b" || item.Position != 1 {
+		t.Errorf("InsertItem = %+v", item)
+	}
+	if !slices.Equal(playlist.edits, []string{"insert b at the end"}) {
+		t.Errorf("edits = %q", playlist.edits)
+	}
+}
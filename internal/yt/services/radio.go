@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// Radio seed strategies
+const (
+	// RadioMix plays the YouTube Mix of the seed track, falling back to a
+	// search when there is none
+	RadioMix = "mix"
+	// RadioSearch searches for more by the seed track's channel
+	RadioSearch = "search"
+)
+
+// RadioStrategies lists the valid seed strategies
+var RadioStrategies = []string{RadioMix, RadioSearch}
+
+// mixPrefix starts the ID of the mix YouTube generates for a video
+const mixPrefix = "RD"
+
+// maxMixExtra caps the tracks asked for beyond the limit to make up for
+// skipped ones; mixes are short, so asking for the whole history is no use
+const maxMixExtra = 20
+
+// MixService lists the mix YouTube generates for a video: an open-ended
+// playlist of related tracks starting with the video itself
+type MixService interface {
+	Mix(ctx context.Context, videoID string, limit int) ([]yt.Video, error)
+}
+
+// Radio picks tracks related to what is playing to keep playback going once
+// a queue runs out
+type Radio struct {
+	strategy string
+	mix      MixService
+	search   SearchService
+}
+
+// NewRadio creates a radio seeded with the given strategy. Either service
+// may be nil; the radio then only uses the other.
+func NewRadio(strategy string, mix MixService, search SearchService) (*Radio, error) {
+	switch strategy {
+	case RadioMix, RadioSearch:
+	default:
+		return nil, fmt.Errorf("unknown radio strategy %q (valid: %s)", strategy, strings.Join(RadioStrategies, ", "))
+	}
+	return &Radio{strategy: strategy, mix: mix, search: search}, nil
+}
+
+// Related returns at most limit tracks related to seed, leaving out the IDs
+// in skip so recent plays do not repeat
+func (r *Radio) Related(ctx context.Context, seed yt.Video, skip map[string]bool, limit int) ([]yt.Video, error) {
+	var errs []error
+	if r.strategy == RadioMix && r.mix != nil {
+		// Ask for extra since the seed and recent plays are dropped
+		videos, err := r.mix.Mix(ctx, seed.ID, limit+min(len(skip), maxMixExtra))
+		switch {
+		case err == nil:
+			if related := unseen(videos, skip, limit); len(related) > 0 {
+				return related, nil
+			}
+		case ctx.Err() != nil:
+			return nil, err
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	if r.search != nil {
+		query := seed.ChannelTitle
+		if query == "" {
+			query = seed.Title
+		}
+		response, err := r.search.SearchWithConfigContext(ctx, query, DefaultSearchConfig(min(int64(limit*2), maxSearchPageSize)))
+		if err == nil {
+			return unseen(response.Videos, skip, limit), nil
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("error finding related tracks: %w", errors.Join(errs...))
+}
+
+// unseen keeps the first limit videos not in skip, dropping repeats
+func unseen(videos []yt.Video, skip map[string]bool, limit int) []yt.Video {
+	seen := make(map[string]bool, len(videos))
+	kept := make([]yt.Video, 0, limit)
+	for _, v := range videos {
+		if len(kept) == limit {
+			break
+		}
+		if v.ID == "" || skip[v.ID] || seen[v.ID] {
+			continue
+		}
+		seen[v.ID] = true
+		kept = append(kept, v)
+	}
+	return kept
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// fakeMix answers with videos or err and records the limits asked for
+type fakeMix struct {
+	videos []yt.Video
+	err    error
+	limits []int
+}
+
+func (f *fakeMix) Mix(ctx context.Context, videoID string, limit int) ([]yt.Video, error) {
+	f.limits = append(f.limits, limit)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.videos, nil
+}
+
+// testVideos returns a video for each ID
+func testVideos(ids ...string) []yt.Video {
+	videos := make([]yt.Video, len(ids))
+	for i, id := range ids {
+		videos[i] = yt.Video{ID: id}
+	}
+	return videos
+}
+
+func TestRadioRelated(t *testing.T) {
+	seed := yt.Video{ID: "seed", Title: "Song", ChannelTitle: "Band"}
+	mixErr := errors.New("yt-dlp failed")
+	searchErr := errors.New("quota exceeded")
+
+	tests := []struct {
+		name       string
+		strategy   string
+		mix        *fakeMix
+		search     *fakeSearch
+		skip       []string
+		want       []string
+		wantErr    []error
+		wantSearch bool
+	}{
+		{
+			name:     "mix",
+			strategy: RadioMix,
+			mix:      &fakeMix{videos: testVideos("seed", "a", "b", "c", "d")},
+			search:   &fakeSearch{response: &yt.SearchResponse{Videos: testVideos("s")}},
+			skip:     []string{"seed", "b"},
+			want:     []string{"a", "c", "d"},
+		},
+		{
+			name:       "mix with nothing new falls back to search",
+			strategy:   RadioMix,
+			mix:        &fakeMix{videos: testVideos("seed", "a")},
+			search:     &fakeSearch{response: &yt.SearchResponse{Videos: testVideos("a", "s1", "s2")}},
+			skip:       []string{"seed", "a"},
+			want:       []string{"s1", "s2"},
+			wantSearch: true,
+		},
+		{
+			name:       "mix error falls back to search",
+			strategy:   RadioMix,
+			mix:        &fakeMix{err: mixErr},
+			search:     &fakeSearch{response: &yt.SearchResponse{Videos: testVideos("s1")}},
+			want:       []string{"s1"},
+			wantSearch: true,
+		},
+		{
+			name:       "both fail",
+			strategy:   RadioMix,
+			mix:        &fakeMix{err: mixErr},
+			search:     &fakeSearch{err: searchErr},
+			wantErr:    []error{mixErr, searchErr},
+			wantSearch: true,
+		},
+		{
+			name:       "search strategy skips the mix",
+			strategy:   RadioSearch,
+			mix:        &fakeMix{videos: testVideos("a")},
+			search:     &fakeSearch{response: &yt.SearchResponse{Videos: testVideos("seed", "s1", "s1", "s2")}},
+			skip:       []string{"seed"},
+			want:       []string{"s1", "s2"},
+			wantSearch: true,
+		},
+		{
+			name:     "no search service",
+			strategy: RadioMix,
+			mix:      &fakeMix{err: mixErr},
+			wantErr:  []error{mixErr},
+		},
+		{
+			name:     "mix runs dry without a search service",
+			strategy: RadioMix,
+			mix:      &fakeMix{videos: testVideos("seed")},
+			skip:     []string{"seed"},
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var search SearchService
+			if tt.search != nil {
+				search = tt.search
+			}
+			radio, err := NewRadio(tt.strategy, tt.mix, search)
+			if err != nil {
+				t.Fatal(err)
+			}
+			skip := map[string]bool{}
+			for _, id := range tt.skip {
+				skip[id] = true
+			}
+
+			got, err := radio.Related(context.Background(), seed, skip, 3)
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("Related error = %v, want it to include %v", err, want)
+				}
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Related = %v", err)
+			}
+			var ids []string
+			for _, v := range got {
+				ids = append(ids, v.ID)
+			}
+			if tt.want != nil && strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Related = %v, want %v", ids, tt.want)
+			}
+			if searched := tt.search != nil && len(tt.search.queries) > 0; searched != tt.wantSearch {
+				t.Errorf("searched = %v, want %v", searched, tt.wantSearch)
+			}
+			if tt.wantSearch && tt.search.queries[0] != "Band" {
+				t.Errorf("searched for %q, want the channel", tt.search.queries[0])
+			}
+		})
+	}
+}
+
+func TestRadioMixLimit(t *testing.T) {
+	mix := &fakeMix{videos: testVideos("a")}
+	radio, err := NewRadio(RadioMix, mix, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	skip := map[string]bool{"x": true, "y": true}
+	radio.Related(context.Background(), yt.Video{ID: "seed"}, skip, 10)
+	for i := range 500 {
+		skip[strings.Repeat("z", i+1)] = true
+	}
+	radio.Related(context.Background(), yt.Video{ID: "seed"}, skip, 10)
+
+	// A long history only adds a capped margin
+	if want := []int{12, 10 + maxMixExtra}; !slices.Equal(mix.limits, want) {
+		t.Errorf("mix limits = %v, want %v", mix.limits, want)
+	}
+}
+
+func TestRadioSearchFallsBackToTitle(t *testing.T) {
+	search := &fakeSearch{response: &yt.SearchResponse{}}
+	radio, err := NewRadio(RadioSearch, nil, search)
+	if err != nil {
+		t.Fatal(err)
+	}
+	radio.Related(context.Background(), yt.Video{ID: "seed", Title: "Song"}, nil, 5)
+	if len(search.queries) != 1 || search.queries[0] != "Song" || search.configs[0].MaxResults != 10 {
+		t.Errorf("searches = %q with %+v, want one for the title", search.queries, search.configs)
+	}
+}
+
+func TestRadioCancelledMix(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	search := &fakeSearch{response: &yt.SearchResponse{Videos: testVideos("s")}}
+	radio, err := NewRadio(RadioMix, &fakeMix{err: context.Canceled}, search)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := radio.Related(ctx, yt.Video{ID: "seed"}, nil, 5); !errors.Is(err, context.Canceled) || len(search.queries) != 0 {
+		t.Errorf("Related = %v after %d searches, want context.Canceled before any", err, len(search.queries))
+	}
+}
+
+func TestNewRadioUnknownStrategy(t *testing.T) {
+	if _, err := NewRadio("shuffle", nil, nil); err == nil {
+		t.Error("NewRadio accepted an unknown strategy")
+	}
+}
+
+func TestUnseen(t *testing.T) {
+	videos := testVideos("a", "", "b", "a", "c", "d", "e")
+	tests := []struct {
+		skip  []string
+		limit int
+		want  string
+	}{
+		{limit: 10, want: "a,b,c,d,e"},
+		{limit: 2, want: "a,b"},
+		{limit: 0, want: ""},
+		{skip: []string{"a", "c"}, limit: 2, want: "b,d"},
+		{skip: []string{"a", "b", "c", "d", "e"}, limit: 3, want: ""},
+	}
+	for _, tt := range tests {
+		skip := map[string]bool{}
+		for _, id := range tt.skip {
+			skip[id] = true
+		}
+		var ids []string
+		for _, v := range unseen(videos, skip, tt.limit) {
+			ids = append(ids, v.ID)
+		}
+		if got := strings.Join(ids, ","); got != tt.want {
+			t.Errorf("unseen skipping %v, limit %d = %q, want %q", tt.skip, tt.limit, got, tt.want)
+		}
+	}
+}
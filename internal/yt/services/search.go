@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,41 +17,63 @@ const (
 	defaultSafeSearch    = "moderate"
 	defaultVideoDuration = "any"
 	defaultVideoType     = "any"
+
+	// maxSearchPageSize is the largest page the Search.List endpoint returns
+	maxSearchPageSize = int64(50)
 )
 
-// SearchService interface for YouTube search operations
+// SearchService interface for YouTube search operations. The Context
+// variants stop waiting and abort in-flight requests when ctx is done.
 type SearchService interface {
 	Search(query string) (*yt.SearchResponse, error)
 	SearchWithConfig(query string, config *yt.SearchConfig) (*yt.SearchResponse, error)
+	SearchContext(ctx context.Context, query string) (*yt.SearchResponse, error)
+	SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error)
 }
 
 type searchService struct {
-	client *yt.Client
-	config *yt.SearchConfig
+	client  *yt.Client
+	config  *yt.SearchConfig
+	details *videoDetailsFetcher
 }
 
 // NewSearchService creates a new search service instance
 func NewSearchService(client *yt.Client, maxResults int64) SearchService {
-	config := &yt.SearchConfig{
+	return &searchService{
+		client:  client,
+		config:  DefaultSearchConfig(maxResults),
+		details: newVideoDetailsFetcher(client),
+	}
+}
+
+// DefaultSearchConfig returns the configuration used by Search
+func DefaultSearchConfig(maxResults int64) *yt.SearchConfig {
+	return &yt.SearchConfig{
 		MaxResults:    maxResults,
 		Order:         defaultOrder,
 		SafeSearch:    defaultSafeSearch,
 		VideoDuration: defaultVideoDuration,
 		VideoType:     defaultVideoType,
 	}
-	return &searchService{
-		client: client,
-		config: config,
-	}
 }
 
 // Search performs a YouTube search with default configuration
 func (s *searchService) Search(query string) (*yt.SearchResponse, error) {
-	return s.SearchWithConfig(query, s.config)
+	return s.SearchContext(context.Background(), query)
 }
 
 // SearchWithConfig performs a YouTube search with custom configuration
 func (s *searchService) SearchWithConfig(query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(context.Background(), query, config)
+}
+
+// SearchContext performs a YouTube search with default configuration
+func (s *searchService) SearchContext(ctx context.Context, query string) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(ctx, query, s.config)
+}
+
+// SearchWithConfigContext performs a YouTube search with custom configuration
+func (s *searchService) SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
 	service := s.client.Service()
 
 	// Build the search call
@@ -60,10 +84,51 @@ func (s *searchService) SearchWithConfig(query string, config *yt.SearchConfig)
 		SafeSearch(config.SafeSearch).
 		VideoDuration(config.VideoDuration).
 		VideoType(config.VideoType).
-		Type("video")
+		Type("video").
+		Context(ctx)
+
+	if config.ChannelID != "" {
+		call = call.ChannelId(config.ChannelID)
+	}
+	if !config.PublishedAfter.IsZero() {
+		call = call.PublishedAfter(config.PublishedAfter.UTC().Format(time.RFC3339))
+	}
+	if !config.PublishedBefore.IsZero() {
+		call = call.PublishedBefore(config.PublishedBefore.UTC().Format(time.RFC3339))
+	}
+	if config.RegionCode != "" {
+		call = call.RegionCode(config.RegionCode)
+	}
+	if config.RelevanceLanguage != "" {
+		call = call.RelevanceLanguage(config.RelevanceLanguage)
+	}
+	if config.VideoCategoryID != "" {
+		call = call.VideoCategoryId(config.VideoCategoryID)
+	}
+	if config.EventType != "" {
+		call = call.EventType(config.EventType)
+	}
+	if config.VideoCaption != "" {
+		call = call.VideoCaption(config.VideoCaption)
+	}
+	if config.VideoDefinition != "" {
+		call = call.VideoDefinition(config.VideoDefinition)
+	}
+	if config.PageToken != "" {
+		call = call.PageToken(config.PageToken)
+	}
 
 	// Execute the search
-	response, err := call.Do()
+	cacheKey := struct {
+		Query  string
+		Config yt.SearchConfig
+	}{query, *config}
+	response, err := yt.Fetch(s.client, yt.CallSearchList, cacheKey, func(etag string) (*youtube.SearchListResponse, error) {
+		if etag != "" {
+			call = call.IfNoneMatch(etag)
+		}
+		return yt.Do(ctx, s.client, yt.CallSearchList, call.Do)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error executing search: %w", err)
 	}
@@ -88,6 +153,8 @@ func (s *searchService) SearchWithConfig(query string, config *yt.SearchConfig)
 				PublishedAt:  publishedAt,
 				ThumbnailURL: getBestThumbnail(item.Snippet.Thumbnails),
 				URL:          fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.Id.VideoId),
+				// Search reports "none" for ordinary videos
+				LiveBroadcast: liveBroadcast(item.Snippet.LiveBroadcastContent),
 			}
 			results = append(results, result)
 		}
@@ -95,19 +162,11 @@ func (s *searchService) SearchWithConfig(query string, config *yt.SearchConfig)
 
 	// Second pass: get detailed video information
 	if len(videoIDs) > 0 {
-		details, err := s.getVideoDetails(service, videoIDs)
+		details, err := s.details.fetch(ctx, videoIDs)
 		if err != nil {
 			log.Printf("Warning: failed to get video details: %v", err)
-		} else {
-			// Merge details with basic info
-			for i, result := range results {
-				if detail, exists := details[result.ID]; exists {
-					results[i].Duration = detail.Duration
-					results[i].ViewCount = detail.ViewCount
-					results[i].LikeCount = detail.LikeCount
-				}
-			}
 		}
+		applyVideoDetails(results, details)
 	}
 
 	return &yt.SearchResponse{
@@ -118,38 +177,38 @@ func (s *searchService) SearchWithConfig(query string, config *yt.SearchConfig)
 	}, nil
 }
 
-// VideoDetails holds additional video information
-type VideoDetails struct {
-	Duration  string `json:"duration"`
-	ViewCount uint64 `json:"view_count"`
-	LikeCount uint64 `json:"like_count"`
+// SearchAll keeps following NextPageToken until limit videos have been
+// collected or the results run out. The returned NextPageToken can be used to
+// resume after the last collected page.
+func SearchAll(s SearchService, query string, config *yt.SearchConfig, limit int) (*yt.SearchResponse, error) {
+	return SearchAllContext(context.Background(), s, query, config, limit)
 }
 
-// getVideoDetails retrieves detailed information for a list of video IDs
-func (s *searchService) getVideoDetails(service *youtube.Service, videoIDs []string) (map[string]VideoDetails, error) {
-	call := service.Videos.List([]string{"statistics", "contentDetails"}).
-		Id(strings.Join(videoIDs, ","))
+// SearchAllContext is SearchAll with a context
+func SearchAllContext(ctx context.Context, s SearchService, query string, config *yt.SearchConfig, limit int) (*yt.SearchResponse, error) {
+	pageConfig := *config
+	all := &yt.SearchResponse{Query: query}
 
-	response, err := call.Do()
-	if err != nil {
-		return nil, fmt.Errorf("error getting video details: %w", err)
-	}
+	for len(all.Videos) < limit {
+		remaining := int64(limit - len(all.Videos))
+		pageConfig.MaxResults = min(remaining, maxSearchPageSize)
 
-	details := make(map[string]VideoDetails)
-	for _, video := range response.Items {
-		if video.Statistics != nil && video.ContentDetails != nil {
-			viewCount := video.Statistics.ViewCount
-			likeCount := video.Statistics.LikeCount
+		response, err := s.SearchWithConfigContext(ctx, query, &pageConfig)
+		if err != nil {
+			return nil, err
+		}
 
-			details[video.Id] = VideoDetails{
-				Duration:  video.ContentDetails.Duration,
-				ViewCount: viewCount,
-				LikeCount: likeCount,
-			}
+		all.Videos = append(all.Videos, response.Videos...)
+		all.TotalResults = response.TotalResults
+		all.NextPageToken = response.NextPageToken
+
+		if response.NextPageToken == "" || len(response.Videos) == 0 {
+			break
 		}
+		pageConfig.PageToken = response.NextPageToken
 	}
 
-	return details, nil
+	return all, nil
 }
 
 // getBestThumbnail returns the URL of the best available thumbnail
@@ -174,3 +233,45 @@ func getBestThumbnail(thumbnails *youtube.ThumbnailDetails) string {
 
 	return ""
 }
+
+// filterPublished drops the videos outside the configured date range, for
+// backends that cannot filter by date themselves
+func filterPublished(videos []yt.Video, config *yt.SearchConfig) []yt.Video {
+	if config.PublishedAfter.IsZero() && config.PublishedBefore.IsZero() {
+		return videos
+	}
+	kept := videos[:0]
+	for _, v := range videos {
+		if config.PublishedWithin(v) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// warnIgnoredFilters logs the filters that are set but that the named
+// backend has no way to apply
+func warnIgnoredFilters(backend string, config *yt.SearchConfig, supported ...string) {
+	set := map[string]bool{
+		"region":     config.RegionCode != "",
+		"language":   config.RelevanceLanguage != "",
+		"category":   config.VideoCategoryID != "",
+		"event":      config.EventType != "",
+		"captions":   config.VideoCaption != "" && config.VideoCaption != "any",
+		"definition": config.VideoDefinition != "" && config.VideoDefinition != "any",
+	}
+	for _, name := range supported {
+		delete(set, name)
+	}
+
+	var ignored []string
+	for name, isSet := range set {
+		if isSet {
+			ignored = append(ignored, name)
+		}
+	}
+	if len(ignored) > 0 {
+		slices.Sort(ignored)
+		log.Printf("Warning: the %s backend ignores the %s search filters", backend, strings.Join(ignored, ", "))
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+	"google.golang.org/api/youtube/v3"
+)
+
+const (
+	// maxVideoIDsPerCall is the most IDs Videos.List accepts in one request
+	maxVideoIDsPerCall = 50
+	// maxConcurrentDetailCalls limits Videos.List requests in flight
+	maxConcurrentDetailCalls = 4
+)
+
+// videoParts are the parts requested from Videos.List
+var videoParts = []string{"snippet", "statistics", "contentDetails", "status"}
+
+// VideoDetails holds additional video information
+type VideoDetails struct {
+	Duration  string `json:"duration"`
+	ViewCount uint64 `json:"view_count"`
+	LikeCount uint64 `json:"like_count"`
+	// LiveBroadcast is "live" or "upcoming" for streams
+	LiveBroadcast string          `json:"live_broadcast,omitempty"`
+	CategoryID    string          `json:"category_id,omitempty"`
+	Availability  yt.Availability `json:"availability,omitempty"`
+}
+
+// videoDetailsFetcher looks up duration and statistics for video IDs,
+// splitting them into as many Videos.List calls as the API limit requires
+type videoDetailsFetcher struct {
+	client      *yt.Client
+	concurrency int
+}
+
+func newVideoDetailsFetcher(client *yt.Client) *videoDetailsFetcher {
+	return &videoDetailsFetcher{client: client, concurrency: maxConcurrentDetailCalls}
+}
+
+// fetch returns the details of every video it could look up. Duplicate and
+// empty IDs are dropped before batching. When some batches fail the details
+// of the others are still returned along with the error.
+func (f *videoDetailsFetcher) fetch(ctx context.Context, videoIDs []string) (map[string]VideoDetails, error) {
+	batches := chunkIDs(uniqueIDs(videoIDs), maxVideoIDsPerCall)
+	details := make(map[string]VideoDetails, len(videoIDs))
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, max(f.concurrency, 1))
+	)
+	for _, batch := range batches {
+		// Once ctx is done the batches still waiting for a slot are dropped
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+			break
+		}
+		wg.Add(1)
+		go func(batch []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			batchDetails, err := f.fetchBatch(ctx, batch)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			for id, detail := range batchDetails {
+				details[id] = detail
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return details, fmt.Errorf("error getting video details: %w", errors.Join(errs...))
+	}
+	return details, nil
+}
+
+// fetchBatch looks up at most maxVideoIDsPerCall videos with one call
+func (f *videoDetailsFetcher) fetchBatch(ctx context.Context, videoIDs []string) (map[string]VideoDetails, error) {
+	call := f.client.Service().Videos.List(videoParts).
+		Id(strings.Join(videoIDs, ",")).
+		Context(ctx)
+
+	// As with playlist items, the parts are part of the key
+	cacheKey := []any{videoParts, videoIDs}
+	response, err := yt.Fetch(f.client, yt.CallVideosList, cacheKey, func(etag string) (*youtube.VideoListResponse, error) {
+		if etag != "" {
+			call = call.IfNoneMatch(etag)
+		}
+		return yt.Do(ctx, f.client, yt.CallVideosList, call.Do)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details := make(map[string]VideoDetails, len(videoIDs))
+	for _, video := range response.Items {
+		// Availability only needs the video to be listed; the rest is
+		// filled in as far as the parts came back
+		detail := VideoDetails{Availability: videoAvailability(video, f.client.Region())}
+		if video.Statistics != nil && video.ContentDetails != nil {
+			detail.Duration = video.ContentDetails.Duration
+			detail.ViewCount = video.Statistics.ViewCount
+			detail.LikeCount = video.Statistics.LikeCount
+			if video.Snippet != nil {
+				detail.LiveBroadcast = liveBroadcast(video.Snippet.LiveBroadcastContent)
+				detail.CategoryID = video.Snippet.CategoryId
+			}
+		}
+		details[video.Id] = detail
+	}
+	// Deleted videos, and private ones to anyone but their owner, are
+	// left out of the response
+	for _, id := range videoIDs {
+		if _, found := details[id]; !found {
+			details[id] = VideoDetails{Availability: yt.AvailabilityDeleted}
+		}
+	}
+	return details, nil
+}
+
+// applyVideoDetails copies fetched details onto the matching results
+func applyVideoDetails(results []yt.SearchResult, details map[string]VideoDetails) {
+	for i, result := range results {
+		if detail, exists := details[result.ID]; exists {
+			// A playlist knows better whether a missing video is private
+			if result.Availability == yt.Available {
+				results[i].Availability = detail.Availability
+			}
+			if detail.Duration == "" {
+				continue
+			}
+			results[i].Duration = detail.Duration
+			results[i].Length, _ = yt.ParseISODuration(detail.Duration)
+			results[i].ViewCount = detail.ViewCount
+			results[i].LikeCount = detail.LikeCount
+			if detail.LiveBroadcast != "" {
+				results[i].LiveBroadcast = detail.LiveBroadcast
+			}
+			results[i].CategoryID = detail.CategoryID
+		}
+	}
+}
+
+// videoAvailability tells whether a listed video can be played in region
+func videoAvailability(video *youtube.Video, region string) yt.Availability {
+	if video.Status != nil {
+		if video.Status.PrivacyStatus == "private" {
+			return yt.AvailabilityPrivate
+		}
+		switch video.Status.UploadStatus {
+		case "deleted", "failed", "rejected":
+			return yt.AvailabilityDeleted
+		}
+	}
+	if details := video.ContentDetails; details != nil {
+		if r := details.RegionRestriction; r != nil {
+			if availability := yt.RegionAvailability(region, r.Allowed, r.Blocked); availability != yt.Available {
+				return availability
+			}
+		}
+		if details.ContentRating != nil && details.ContentRating.YtRating == "ytAgeRestricted" {
+			return yt.AvailabilityAgeRestricted
+		}
+	}
+	return yt.Available
+}
+
+// liveBroadcast normalises the API's liveBroadcastContent, which is "none"
+// for videos that are not streams
+func liveBroadcast(content string) string {
+	if content == "none" {
+		return ""
+	}
+	return content
+}
+
+// uniqueIDs drops empty and repeated IDs, keeping the first occurrence
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// chunkIDs splits ids into slices of at most size elements
+func chunkIDs(ids []string, size int) [][]string {
+	var chunks [][]string
+	for len(ids) > size {
+		chunks = append(chunks, ids[:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
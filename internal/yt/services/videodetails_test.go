@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// fakeVideo is a full Videos.List item for id
+func fakeVideo(id string) map[string]any {
+	return map[string]any{
+		"id":             id,
+		"snippet":        map[string]any{"title": id, "liveBroadcastContent": "none", "categoryId": "10"},
+		"contentDetails": map[string]any{"duration": "PT3M"},
+		"statistics":     map[string]any{"viewCount": "7"},
+		"status":         map[string]any{"privacyStatus": "public", "uploadStatus": "processed"},
+	}
+}
+
+// handleVideos serves Videos.List with item for every listed ID it returns
+// an item for
+func handleVideos(api *fakeDataAPI, item func(id string) map[string]any) {
+	api.handle("videos", func(w http.ResponseWriter, r *http.Request) {
+		items := []map[string]any{}
+		for _, id := range strings.Split(r.URL.Query().Get("id"), ",") {
+			if v := item(id); v != nil {
+				items = append(items, v)
+			}
+		}
+		writeAPIJSON(w, map[string]any{"items": items})
+	})
+}
+
+func TestFetchVideoDetailsAvailability(t *testing.T) {
+	api := newFakeAPI(t)
+	handleVideos(api, func(id string) map[string]any {
+		v := fakeVideo(id)
+		switch id {
+		case "gone":
+			return nil
+		case "nostats":
+			delete(v, "statistics")
+		case "nodetails":
+			delete(v, "contentDetails")
+		case "private":
+			v["status"] = map[string]any{"privacyStatus": "private"}
+			delete(v, "statistics")
+		}
+		return v
+	})
+
+	fetcher := newVideoDetailsFetcher(api.client(t, fakeAPIToken))
+	details, err := fetcher.fetch(context.Background(), []string{"ok", "gone", "nostats", "nodetails", "private"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]yt.Availability{
+		"ok":        yt.Available,
+		"gone":      yt.AvailabilityDeleted,
+		"nostats":   yt.Available,
+		"nodetails": yt.Available,
+		"private":   yt.AvailabilityPrivate,
+	}
+	for id, availability := range want {
+		if got := details[id].Availability; got != availability {
+			t.Errorf("%s availability = %v, want %v", id, got, availability)
+		}
+	}
+	if d := details["ok"]; d.Duration != "PT3M" || d.ViewCount != 7 || d.CategoryID != "10" {
+		t.Errorf("ok details = %+v", d)
+	}
+}
+
+func TestFetchVideoDetailsBatches(t *testing.T) {
+	api := newFakeAPI(t)
+	handleVideos(api, fakeVideo)
+
+	var ids []string
+	for i := range 120 {
+		ids = append(ids, fmt.Sprintf("video%06d", i))
+	}
+	// Repeats and blanks are looked up once or not at all
+	ids = append(ids, "", ids[0], ids[119])
+
+	details, err := newVideoDetailsFetcher(api.client(t, fakeAPIToken)).fetch(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details) != 120 {
+		t.Errorf("got details for %d videos, want 120", len(details))
+	}
+
+	var sizes []int
+	seen := map[string]bool{}
+	for _, q := range api.queries("/videos") {
+		batch := strings.Split(q.Get("id"), ",")
+		sizes = append(sizes, len(batch))
+		for _, id := range batch {
+			if seen[id] {
+				t.Errorf("%s looked up twice", id)
+			}
+			seen[id] = true
+		}
+	}
+	slices.Sort(sizes)
+	if !slices.Equal(sizes, []int{20, 50, 50}) {
+		t.Errorf("batch sizes = %v, want 50, 50 and 20", sizes)
+	}
+}
+
+func TestFetchVideoDetailsPartialFailure(t *testing.T) {
+	api := newFakeAPI(t)
+	api.handle("videos", func(w http.ResponseWriter, r *http.Request) {
+		ids := strings.Split(r.URL.Query().Get("id"), ",")
+		if slices.Contains(ids, "video000060") {
+			writeAPIError(w, http.StatusBadRequest, "invalidParameter")
+			return
+		}
+		items := []map[string]any{}
+		for _, id := range ids {
+			items = append(items, fakeVideo(id))
+		}
+		writeAPIJSON(w, map[string]any{"items": items})
+	})
+
+	var ids []string
+	for i := range 120 {
+		ids = append(ids, fmt.Sprintf("video%06d", i))
+	}
+	details, err := newVideoDetailsFetcher(api.client(t, fakeAPIToken)).fetch(context.Background(), ids)
+	if err == nil {
+		t.Fatal("fetch succeeded, want the failed batch reported")
+	}
+	// The second batch failed; the others still come back
+	if len(details) != 70 {
+		t.Errorf("got details for %d videos, want 70", len(details))
+	}
+	for _, id := range []string{"video000000", "video000049", "video000100", "video000119"} {
+		if details[id].Duration != "PT3M" {
+			t.Errorf("%s details = %+v", id, details[id])
+		}
+	}
+	if _, ok := details["video000060"]; ok {
+		t.Error("a video of the failed batch has details")
+	}
+}
+
+func TestFetchVideoDetailsCancelled(t *testing.T) {
+	api := newFakeAPI(t)
+	handleVideos(api, fakeVideo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	details, err := newVideoDetailsFetcher(api.client(t, fakeAPIToken)).fetch(ctx, []string{"a", "b"})
+	if !errors.Is(err, context.Canceled) || len(details) != 0 {
+		t.Errorf("fetch = %v, %v; want context.Canceled", details, err)
+	}
+	if n := len(api.queries("/videos")); n != 0 {
+		t.Errorf("made %d calls after cancelling", n)
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want []string
+	}{
+		{in: nil, want: []string{}},
+		{in: []string{"", ""}, want: []string{}},
+		{in: []string{"a", "b", "a", "", "c", "b"}, want: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		if got := uniqueIDs(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("uniqueIDs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestChunkIDs(t *testing.T) {
+	ids := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = strconv.Itoa(i)
+		}
+		return out
+	}
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{n: 0, want: nil},
+		{n: 1, want: []int{1}},
+		{n: 50, want: []int{50}},
+		{n: 51, want: []int{50, 1}},
+		{n: 100, want: []int{50, 50}},
+		{n: 120, want: []int{50, 50, 20}},
+	}
+	for _, tt := range tests {
+		in := ids(tt.n)
+		chunks := chunkIDs(in, maxVideoIDsPerCall)
+		var sizes []int
+		var joined []string
+		for _, chunk := range chunks {
+			sizes = append(sizes, len(chunk))
+			joined = append(joined, chunk...)
+		}
+		if !slices.Equal(sizes, tt.want) || !slices.Equal(joined, in[:len(joined)]) || len(joined) != tt.n {
+			t.Errorf("chunkIDs of %d IDs gave sizes %v, want %v in order", tt.n, sizes, tt.want)
+		}
+	}
+}
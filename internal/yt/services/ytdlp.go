@@ -0,0 +1,510 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+const (
+	defaultYtDlpPath = "yt-dlp"
+
+	// maxScannerLine bounds a single --dump-json line; full video JSON with
+	// every format listed easily exceeds bufio's 64k default
+	maxScannerLine = 16 * 1024 * 1024
+)
+
+// ytdlpEntry holds the fields gplay uses from yt-dlp's --dump-json output.
+// Flat playlist entries only carry a subset of them.
+type ytdlpEntry struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Channel     string  `json:"channel"`
+	ChannelID   string  `json:"channel_id"`
+	Uploader    string  `json:"uploader"`
+	UploadDate  string  `json:"upload_date"` // YYYYMMDD
+	Timestamp   int64   `json:"timestamp"`
+	Duration    float64 `json:"duration"`
+	ViewCount   uint64  `json:"view_count"`
+	LikeCount   uint64  `json:"like_count"`
+	Thumbnail   string  `json:"thumbnail"`
+	Thumbnails  []struct {
+		URL string `json:"url"`
+	} `json:"thumbnails"`
+	LiveStatus string   `json:"live_status"` // is_live, is_upcoming, was_live...
+	Categories []string `json:"categories"`
+	// Availability is public, unlisted, private, needs_auth...
+	Availability string `json:"availability"`
+	AgeLimit     int    `json:"age_limit"`
+
+	// Channel and playlist fields
+	URL                  string `json:"url"`
+	UploaderID           string `json:"uploader_id"` // @handle
+	ChannelFollowerCount uint64 `json:"channel_follower_count"`
+	PlaylistCount        int64  `json:"playlist_count"`
+}
+
+type ytdlpSearchService struct {
+	binary string
+	config *yt.SearchConfig
+}
+
+// NewYtDlpSearchService creates a search service that shells out to yt-dlp
+// instead of the YouTube Data API. An empty binary uses yt-dlp from PATH.
+func NewYtDlpSearchService(binary string, maxResults int64) SearchService {
+	if binary == "" {
+		binary = defaultYtDlpPath
+	}
+	return &ytdlpSearchService{
+		binary: binary,
+		config: DefaultSearchConfig(maxResults),
+	}
+}
+
+// Search performs a yt-dlp search with default configuration
+func (s *ytdlpSearchService) Search(query string) (*yt.SearchResponse, error) {
+	return s.SearchContext(context.Background(), query)
+}
+
+// SearchWithConfig performs a yt-dlp search with custom configuration
+func (s *ytdlpSearchService) SearchWithConfig(query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(context.Background(), query, config)
+}
+
+// SearchContext performs a yt-dlp search with default configuration
+func (s *ytdlpSearchService) SearchContext(ctx context.Context, query string) (*yt.SearchResponse, error) {
+	return s.SearchWithConfigContext(ctx, query, s.config)
+}
+
+// SearchWithConfigContext performs a yt-dlp search, killing yt-dlp when ctx
+// is done. Only MaxResults, Order=date and PageToken are honoured; page
+// tokens are result offsets.
+func (s *ytdlpSearchService) SearchWithConfigContext(ctx context.Context, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	if config.ChannelID != "" {
+		channel := &yt.Channel{ID: config.ChannelID}
+		return (&ytdlpChannelService{binary: s.binary}).SearchChannel(ctx, channel, query, config)
+	}
+
+	offset, err := parseOffsetToken(config.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "ytsearch"
+	if config.Order == "date" {
+		prefix = "ytsearchdate"
+	}
+
+	// yt-dlp has no offset for searches, so ask for everything up to the end
+	// of the page and skip what earlier pages already returned
+	end := offset + int(config.MaxResults)
+	entries, err := runYtDlpJSON(ctx, s.binary,
+		"--dump-json",
+		"--skip-download",
+		"--playlist-start", strconv.Itoa(offset+1),
+		fmt.Sprintf("%s%d:%s", prefix, end, query))
+	if err != nil {
+		return nil, fmt.Errorf("error executing search: %w", err)
+	}
+
+	warnIgnoredFilters(BackendYtDlp, config)
+
+	results := make([]yt.SearchResult, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, entry.toVideo())
+	}
+
+	response := &yt.SearchResponse{
+		Videos:       filterPublished(results, config),
+		TotalResults: int64(offset + len(results)),
+		Query:        query,
+	}
+	// A full page means there may be more
+	if len(results) == int(config.MaxResults) {
+		response.NextPageToken = strconv.Itoa(end)
+	}
+	return response, nil
+}
+
+type ytdlpPlaylistService struct {
+	binary string
+}
+
+// NewYtDlpPlaylistService creates a playlist service that shells out to
+// yt-dlp. An empty binary uses yt-dlp from PATH.
+func NewYtDlpPlaylistService(binary string) PlaylistService {
+	if binary == "" {
+		binary = defaultYtDlpPath
+	}
+	return &ytdlpPlaylistService{binary: binary}
+}
+
+// GetPlaylistItems retrieves all videos in a playlist. maxResults only
+// matters for the API backend's page size and is ignored here.
+func (p *ytdlpPlaylistService) GetPlaylistItems(playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	return p.GetPlaylistItemsContext(context.Background(), playlistID, maxResults)
+}
+
+// GetPlaylistItemsContext is GetPlaylistItems with a context
+func (p *ytdlpPlaylistService) GetPlaylistItemsContext(ctx context.Context, playlistID string, maxResults int64) ([]yt.SearchResult, error) {
+	// The ID ends up in a URL, so nothing but an ID may get through
+	id, err := yt.ParsePlaylistID(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := runYtDlpJSON(ctx, p.binary,
+		"--flat-playlist",
+		"--dump-json",
+		yt.PlaylistURL(id))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching playlist items: %w", err)
+	}
+
+	results := make([]yt.SearchResult, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, entry.toVideo())
+	}
+	return results, nil
+}
+
+type ytdlpMixService struct {
+	binary string
+}
+
+// NewYtDlpMixService creates a mix service that shells out to yt-dlp. An
+// empty binary uses yt-dlp from PATH.
+func NewYtDlpMixService(binary string) MixService {
+	if binary == "" {
+		binary = defaultYtDlpPath
+	}
+	return &ytdlpMixService{binary: binary}
+}
+
+// Mix lists the first limit tracks of the mix generated for videoID. Mixes
+// only resolve when opened from a video, hence the watch URL.
+func (m *ytdlpMixService) Mix(ctx context.Context, videoID string, limit int) ([]yt.Video, error) {
+	entries, err := runYtDlpJSON(ctx, m.binary,
+		"--flat-playlist",
+		"--dump-json",
+		"--playlist-end", strconv.Itoa(limit),
+		fmt.Sprintf("https://www.youtube.com/watch?v=%s&list=%s%s", url.QueryEscape(videoID), mixPrefix, url.QueryEscape(videoID)))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching mix: %w", err)
+	}
+
+	videos := make([]yt.Video, 0, len(entries))
+	for _, entry := range entries {
+		videos = append(videos, entry.toVideo())
+	}
+	return videos, nil
+}
+
+type ytdlpChannelService struct {
+	binary string
+}
+
+// NewYtDlpChannelService creates a channel service that shells out to
+// yt-dlp. An empty binary uses yt-dlp from PATH.
+func NewYtDlpChannelService(binary string) ChannelService {
+	if binary == "" {
+		binary = defaultYtDlpPath
+	}
+	return &ytdlpChannelService{binary: binary}
+}
+
+// Channel looks up a channel by ID, @handle or channel URL
+func (c *ytdlpChannelService) Channel(ctx context.Context, ref string) (*yt.Channel, error) {
+	id, handle, err := parseChannelRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		id = handle
+	}
+
+	// The videos tab carries the channel metadata; one entry is enough
+	entries, err := runYtDlpJSON(ctx, c.binary,
+		"--flat-playlist",
+		"--dump-single-json",
+		"--playlist-items", "1",
+		yt.ChannelURL(id)+"/videos")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching channel: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("channel %s: %w", ref, yt.ErrNotFound)
+	}
+
+	e := entries[0]
+	channel := &yt.Channel{
+		ID:              e.ChannelID,
+		Title:           e.Channel,
+		Handle:          e.UploaderID,
+		Description:     e.Description,
+		SubscriberCount: e.ChannelFollowerCount,
+		URL:             yt.ChannelURL(e.ChannelID),
+	}
+	if channel.ID == "" {
+		channel.ID = e.ID
+		channel.URL = yt.ChannelURL(e.ID)
+	}
+	if channel.Title == "" {
+		channel.Title = strings.TrimSuffix(e.Title, " - Videos")
+	}
+	if len(e.Thumbnails) > 0 {
+		channel.ThumbnailURL = e.Thumbnails[len(e.Thumbnails)-1].URL
+	}
+	return channel, nil
+}
+
+// Uploads lists the newest entries of the channel's videos tab
+func (c *ytdlpChannelService) Uploads(ctx context.Context, channel *yt.Channel, limit int) ([]yt.Video, error) {
+	args := []string{"--flat-playlist", "--dump-json"}
+	if limit > 0 {
+		args = append(args, "--playlist-end", strconv.Itoa(limit))
+	}
+	entries, err := runYtDlpJSON(ctx, c.binary, append(args, yt.ChannelURL(channel.ID)+"/videos")...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching uploads: %w", err)
+	}
+	return channelVideos(channel, entries), nil
+}
+
+// Playlists lists the entries of the channel's playlists tab
+func (c *ytdlpChannelService) Playlists(ctx context.Context, channel *yt.Channel) ([]yt.Playlist, error) {
+	entries, err := runYtDlpJSON(ctx, c.binary,
+		"--flat-playlist",
+		"--dump-json",
+		yt.ChannelURL(channel.ID)+"/playlists")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching playlists: %w", err)
+	}
+
+	playlists := make([]yt.Playlist, 0, len(entries))
+	for _, e := range entries {
+		playlist := yt.Playlist{
+			ID:           e.ID,
+			Title:        e.Title,
+			Description:  e.Description,
+			ChannelTitle: channel.Title,
+			ChannelID:    channel.ID,
+			ItemCount:    e.PlaylistCount,
+			URL:          yt.PlaylistURL(e.ID),
+		}
+		if len(e.Thumbnails) > 0 {
+			playlist.ThumbnailURL = e.Thumbnails[len(e.Thumbnails)-1].URL
+		}
+		playlists = append(playlists, playlist)
+	}
+	return playlists, nil
+}
+
+// SearchChannel runs the channel's own search. Page tokens are result offsets like
+// the yt-dlp search service.
+func (c *ytdlpChannelService) SearchChannel(ctx context.Context, channel *yt.Channel, query string, config *yt.SearchConfig) (*yt.SearchResponse, error) {
+	offset, err := parseOffsetToken(config.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	end := offset + int(config.MaxResults)
+	entries, err := runYtDlpJSON(ctx, c.binary,
+		"--flat-playlist",
+		"--dump-json",
+		"--playlist-start", strconv.Itoa(offset+1),
+		"--playlist-end", strconv.Itoa(end),
+		yt.ChannelURL(channel.ID)+"/search?query="+url.QueryEscape(query))
+	if err != nil {
+		return nil, fmt.Errorf("error executing search: %w", err)
+	}
+
+	warnIgnoredFilters(BackendYtDlp, config)
+
+	results := channelVideos(channel, entries)
+	response := &yt.SearchResponse{
+		Videos:       filterPublished(results, config),
+		TotalResults: int64(offset + len(results)),
+		Query:        query,
+	}
+	if len(results) == int(config.MaxResults) {
+		response.NextPageToken = strconv.Itoa(end)
+	}
+	return response, nil
+}
+
+// channelVideos converts flat tab entries, which often lack the channel
+// fields, filling those in from the channel
+func channelVideos(channel *yt.Channel, entries []ytdlpEntry) []yt.Video {
+	videos := make([]yt.Video, 0, len(entries))
+	for _, e := range entries {
+		video := e.toVideo()
+		if video.ChannelID == "" {
+			video.ChannelID = channel.ID
+		}
+		if video.ChannelTitle == "" {
+			video.ChannelTitle = channel.Title
+		}
+		videos = append(videos, video)
+	}
+	return videos
+}
+
+// toVideo maps a yt-dlp entry to the same shape the API backend returns
+func (e ytdlpEntry) toVideo() yt.Video {
+	channel := e.Channel
+	if channel == "" {
+		channel = e.Uploader
+	}
+
+	var publishedAt time.Time
+	if e.Timestamp > 0 {
+		publishedAt = time.Unix(e.Timestamp, 0).UTC()
+	} else if e.UploadDate != "" {
+		publishedAt, _ = time.Parse("20060102", e.UploadDate)
+	}
+
+	thumbnail := e.Thumbnail
+	if thumbnail == "" && len(e.Thumbnails) > 0 {
+		// yt-dlp orders thumbnails from worst to best
+		thumbnail = e.Thumbnails[len(e.Thumbnails)-1].URL
+	}
+
+	length := secondsDuration(e.Duration)
+
+	var live string
+	switch e.LiveStatus {
+	case "is_live":
+		live = "live"
+	case "is_upcoming":
+		live = "upcoming"
+	}
+
+	var category string
+	if len(e.Categories) > 0 {
+		category = yt.CategoryID(e.Categories[0])
+	}
+
+	// Flat playlist entries only have the placeholder title to go by
+	availability := yt.TitleAvailability(e.Title)
+	switch {
+	case e.Availability == "private":
+		availability = yt.AvailabilityPrivate
+	case e.AgeLimit >= 18 || e.Availability == "needs_auth":
+		availability = yt.AvailabilityAgeRestricted
+	}
+
+	return yt.Video{
+		ID:            e.ID,
+		Title:         e.Title,
+		Description:   e.Description,
+		ChannelTitle:  channel,
+		ChannelID:     e.ChannelID,
+		PublishedAt:   publishedAt,
+		Duration:      yt.FormatISODuration(length),
+		Length:        length,
+		ViewCount:     e.ViewCount,
+		LikeCount:     e.LikeCount,
+		ThumbnailURL:  thumbnail,
+		URL:           fmt.Sprintf("https://www.youtube.com/watch?v=%s", e.ID),
+		LiveBroadcast: live,
+		CategoryID:    category,
+		// Flat search and channel entries link Shorts by their own URL
+		Short:        strings.Contains(e.URL, "/shorts/"),
+		Availability: availability,
+	}
+}
+
+// secondsDuration converts the seconds reported by yt-dlp, Invidious and
+// Piped; negative values, used for live streams, become zero
+func secondsDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// parseOffsetToken decodes the offset based page tokens used by backends
+// without native pagination
+func parseOffsetToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(token)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid page token %q", token)
+	}
+	return offset, nil
+}
+
+// runYtDlpJSON runs yt-dlp and decodes one JSON object per output line
+func runYtDlpJSON(ctx context.Context, binary string, args ...string) ([]ytdlpEntry, error) {
+	args = append([]string{"--no-warnings", "--ignore-errors"}, args...)
+	cmd := exec.CommandContext(ctx, binary, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil && len(out) == 0 {
+		// --ignore-errors still exits non-zero when single entries fail, so
+		// only treat it as fatal when nothing was produced
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			line := lastLine(stderr.Bytes())
+			if kind := ytdlpErrorKind(line); kind != nil {
+				return nil, fmt.Errorf("yt-dlp failed: %w (%s)", kind, line)
+			}
+			return nil, fmt.Errorf("yt-dlp failed: %s", line)
+		}
+		return nil, fmt.Errorf("error running yt-dlp: %w", err)
+	}
+
+	var entries []ytdlpEntry
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScannerLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry ytdlpEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("error parsing yt-dlp output: %w", err)
+		}
+		if entry.ID != "" {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading yt-dlp output: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ytdlpErrorKind maps yt-dlp error messages onto the typed errors of the
+// Data API so callers can handle both backends alike
+func ytdlpErrorKind(message string) error {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "playlist") && strings.Contains(lower, "private"):
+		return yt.ErrPrivatePlaylist
+	case strings.Contains(lower, "does not exist"), strings.Contains(lower, "not found"),
+		strings.Contains(lower, "http error 404"):
+		return yt.ErrNotFound
+	}
+	return nil
+}
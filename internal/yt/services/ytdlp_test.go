@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alanpramil7/gplay/internal/yt"
+)
+
+// fakeYtDlp puts a yt-dlp on PATH that prints stdout and stderr and exits
+// with code. The returned function reports the arguments of the last run.
+func fakeYtDlp(t *testing.T, stdout, stderr string, code int) func() []string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake yt-dlp is a shell script")
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"stdout": stdout, "stderr": stderr} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := "#!/bin/sh\n" +
+		"printf '%s\\n' \"$@\" > '" + filepath.Join(dir, "args") + "'\n" +
+		"cat '" + filepath.Join(dir, "stdout") + "'\n" +
+		"cat '" + filepath.Join(dir, "stderr") + "' >&2\n" +
+		"exit " + strconv.Itoa(code) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, defaultYtDlpPath), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() []string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, "args"))
+		if err != nil {
+			t.Fatalf("yt-dlp was not run: %v", err)
+		}
+		return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+}
+
+// hasArgs reports whether want appears in args as a consecutive run
+func hasArgs(args []string, want ...string) bool {
+	for i := range args {
+		if i+len(want) <= len(args) && slices.Equal(args[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestYtDlpSearchPaging(t *testing.T) {
+	tests := []struct {
+		name      string
+		order     string
+		pageToken string
+		output    string
+		wantArgs  [][]string
+		wantIDs   []string
+		wantTotal int64
+		wantNext  string
+	}{
+		{
+			name:      "first page",
+			output:    `{"id":"a1"}` + "\n" + `{"id":"b2"}` + "\n",
+			wantArgs:  [][]string{{"--playlist-start", "1"}, {"ytsearch2:lofi"}},
+			wantIDs:   []string{"a1", "b2"},
+			wantTotal: 2,
+			wantNext:  "2",
+		},
+		{
+			name:      "last page",
+			pageToken: "2",
+			output:    `{"id":"c3"}` + "\n",
+			wantArgs:  [][]string{{"--playlist-start", "3"}, {"ytsearch4:lofi"}},
+			wantIDs:   []string{"c3"},
+			wantTotal: 3,
+		},
+		{
+			name:      "newest first",
+			order:     "date",
+			output:    `{"id":"a1"}` + "\n" + `{"id":"b2"}` + "\n",
+			wantArgs:  [][]string{{"ytsearchdate2:lofi"}},
+			wantIDs:   []string{"a1", "b2"},
+			wantTotal: 2,
+			wantNext:  "2",
+		},
+		{
+			name:      "entries without an ID are skipped",
+			output:    `{"id":"a1"}` + "\n\n" + `{"title":"no id"}` + "\n",
+			wantArgs:  [][]string{{"ytsearch2:lofi"}},
+			wantIDs:   []string{"a1"},
+			wantTotal: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := fakeYtDlp(t, tt.output, "", 0)
+			service := NewYtDlpSearchService("", 2)
+
+			response, err := service.SearchWithConfigContext(context.Background(), "lofi",
+				&yt.SearchConfig{MaxResults: 2, Order: tt.order, PageToken: tt.pageToken})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.wantArgs {
+				if !hasArgs(args(), want...) {
+					t.Errorf("args %q do not contain %q", args(), want)
+				}
+			}
+			var ids []string
+			for _, v := range response.Videos {
+				ids = append(ids, v.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("IDs = %q, want %q", ids, tt.wantIDs)
+			}
+			if response.TotalResults != tt.wantTotal {
+				t.Errorf("TotalResults = %d, want %d", response.TotalResults, tt.wantTotal)
+			}
+			if response.NextPageToken != tt.wantNext {
+				t.Errorf("NextPageToken = %q, want %q", response.NextPageToken, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestParseOffsetToken(t *testing.T) {
+	tests := []struct {
+		token   string
+		want    int
+		wantErr bool
+	}{
+		{token: "", want: 0},
+		{token: "0", want: 0},
+		{token: "25", want: 25},
+		{token: "-1", wantErr: true},
+		{token: "CAUQAA", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseOffsetToken(tt.token)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOffsetToken(%q) error = %v, want error %v", tt.token, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseOffsetToken(%q) = %d, want %d", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestRunYtDlpJSONErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdout   string
+		stderr   string
+		code     int
+		wantKind error
+		wantErr  string
+		wantIDs  []string
+	}{
+		{
+			name:     "missing playlist",
+			stderr:   "ERROR: [youtube:tab] PLx: The playlist does not exist.\n",
+			code:     1,
+			wantKind: yt.ErrNotFound,
+		},
+		{
+			name:     "private playlist",
+			stderr:   "WARNING: retrying\nERROR: [youtube:tab] PLx: This playlist is private\n",
+			code:     1,
+			wantKind: yt.ErrPrivatePlaylist,
+		},
+		{
+			name:    "other failure keeps the last stderr line",
+			stderr:  "ERROR: Unable to download webpage: timed out\n",
+			code:    1,
+			wantErr: "yt-dlp failed: ERROR: Unable to download webpage: timed out",
+		},
+		{
+			name:    "failed entries are ignored when others were printed",
+			stdout:  `{"id":"a1"}` + "\n",
+			stderr:  "ERROR: [youtube] b2: Video unavailable\n",
+			code:    1,
+			wantIDs: []string{"a1"},
+		},
+		{
+			name:    "invalid output",
+			stdout:  "not json\n",
+			wantErr: "error parsing yt-dlp output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeYtDlp(t, tt.stdout, tt.stderr, tt.code)
+
+			entries, err := runYtDlpJSON(context.Background(), defaultYtDlpPath)
+			switch {
+			case tt.wantKind != nil:
+				if !errors.Is(err, tt.wantKind) {
+					t.Fatalf("error = %v, want %v", err, tt.wantKind)
+				}
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+
+			var ids []string
+			for _, e := range entries {
+				ids = append(ids, e.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("IDs = %q, want %q", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestRunYtDlpJSONMissingBinary(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := runYtDlpJSON(context.Background(), defaultYtDlpPath)
+	if err == nil || !strings.Contains(err.Error(), "error running yt-dlp") {
+		t.Fatalf("error = %v, want a run error", err)
+	}
+}
+
+func TestRunYtDlpJSONCancelled(t *testing.T) {
+	fakeYtDlp(t, `{"id":"a1"}`+"\n", "", 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := runYtDlpJSON(ctx, defaultYtDlpPath); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestYtDlpEntryToVideo(t *testing.T) {
+	thumbnails := []struct {
+		URL string `json:"url"`
+	}{{URL: "https://i.ytimg.com/small.jpg"}, {URL: "https://i.ytimg.com/large.jpg"}}
+
+	tests := []struct {
+		name  string
+		entry ytdlpEntry
+		want  yt.Video
+	}{
+		{
+			name: "full entry",
+			entry: ytdlpEntry{
+				ID:         "dQw4w9WgXcQ",
+				Title:      "Never Gonna Give You Up",
+				Channel:    "Rick Astley",
+				Uploader:   "RickAstleyVEVO",
+				ChannelID:  "UCuAXFkgsw1L7xaCfnd5JJOw",
+				Timestamp:  1256453400,
+				UploadDate: "20091025",
+				Duration:   213,
+				ViewCount:  1500000000,
+				LikeCount:  18000000,
+				Thumbnail:  "https://i.ytimg.com/maxres.jpg",
+				Thumbnails: thumbnails,
+				Categories: []string{"Music"},
+			},
+			want: yt.Video{
+				ID:           "dQw4w9WgXcQ",
+				Title:        "Never Gonna Give You Up",
+				ChannelTitle: "Rick Astley",
+				ChannelID:    "UCuAXFkgsw1L7xaCfnd5JJOw",
+				PublishedAt:  time.Unix(1256453400, 0).UTC(),
+				Duration:     "PT3M33S",
+				Length:       213 * time.Second,
+				ViewCount:    1500000000,
+				LikeCount:    18000000,
+				ThumbnailURL: "https://i.ytimg.com/maxres.jpg",
+				URL:          "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+				CategoryID:   "10",
+			},
+		},
+		{
+			name: "flat entry falls back to uploader, upload date and thumbnails",
+			entry: ytdlpEntry{
+				ID:         "abc",
+				Title:      "Short clip",
+				Uploader:   "Someone",
+				UploadDate: "20240131",
+				Thumbnails: thumbnails,
+				URL:        "https://www.youtube.com/shorts/abc",
+			},
+			want: yt.Video{
+				ID:           "abc",
+				Title:        "Short clip",
+				ChannelTitle: "Someone",
+				PublishedAt:  time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+				Duration:     "P0D",
+				ThumbnailURL: "https://i.ytimg.com/large.jpg",
+				URL:          "https://www.youtube.com/watch?v=abc",
+				Short:        true,
+			},
+		},
+		{
+			name:  "live stream",
+			entry: ytdlpEntry{ID: "live", Duration: -1, LiveStatus: "is_live"},
+			want: yt.Video{
+				ID:            "live",
+				Duration:      "P0D",
+				URL:           "https://www.youtube.com/watch?v=live",
+				LiveBroadcast: "live",
+			},
+		},
+		{
+			name:  "upcoming stream",
+			entry: ytdlpEntry{ID: "soon", LiveStatus: "is_upcoming"},
+			want: yt.Video{
+				ID:            "soon",
+				Duration:      "P0D",
+				URL:           "https://www.youtube.com/watch?v=soon",
+				LiveBroadcast: "upcoming",
+			},
+		},
+		{
+			name:  "deleted placeholder",
+			entry: ytdlpEntry{ID: "gone", Title: "[Deleted video]"},
+			want: yt.Video{
+				ID:           "gone",
+				Title:        "[Deleted video]",
+				Duration:     "P0D",
+				URL:          "https://www.youtube.com/watch?v=gone",
+				Availability: yt.AvailabilityDeleted,
+			},
+		},
+		{
+			name:  "private",
+			entry: ytdlpEntry{ID: "priv", Availability: "private"},
+			want: yt.Video{
+				ID:           "priv",
+				Duration:     "P0D",
+				URL:          "https://www.youtube.com/watch?v=priv",
+				Availability: yt.AvailabilityPrivate,
+			},
+		},
+		{
+			name:  "age restricted",
+			entry: ytdlpEntry{ID: "adult", AgeLimit: 18},
+			want: yt.Video{
+				ID:           "adult",
+				Duration:     "P0D",
+				URL:          "https://www.youtube.com/watch?v=adult",
+				Availability: yt.AvailabilityAgeRestricted,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.entry.toVideo()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toVideo() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYtDlpPlaylistURL(t *testing.T) {
+	args := fakeYtDlp(t, `{"id":"a1"}`+"\n", "", 0)
+	service := NewYtDlpPlaylistService("")
+
+	for _, ref := range []string{"PLabcdefghijkl", "https://www.youtube.com/playlist?list=PLabcdefghijkl&si=x"} {
+		if _, err := service.GetPlaylistItemsContext(context.Background(), ref, 50); err != nil {
+			t.Fatalf("GetPlaylistItemsContext(%q): %v", ref, err)
+		}
+		if got := args(); !hasArgs(got, "https://www.youtube.com/playlist?list=PLabcdefghijkl") {
+			t.Errorf("GetPlaylistItemsContext(%q) ran yt-dlp with %q", ref, got)
+		}
+	}
+
+	if _, err := service.GetPlaylistItemsContext(context.Background(), "PLabc&list=PLother", 50); err == nil {
+		t.Error("an ID with query characters was accepted")
+	}
+}
@@ -1,6 +1,9 @@
 package yt
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Video represents a single video from YouTube
 type Video struct {
@@ -10,11 +13,78 @@ type Video struct {
 	ChannelTitle string    `json:"channel_title"`
 	ChannelID    string    `json:"channel_id"`
 	PublishedAt  time.Time `json:"published_at"`
-	Duration     string    `json:"duration"`
+	Duration     string    `json:"duration"` // ISO 8601, e.g. PT4M13S
 	ViewCount    uint64    `json:"view_count"`
 	LikeCount    uint64    `json:"like_count"`
 	ThumbnailURL string    `json:"thumbnail_url"`
 	URL          string    `json:"url"`
+	// LiveBroadcast is "live" or "upcoming" for streams, empty otherwise
+	LiveBroadcast string `json:"live_broadcast,omitempty"`
+	CategoryID    string `json:"category_id,omitempty"`
+	// Short is set when the backend marks the video as a YouTube Short
+	Short bool `json:"short,omitempty"`
+	// Availability is set when the video cannot be played, see
+	// availability.go
+	Availability Availability `json:"availability,omitempty"`
+	// Length is Duration parsed, zero for live streams
+	Length time.Duration `json:"-"`
+}
+
+// IsLive reports whether v is a stream that is on air or yet to start
+func (v Video) IsLive() bool {
+	return v.LiveBroadcast != ""
+}
+
+// Playable reports whether nothing is known to stop v from playing
+func (v Video) Playable() bool {
+	return v.Availability == Available
+}
+
+// UnmarshalJSON fills Length from the stored duration string
+func (v *Video) UnmarshalJSON(data []byte) error {
+	type plain Video
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+	v.Length, _ = ParseISODuration(v.Duration)
+	return nil
+}
+
+// Channel represents a YouTube channel
+type Channel struct {
+	ID              string `json:"id"`
+	Title           string `json:"title"`
+	Handle          string `json:"handle,omitempty"` // @name
+	Description     string `json:"description"`
+	SubscriberCount uint64 `json:"subscriber_count"`
+	VideoCount      uint64 `json:"video_count"`
+	ThumbnailURL    string `json:"thumbnail_url"`
+	URL             string `json:"url"`
+	// UploadsPlaylistID is the playlist holding every upload, when the
+	// backend exposes it
+	UploadsPlaylistID string `json:"uploads_playlist_id,omitempty"`
+}
+
+// Playlist represents a YouTube playlist
+type Playlist struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	ChannelTitle string `json:"channel_title"`
+	ChannelID    string `json:"channel_id"`
+	ItemCount    int64  `json:"item_count"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	URL          string `json:"url"`
+}
+
+// PlaylistItem is one entry of a playlist. Edits refer to the entry ID
+// since a video can appear more than once.
+type PlaylistItem struct {
+	ID           string       `json:"id"`
+	VideoID      string       `json:"video_id"`
+	Position     int          `json:"position"`
+	Title        string       `json:"title"`
+	Availability Availability `json:"availability,omitempty"`
 }
 
 // SearchResponse represents the complete search response
@@ -32,6 +102,18 @@ type SearchConfig struct {
 	SafeSearch    string `json:"safe_search"`    // none, moderate, strict
 	VideoDuration string `json:"video_duration"` // any, short, medium, long
 	VideoType     string `json:"video_type"`     // any, episode, movie
+	ChannelID     string `json:"channel_id,omitempty"`
+	PageToken     string `json:"page_token,omitempty"`
+
+	// Optional filters, see filters.go for the accepted values
+	PublishedAfter    time.Time `json:"published_after,omitzero"`
+	PublishedBefore   time.Time `json:"published_before,omitzero"`
+	RegionCode        string    `json:"region_code,omitempty"`        // ISO 3166-1 alpha-2
+	RelevanceLanguage string    `json:"relevance_language,omitempty"` // ISO 639-1
+	VideoCategoryID   string    `json:"video_category_id,omitempty"`
+	EventType         string    `json:"event_type,omitempty"`       // live, upcoming, completed
+	VideoCaption      string    `json:"video_caption,omitempty"`    // any, closedCaption, none
+	VideoDefinition   string    `json:"video_definition,omitempty"` // any, high, standard
 }
 
 // SearchResult is an alias for Video for backward compatibility
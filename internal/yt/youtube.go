@@ -3,7 +3,9 @@ package yt
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
@@ -16,13 +18,28 @@ const (
 // Client wraps the YouTube API service
 type Client struct {
 	service *youtube.Service
+	quota   *QuotaTracker
+	cache   *Cache
+	limiter *RateLimiter
+	retry   RetryPolicy
+	region  string
+	// sleep waits between retries; nil means sleep, tests replace it
+	sleep func(ctx context.Context, d time.Duration) error
+
+	// cacheScope keeps cached responses of different accounts apart
+	cacheScope string
 }
 
-// NewClient creates a new YouTube API client
+// NewClient creates a new YouTube API client using the key from the
+// GOOGLE_API_KEY environment variable
 func NewClient() (*Client, error) {
-	apiKey := os.Getenv(envAPIKey)
+	return NewClientWithKey(os.Getenv(envAPIKey))
+}
+
+// NewClientWithKey creates a new YouTube API client with an explicit API key
+func NewClientWithKey(apiKey string) (*Client, error) {
 	if apiKey == "" {
-		return nil, fmt.Errorf("missing YouTube API key: set %s environment variable", envAPIKey)
+		return nil, fmt.Errorf("missing YouTube API key: set %s or api_key in the config file", envAPIKey)
 	}
 
 	ctx := context.Background()
@@ -33,6 +50,21 @@ func NewClient() (*Client, error) {
 
 	return &Client{
 		service: service,
+		retry:   DefaultRetryPolicy,
+	}, nil
+}
+
+// NewAuthorizedClient creates a YouTube API client that calls the API as
+// the user httpClient is authorized for, e.g. by OAuth
+func NewAuthorizedClient(httpClient *http.Client) (*Client, error) {
+	service, err := youtube.NewService(context.Background(), option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create YouTube service: %w", err)
+	}
+
+	return &Client{
+		service: service,
+		retry:   DefaultRetryPolicy,
 	}, nil
 }
 
@@ -40,3 +72,43 @@ func NewClient() (*Client, error) {
 func (c *Client) Service() *youtube.Service {
 	return c.service
 }
+
+// SetQuotaTracker makes the client account every API call against quota
+func (c *Client) SetQuotaTracker(quota *QuotaTracker) {
+	c.quota = quota
+}
+
+// Quota returns the quota tracker, or nil when usage is not tracked
+func (c *Client) Quota() *QuotaTracker {
+	return c.quota
+}
+
+// Spend records an API call with the quota tracker before it is made. It
+// fails with ErrQuotaBudgetExceeded once the daily budget is used up.
+func (c *Client) Spend(call string) error {
+	if c.quota == nil {
+		return nil
+	}
+	return c.quota.Spend(call)
+}
+
+// SetRetryPolicy changes how transient API failures are retried
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// SetRegion sets the ISO 3166-1 alpha-2 code of the region the user is
+// in, which decides whether region restricted videos can be played
+func (c *Client) SetRegion(region string) {
+	c.region = region
+}
+
+// Region returns the user's region, or "" when unknown
+func (c *Client) Region() string {
+	return c.region
+}
+
+// SetRateLimiter makes the client wait for limiter before every API call
+func (c *Client) SetRateLimiter(limiter *RateLimiter) {
+	c.limiter = limiter
+}